@@ -34,6 +34,11 @@ func main() {
 	}
 	defer st.Close()
 
+	settings, err := st.Settings().Load()
+	if err != nil {
+		log.Fatalf("Failed to load settings: %v", err)
+	}
+
 	// Find web directory
 	webDir := findWebDir()
 	if webDir != "" {
@@ -45,10 +50,11 @@ func main() {
 	appCfg := app.Config{
 		Store:        st,
 		PluginDir:    pluginDir,
-		CameraID:     0, // Default camera
-		MotionThresh: 0.05,
+		CameraID:     settings.CameraID,
+		MotionThresh: settings.MotionThreshold,
 	}
 	application := app.New(appCfg)
+	application.ApplySettings(settings)
 
 	// Load gestures from database
 	if err := application.LoadGestures(); err != nil {
@@ -60,27 +66,38 @@ func main() {
 		log.Printf("Warning: Failed to discover plugins: %v", err)
 	}
 
-	// NOTE: Don't start app pipeline - let WebSocket handler read camera directly
-	// This avoids camera access conflicts between pipeline and recording UI
-	// application.SetEnabled(true)
-	// if err := application.Start(); err != nil {
-	// 	log.Fatalf("Failed to start detection pipeline: %v", err)
-	// }
-	// defer application.Stop()
+	// Start the detection pipeline. The camera is shared with the web UI
+	// through the app's frame hub, so both can run at the same time.
+	application.SetEnabled(true)
+	if err := application.Start(); err != nil {
+		log.Fatalf("Failed to start detection pipeline: %v", err)
+	}
+	defer application.Stop()
+
+	// Report what the pipeline decides to the web UI
+	events := server.NewEventsHandler()
+	application.SetEventHandler(func(e app.MatchEvent) {
+		events.Broadcast(e)
+	})
 
-	// Configure and start server with app's camera and detector
+	// Configure and start server with app's frame hub, detector and plugins
 	cfg := server.Config{
 		StaticDir: webDir,
 		Store:     st,
-		Camera:    application.Camera(),
+		FrameHub:  application.FrameHub(),
 		Detector:  application.Detector(),
+		Plugins:   application.PluginManager(),
+		Executor:  application.PluginExecutor(),
+		Events:    events,
+
+		ApplySettings: application.ApplySettings,
 	}
 
 	srv := server.New(cfg)
 
-	addr := ":8080"
+	addr := fmt.Sprintf(":%d", settings.Port)
 	fmt.Printf("Starting server on %s\n", addr)
-	fmt.Println("Open http://localhost:8080 in your browser")
+	fmt.Printf("Open http://localhost:%d in your browser\n", settings.Port)
 	fmt.Println("Press Ctrl+C to stop")
 
 	// Start server in a goroutine
@@ -2,6 +2,8 @@
 package app
 
 import (
+	"encoding/json"
+	"errors"
 	"log"
 	"sync"
 	"time"
@@ -13,14 +15,15 @@ import (
 	"github.com/ayusman/kuchipudi/internal/store"
 )
 
-// Pipeline timing constants.
+// Pipeline timing constants. The frame rates and idle timeout are defaults
+// that can be changed at runtime with ApplySettings.
 const (
 	// IdleFPS is the frame rate when no motion is detected.
-	IdleFPS = 5
+	IdleFPS = store.DefaultIdleFPS
 	// ActiveFPS is the frame rate during active detection.
-	ActiveFPS = 15
+	ActiveFPS = store.DefaultActiveFPS
 	// IdleTimeoutMs is the time in milliseconds to wait before switching back to idle mode.
-	IdleTimeoutMs = 2000
+	IdleTimeoutMs = store.DefaultIdleTimeoutMs
 	// PathBufferSize is the maximum number of frames to buffer for dynamic gesture detection.
 	PathBufferSize = 60
 )
@@ -37,13 +40,23 @@ type Config struct {
 type App struct {
 	config         Config
 	camera         capture.Camera
+	frames         *capture.FrameHub
+	frameSub       *capture.Subscription
 	motion         *capture.MotionDetector
 	detector       detector.Detector
 	staticMatcher  *gesture.StaticMatcher
 	dynamicMatcher *gesture.DynamicMatcher
+	twoHandMatcher *gesture.TwoHandMatcher
+	activator      *gesture.Activator
 	pluginMgr      *plugin.Manager
 	pluginExec     *plugin.Executor
+	idleFPS        int
+	activeFPS      int
+	idleTimeout    time.Duration
+	ambiguity      gesture.Ambiguity
+	onEvent        func(MatchEvent)
 	enabled        bool
+	watchOnce      sync.Once
 	mu             sync.RWMutex
 	stopCh         chan struct{}
 	lastMotionTime time.Time
@@ -56,14 +69,23 @@ func New(config Config) *App {
 		motionThreshold = 1.0 // Default threshold: 1% pixel change
 	}
 
+	camera := capture.NewCamera(config.CameraID)
+
 	a := &App{
 		config:         config,
-		camera:         capture.NewCamera(config.CameraID),
+		camera:         camera,
+		frames:         capture.NewFrameHub(camera),
 		motion:         capture.NewMotionDetector(motionThreshold),
 		staticMatcher:  gesture.NewStaticMatcher(),
 		dynamicMatcher: gesture.NewDynamicMatcher(),
+		twoHandMatcher: gesture.NewTwoHandMatcher(),
+		activator:      gesture.NewActivator(),
 		pluginMgr:      plugin.NewManager(config.PluginDir),
-		pluginExec:     plugin.NewExecutor(5000), // 5 second timeout for plugin execution
+		pluginExec:     plugin.NewExecutor(store.DefaultPluginTimeoutMs),
+		idleFPS:        IdleFPS,
+		activeFPS:      ActiveFPS,
+		idleTimeout:    IdleTimeoutMs * time.Millisecond,
+		ambiguity:      gesture.Ambiguity{MinScore: store.DefaultMinMatchScore, Margin: store.DefaultAmbiguityMargin},
 		enabled:        false,
 		stopCh:         nil,
 		lastMotionTime: time.Now(),
@@ -103,6 +125,8 @@ func (a *App) SetDetector(d detector.Detector) {
 }
 
 // LoadGestures loads gesture templates from the database into the matchers.
+// After loading, changes made through the store are applied to the matchers
+// automatically, so gestures edited in the web UI take effect without a restart.
 func (a *App) LoadGestures() error {
 	if a.config.Store == nil {
 		return nil
@@ -114,39 +138,191 @@ func (a *App) LoadGestures() error {
 	}
 
 	for _, g := range gestures {
-		template := &gesture.Template{
-			ID:        g.ID,
-			Name:      g.Name,
-			Tolerance: g.Tolerance,
-		}
+		a.applyGesture(g)
+	}
+
+	a.watchOnce.Do(func() {
+		a.config.Store.OnGestureChange(a.handleGestureChange)
+	})
+
+	log.Printf("Loaded %d gestures from database", len(gestures))
+	return nil
+}
 
-		switch g.Type {
-		case store.GestureTypeStatic:
-			template.Type = gesture.TypeStatic
-			landmarks, err := a.config.Store.Gestures().GetLandmarks(g.ID)
-			if err != nil {
-				log.Printf("Failed to load landmarks for %s: %v", g.Name, err)
-			} else if len(landmarks) > 0 {
-				template.Landmarks = storeLandmarksToDetector(landmarks)
-			}
-			a.staticMatcher.AddTemplate(template)
-
-		case store.GestureTypeDynamic:
-			template.Type = gesture.TypeDynamic
-			path, err := a.config.Store.Gestures().GetPath(g.ID)
-			if err != nil {
-				log.Printf("Failed to load path for %s: %v", g.Name, err)
-			} else if len(path) > 0 {
-				template.Path = storePathToGesture(path)
-			}
-			a.dynamicMatcher.AddTemplate(template)
+// ReloadGesture reads a gesture from the database and installs its current
+// template in the matchers, replacing any previous version with the same ID.
+func (a *App) ReloadGesture(id string) error {
+	if a.config.Store == nil {
+		return nil
+	}
+
+	g, err := a.config.Store.Gestures().GetByID(id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			a.RemoveGesture(id)
+			return nil
 		}
+		return err
 	}
 
-	log.Printf("Loaded %d gestures from database", len(gestures))
+	a.applyGesture(g)
 	return nil
 }
 
+// RemoveGesture removes a gesture's template from the matchers.
+func (a *App) RemoveGesture(id string) {
+	a.staticMatcher.RemoveTemplate(id)
+	a.dynamicMatcher.RemoveTemplate(id)
+	a.twoHandMatcher.RemoveTemplate(id)
+	a.activator.Forget(id)
+}
+
+// handleGestureChange is registered with the store to keep matchers in sync.
+func (a *App) handleGestureChange(gestureID string, deleted bool) {
+	if deleted {
+		a.RemoveGesture(gestureID)
+		return
+	}
+
+	if err := a.ReloadGesture(gestureID); err != nil {
+		log.Printf("Failed to reload gesture %s: %v", gestureID, err)
+	}
+}
+
+// applyGesture builds a template for g and installs it in the matcher for its type.
+// The template is removed from the other matchers in case the gesture changed type.
+func (a *App) applyGesture(g *store.Gesture) {
+	template := &gesture.Template{
+		ID:        g.ID,
+		Name:      g.Name,
+		Tolerance: g.Tolerance,
+		Activation: gesture.ActivationConfig{
+			MinHold:       time.Duration(g.HoldMs) * time.Millisecond,
+			MinFrames:     g.MinFrames,
+			Cooldown:      time.Duration(g.CooldownMs) * time.Millisecond,
+			FireOnRelease: g.FireOnRelease,
+		},
+	}
+
+	switch g.Type {
+	case store.GestureTypeStatic:
+		template.Type = gesture.TypeStatic
+		template.Hand = gesture.Hand(g.Handedness)
+		template.RecordedBy = g.TemplateHandedness
+		template.Static = gesture.StaticOptions{
+			RotationInvariant: g.RotationInvariant,
+			Mode:              gesture.MatchMode(g.MatchMode),
+			Neighbors:         g.Neighbors,
+		}
+		landmarks, err := a.config.Store.Gestures().GetLandmarks(g.ID)
+		if err != nil {
+			log.Printf("Failed to load landmarks for %s: %v", g.Name, err)
+		} else if len(landmarks) > 0 {
+			template.Landmarks = storeLandmarksToDetector(landmarks)
+		}
+		if g.Neighbors > 0 {
+			template.Exemplars = a.loadExemplars(g, template.Static)
+		}
+		a.dynamicMatcher.RemoveTemplate(g.ID)
+		a.twoHandMatcher.RemoveTemplate(g.ID)
+		a.staticMatcher.AddTemplate(template)
+
+	case store.GestureTypeDynamic:
+		template.Type = gesture.TypeDynamic
+		template.Dynamic = gesture.PathOptions{
+			Normalization: gesture.PathNormalization(g.PathNormalization),
+			Features:      gesture.PathFeatures(g.PathFeatures),
+		}
+		for _, t := range g.PathTrack {
+			template.Dynamic.Track = append(template.Dynamic.Track, gesture.Tracked(t))
+		}
+		path, err := a.config.Store.Gestures().GetPath(g.ID)
+		if err != nil {
+			log.Printf("Failed to load path for %s: %v", g.Name, err)
+		} else if len(path) > 0 {
+			template.Path = storePathToGesture(path)
+		}
+		a.staticMatcher.RemoveTemplate(g.ID)
+		a.twoHandMatcher.RemoveTemplate(g.ID)
+		a.dynamicMatcher.AddTemplate(template)
+
+	case store.GestureTypeTwoHand:
+		template.Type = gesture.TypeTwoHand
+		template.Hands = a.loadHandPair(g)
+		a.staticMatcher.RemoveTemplate(g.ID)
+		a.dynamicMatcher.RemoveTemplate(g.ID)
+		a.twoHandMatcher.AddTemplate(template)
+	}
+}
+
+// loadExemplars prepares the recorded samples of a static gesture for k-NN
+// matching, leaving out the samples training rejected as outliers.
+// Returns nil if the samples cannot be loaded or parsed.
+func (a *App) loadExemplars(g *store.Gesture, opts gesture.StaticOptions) [][]detector.Point3D {
+	samples, err := a.config.Store.Samples().GetByGestureID(g.ID)
+	if err != nil {
+		log.Printf("Failed to load samples for %s: %v", g.Name, err)
+		return nil
+	}
+	if len(samples) == 0 {
+		return nil
+	}
+
+	data := make([]json.RawMessage, len(samples))
+	for i, sample := range samples {
+		data[i] = sample.Data
+	}
+	all, err := gesture.NewTrainer().StaticExemplars(data, opts)
+	if err != nil {
+		log.Printf("Failed to prepare exemplars for %s: %v", g.Name, err)
+		return nil
+	}
+
+	exemplars := make([][]detector.Point3D, 0, len(all))
+	for i, exemplar := range all {
+		if !samples[i].Outlier {
+			exemplars = append(exemplars, exemplar)
+		}
+	}
+	return exemplars
+}
+
+// loadHandPair reads the two-hand template of g from the database.
+// Returns nil if the gesture has not been trained or the template is incomplete.
+func (a *App) loadHandPair(g *store.Gesture) *gesture.HandPair {
+	pair, err := a.config.Store.Gestures().GetHandPair(g.ID)
+	if err != nil {
+		log.Printf("Failed to load hand pair for %s: %v", g.Name, err)
+		return nil
+	}
+	if pair == nil {
+		return nil
+	}
+
+	landmarks, err := a.config.Store.Gestures().GetLandmarks(g.ID)
+	if err != nil {
+		log.Printf("Failed to load landmarks for %s: %v", g.Name, err)
+		return nil
+	}
+
+	hands := &gesture.HandPair{
+		Offset: detector.Point3D{X: pair.OffsetX, Y: pair.OffsetY, Z: pair.OffsetZ},
+		Scale:  pair.Scale,
+	}
+	for _, l := range landmarks {
+		p := detector.Point3D{X: l.X, Y: l.Y, Z: l.Z}
+		if l.Hand == store.HandRight {
+			hands.Right = append(hands.Right, p)
+		} else {
+			hands.Left = append(hands.Left, p)
+		}
+	}
+	if len(hands.Left) == 0 || len(hands.Right) == 0 {
+		return nil
+	}
+	return hands
+}
+
 // storeLandmarksToDetector converts store.Landmark slice to detector.Point3D slice.
 func storeLandmarksToDetector(landmarks []store.Landmark) []detector.Point3D {
 	points := make([]detector.Point3D, len(landmarks))
@@ -161,6 +337,9 @@ func storePathToGesture(path []store.PathPoint) []gesture.PathPoint {
 	points := make([]gesture.PathPoint, len(path))
 	for i, p := range path {
 		points[i] = gesture.PathPoint{X: p.X, Y: p.Y, Timestamp: p.TimestampMs}
+		if len(p.Hand) > 0 {
+			points[i].Hand = storeLandmarksToDetector(p.Hand)
+		}
 	}
 	return points
 }
@@ -180,17 +359,17 @@ func (a *App) Start() error {
 		return nil
 	}
 
-	// Open the camera
-	if err := a.camera.Open(); err != nil {
+	// Subscribe to camera frames at the idle rate; this opens the camera if needed
+	sub, err := a.frames.Subscribe(a.idleFPS)
+	if err != nil {
 		return err
 	}
-
-	// Set initial FPS to idle mode
-	a.camera.SetFPS(IdleFPS)
+	a.frameSub = sub
 
 	// Create stop channel and start the pipeline
 	a.stopCh = make(chan struct{})
-	go a.runPipeline()
+	go a.runPipeline(a.frameSub, a.stopCh)
+	go a.runHistoryPruning(a.stopCh)
 
 	log.Println("Detection pipeline started")
 	return nil
@@ -207,9 +386,11 @@ func (a *App) Stop() {
 		a.stopCh = nil
 	}
 
-	// Close the camera
-	if err := a.camera.Close(); err != nil {
-		log.Printf("Error closing camera: %v", err)
+	// Release our camera subscription; the hub closes the camera once
+	// no other subscribers (such as the web UI) remain
+	if a.frameSub != nil {
+		a.frameSub.Close()
+		a.frameSub = nil
 	}
 
 	// Close motion detector
@@ -222,6 +403,9 @@ func (a *App) Stop() {
 		}
 	}
 
+	// Shut down persistent plugin processes
+	a.pluginExec.Close()
+
 	log.Println("Detection pipeline stopped")
 }
 
@@ -230,6 +414,11 @@ func (a *App) Camera() capture.Camera {
 	return a.camera
 }
 
+// FrameHub returns the hub that shares camera frames between the pipeline and other consumers.
+func (a *App) FrameHub() *capture.FrameHub {
+	return a.frames
+}
+
 // MotionDetector returns the motion detector instance.
 func (a *App) MotionDetector() *capture.MotionDetector {
 	return a.motion
@@ -245,11 +434,21 @@ func (a *App) DynamicMatcher() *gesture.DynamicMatcher {
 	return a.dynamicMatcher
 }
 
+// TwoHandMatcher returns the two-hand gesture matcher.
+func (a *App) TwoHandMatcher() *gesture.TwoHandMatcher {
+	return a.twoHandMatcher
+}
+
 // PluginManager returns the plugin manager.
 func (a *App) PluginManager() *plugin.Manager {
 	return a.pluginMgr
 }
 
+// PluginExecutor returns the executor used to run plugin actions.
+func (a *App) PluginExecutor() *plugin.Executor {
+	return a.pluginExec
+}
+
 // Detector returns the hand detector.
 func (a *App) Detector() detector.Detector {
 	a.mu.RLock()
@@ -271,3 +271,54 @@ func TestApp_IdleActiveMode_Switching(t *testing.T) {
 	}
 
 }
+
+func TestApp_HotReloadGestures(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping integration test")
+	}
+
+	tmpDir := t.TempDir()
+	s, err := store.New(filepath.Join(tmpDir, "test.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	defer s.Close()
+
+	app := New(Config{Store: s, PluginDir: tmpDir})
+	if err := app.LoadGestures(); err != nil {
+		t.Fatalf("LoadGestures() error = %v", err)
+	}
+
+	hand := detector.ThumbsUpLandmarks()
+
+	// Creating and training a gesture makes it matchable without a reload
+	s.Gestures().Create(&store.Gesture{ID: "g1", Name: "Thumbs Up", Type: store.GestureTypeStatic, Tolerance: 0.3})
+	normalized := hand.Normalize()
+	landmarks := make([]store.Landmark, len(normalized.Points))
+	for i, p := range normalized.Points {
+		landmarks[i] = store.Landmark{Index: i, X: p.X, Y: p.Y, Z: p.Z}
+	}
+	if err := s.Gestures().SaveLandmarks("g1", landmarks); err != nil {
+		t.Fatalf("SaveLandmarks() error = %v", err)
+	}
+
+	matches := app.StaticMatcher().Match(&hand)
+	if len(matches) != 1 {
+		t.Fatalf("expected trained gesture to match, got %d matches", len(matches))
+	}
+
+	// Renaming replaces the template in place
+	g, _ := s.Gestures().GetByID("g1")
+	g.Name = "Approve"
+	s.Gestures().Update(g)
+	matches = app.StaticMatcher().Match(&hand)
+	if len(matches) != 1 || matches[0].Template.Name != "Approve" {
+		t.Errorf("expected renamed template, got %+v", matches)
+	}
+
+	// Deleting removes it
+	s.Gestures().Delete("g1")
+	if matches := app.StaticMatcher().Match(&hand); len(matches) != 0 {
+		t.Errorf("expected no matches after delete, got %d", len(matches))
+	}
+}
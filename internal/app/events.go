@@ -0,0 +1,104 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/gesture"
+)
+
+// MatchEvent reports what the pipeline decided about a gesture: that it fired,
+// or that it could not be told apart from another gesture and nothing fired.
+type MatchEvent struct {
+	Time      time.Time       `json:"time"`
+	Outcome   gesture.Outcome `json:"outcome"` // OutcomeMatch or OutcomeAmbiguous
+	GestureID string          `json:"gesture_id"`
+	Gesture   string          `json:"gesture"` // Gesture name
+	Score     float64         `json:"score"`   // Match score (0-1, higher is better)
+
+	// The gesture that matched almost as well, for ambiguous outcomes
+	RunnerUpID    string  `json:"runner_up_id,omitempty"`
+	RunnerUp      string  `json:"runner_up,omitempty"`
+	RunnerUpScore float64 `json:"runner_up_score,omitempty"`
+}
+
+// SetEventHandler sets a function called with every match event.
+// It is called on the pipeline goroutine, so it must not block.
+func (a *App) SetEventHandler(handler func(MatchEvent)) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.onEvent = handler
+}
+
+// emit passes e to the event handler, if one is set.
+func (a *App) emit(e MatchEvent) {
+	a.mu.RLock()
+	handler := a.onEvent
+	a.mu.RUnlock()
+
+	if handler != nil {
+		handler(e)
+	}
+}
+
+// emitMatch reports that the action of a gesture fired.
+func (a *App) emitMatch(now time.Time, t *gesture.Template, score float64) {
+	a.emit(MatchEvent{
+		Time:      now,
+		Outcome:   gesture.OutcomeMatch,
+		GestureID: t.ID,
+		Gesture:   t.Name,
+		Score:     score,
+	})
+}
+
+// reportAmbiguous logs an ambiguous decision and emits its event.
+func (a *App) reportAmbiguous(now time.Time, d gesture.Decision) {
+	best, runnerUp := d.Best, d.RunnerUp
+	log.Printf("Ambiguous %s gesture: %s (score: %.3f) vs %s (score: %.3f), ignored",
+		best.Template.Type, best.Template.Name, best.Score, runnerUp.Template.Name, runnerUp.Score)
+
+	a.emit(MatchEvent{
+		Time:          now,
+		Outcome:       gesture.OutcomeAmbiguous,
+		GestureID:     best.Template.ID,
+		Gesture:       best.Template.Name,
+		Score:         best.Score,
+		RunnerUpID:    runnerUp.Template.ID,
+		RunnerUp:      runnerUp.Template.Name,
+		RunnerUpScore: runnerUp.Score,
+	})
+}
+
+// ambiguities remembers the pairs of gestures found ambiguous on the previous
+// frame, so that a pose held between two gestures is reported once rather
+// than on every frame.
+type ambiguities struct {
+	previous map[[2]string]bool
+	current  map[[2]string]bool
+}
+
+// newAmbiguities creates an empty ambiguities.
+func newAmbiguities() *ambiguities {
+	return &ambiguities{
+		previous: make(map[[2]string]bool),
+		current:  make(map[[2]string]bool),
+	}
+}
+
+// observe records an ambiguous decision on the current frame and returns
+// whether it is new, that is, was not also ambiguous on the previous frame.
+func (s *ambiguities) observe(d gesture.Decision) bool {
+	pair := [2]string{d.Best.Template.ID, d.RunnerUp.Template.ID}
+	if pair[0] > pair[1] {
+		pair[0], pair[1] = pair[1], pair[0]
+	}
+	s.current[pair] = true
+	return !s.previous[pair]
+}
+
+// nextFrame starts a new frame.
+func (s *ambiguities) nextFrame() {
+	s.previous, s.current = s.current, s.previous
+	clear(s.current)
+}
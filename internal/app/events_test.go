@@ -0,0 +1,70 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/gesture"
+)
+
+func TestAmbiguities_ReportsHeldPoseOnce(t *testing.T) {
+	s := newAmbiguities()
+	palm := &gesture.Template{ID: "palm"}
+	wave := &gesture.Template{ID: "wave"}
+	ambiguous := gesture.Decision{
+		Outcome:  gesture.OutcomeAmbiguous,
+		Best:     &gesture.Match{Template: palm},
+		RunnerUp: &gesture.Match{Template: wave},
+	}
+	swapped := gesture.Decision{
+		Outcome:  gesture.OutcomeAmbiguous,
+		Best:     &gesture.Match{Template: wave},
+		RunnerUp: &gesture.Match{Template: palm},
+	}
+
+	s.nextFrame()
+	if !s.observe(ambiguous) {
+		t.Error("expected the first ambiguous frame to be reported")
+	}
+
+	// Held over the next frames, even as the two trade places
+	s.nextFrame()
+	if s.observe(ambiguous) {
+		t.Error("expected a held ambiguous pose not to be reported again")
+	}
+	s.nextFrame()
+	if s.observe(swapped) {
+		t.Error("expected the same pair in the other order not to be reported again")
+	}
+
+	// After a frame without it, it is reported again
+	s.nextFrame()
+	s.nextFrame()
+	if !s.observe(ambiguous) {
+		t.Error("expected an ambiguous pose to be reported again after it was released")
+	}
+}
+
+func TestApp_SetEventHandler(t *testing.T) {
+	a := &App{}
+	now := time.Now()
+	var events []MatchEvent
+	a.SetEventHandler(func(e MatchEvent) { events = append(events, e) })
+
+	a.emitMatch(now, &gesture.Template{ID: "palm", Name: "Palm"}, 0.9)
+	a.reportAmbiguous(now, gesture.Decision{
+		Outcome:  gesture.OutcomeAmbiguous,
+		Best:     &gesture.Match{Template: &gesture.Template{ID: "palm", Name: "Palm"}, Score: 0.9},
+		RunnerUp: &gesture.Match{Template: &gesture.Template{ID: "wave", Name: "Wave"}, Score: 0.89},
+	})
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if e := events[0]; e.Outcome != gesture.OutcomeMatch || e.Gesture != "Palm" || e.RunnerUp != "" {
+		t.Errorf("expected a match of Palm, got %+v", e)
+	}
+	if e := events[1]; e.Outcome != gesture.OutcomeAmbiguous || e.GestureID != "palm" || e.RunnerUpID != "wave" || e.RunnerUpScore != 0.89 {
+		t.Errorf("expected Palm to be ambiguous with Wave, got %+v", e)
+	}
+}
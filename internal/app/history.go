@@ -0,0 +1,61 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// History retention limits, applied when the pipeline starts and every HistoryPruneInterval.
+const (
+	// HistoryRetention is how long action runs are kept.
+	HistoryRetention = 30 * 24 * time.Hour
+	// HistoryMaxRuns is the maximum number of action runs kept.
+	HistoryMaxRuns = 10000
+	// HistoryPruneInterval is how often old action runs are pruned.
+	HistoryPruneInterval = time.Hour
+)
+
+// recordRun saves an action run to the history. Failures are only logged.
+func (a *App) recordRun(run *store.ActionRun) {
+	if a.config.Store == nil {
+		return
+	}
+
+	if err := a.config.Store.History().Create(run); err != nil {
+		log.Printf("Failed to record action run: %v", err)
+	}
+}
+
+// pruneHistory deletes action runs beyond the retention limits.
+func (a *App) pruneHistory() {
+	if a.config.Store == nil {
+		return
+	}
+
+	deleted, err := a.config.Store.History().Prune(time.Now().Add(-HistoryRetention), HistoryMaxRuns)
+	if err != nil {
+		log.Printf("Failed to prune action history: %v", err)
+		return
+	}
+	if deleted > 0 {
+		log.Printf("Pruned %d old action runs", deleted)
+	}
+}
+
+// runHistoryPruning prunes the history now and then periodically until stopCh is closed.
+func (a *App) runHistoryPruning(stopCh chan struct{}) {
+	ticker := time.NewTicker(HistoryPruneInterval)
+	defer ticker.Stop()
+
+	a.pruneHistory()
+	for {
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+			a.pruneHistory()
+		}
+	}
+}
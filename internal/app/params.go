@@ -0,0 +1,93 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+	"github.com/ayusman/kuchipudi/internal/plugin"
+)
+
+// actionContext describes the circumstances in which a gesture fired.
+// Action params can reference its values with the placeholders
+// {{gesture}}, {{score}}, {{handedness}}, {{x}} and {{y}}.
+type actionContext struct {
+	Gesture    string  // Gesture name
+	Score      float64 // Match score (0-1, higher is better)
+	Handedness string  // "Left" or "Right", empty if unknown
+	X          float64 // Palm X position in normalized image coordinates
+	Y          float64 // Palm Y position in normalized image coordinates
+}
+
+// newActionContext builds the context of a gesture matched on hand.
+// The palm position is taken from the middle finger MCP joint.
+func newActionContext(name string, score float64, hand *detector.HandLandmarks) actionContext {
+	ctx := actionContext{Gesture: name, Score: score}
+	if hand != nil {
+		palm := hand.Points[detector.MiddleMCP]
+		ctx.Handedness = hand.Handedness
+		ctx.X = palm.X
+		ctx.Y = palm.Y
+	}
+	return ctx
+}
+
+// replacer returns a replacer that substitutes the context's placeholders.
+func (c actionContext) replacer() *strings.Replacer {
+	format := func(v float64) string {
+		return strconv.FormatFloat(v, 'f', 3, 64)
+	}
+
+	return strings.NewReplacer(
+		"{{gesture}}", c.Gesture,
+		"{{score}}", format(c.Score),
+		"{{handedness}}", c.Handedness,
+		"{{x}}", format(c.X),
+		"{{y}}", format(c.Y),
+	)
+}
+
+// expandParams substitutes context placeholders in every string value of params,
+// including strings nested in arrays and objects. Object keys are left unchanged.
+// A string made of one of plugin.NumberPlaceholders alone becomes a number.
+func expandParams(params json.RawMessage, ctx actionContext) (json.RawMessage, error) {
+	if !bytes.Contains(params, []byte("{{")) {
+		return params, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.UseNumber() // Keep numbers exactly as written
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(expandValue(v, ctx.replacer()))
+}
+
+// expandValue applies r to every string in a decoded JSON value.
+func expandValue(v any, r *strings.Replacer) any {
+	switch v := v.(type) {
+	case string:
+		if slices.Contains(plugin.NumberPlaceholders, v) {
+			return json.Number(r.Replace(v))
+		}
+		return r.Replace(v)
+	case []any:
+		for i := range v {
+			v[i] = expandValue(v[i], r)
+		}
+		return v
+	case map[string]any:
+		for k := range v {
+			v[k] = expandValue(v[k], r)
+		}
+		return v
+	default:
+		return v
+	}
+}
@@ -0,0 +1,76 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+func TestExpandParams(t *testing.T) {
+	hand := detector.ThumbsUpLandmarks()
+	hand.Handedness = "Right"
+	hand.Points[detector.MiddleMCP] = detector.Point3D{X: 0.25, Y: 0.75}
+
+	ctx := newActionContext("thumbs-up", 0.9, &hand)
+
+	params := json.RawMessage(`{"text": "{{gesture}} ({{handedness}}) at {{x}},{{y}} score {{score}}", "list": ["{{gesture}}", 12345678901234567], "n": 1}`)
+	got, err := expandParams(params, ctx)
+	if err != nil {
+		t.Fatalf("expandParams() error = %v", err)
+	}
+
+	var v struct {
+		Text string            `json:"text"`
+		List []json.RawMessage `json:"list"`
+		N    int               `json:"n"`
+	}
+	if err := json.Unmarshal(got, &v); err != nil {
+		t.Fatalf("failed to decode expanded params: %v", err)
+	}
+
+	if want := "thumbs-up (Right) at 0.250,0.750 score 0.900"; v.Text != want {
+		t.Errorf("expected text %q, got %q", want, v.Text)
+	}
+	if len(v.List) != 2 || string(v.List[0]) != `"thumbs-up"` || string(v.List[1]) != "12345678901234567" {
+		t.Errorf("unexpected list: %s", got)
+	}
+	if v.N != 1 {
+		t.Errorf("expected n to be unchanged, got %d", v.N)
+	}
+}
+
+func TestExpandParams_NoPlaceholders(t *testing.T) {
+	params := json.RawMessage(`{"key": "c"}`)
+
+	got, err := expandParams(params, actionContext{Gesture: "palm"})
+	if err != nil {
+		t.Fatalf("expandParams() error = %v", err)
+	}
+	if string(got) != string(params) {
+		t.Errorf("expected params unchanged, got %s", got)
+	}
+}
+
+func TestExpandParams_Numbers(t *testing.T) {
+	ctx := actionContext{Gesture: "palm", Score: 0.9, X: 0.25}
+
+	got, err := expandParams(json.RawMessage(`{"amount": "{{score}}", "at": ["{{x}}"], "label": "{{score}}%", "name": "{{gesture}}"}`), ctx)
+	if err != nil {
+		t.Fatalf("expandParams() error = %v", err)
+	}
+
+	var v map[string]any
+	if err := json.Unmarshal(got, &v); err != nil {
+		t.Fatalf("failed to decode expanded params: %v", err)
+	}
+	if v["amount"] != 0.9 {
+		t.Errorf("expected a lone number placeholder to become a number, got %#v", v["amount"])
+	}
+	if at, ok := v["at"].([]any); !ok || len(at) != 1 || at[0] != 0.25 {
+		t.Errorf("expected a nested number placeholder to become a number, got %#v", v["at"])
+	}
+	if v["label"] != "0.900%" || v["name"] != "palm" {
+		t.Errorf("expected other placeholders to stay in strings, got %s", got)
+	}
+}
@@ -1,27 +1,39 @@
 package app
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
+	"slices"
 	"time"
 
+	"github.com/ayusman/kuchipudi/internal/capture"
+	"github.com/ayusman/kuchipudi/internal/detector"
 	"github.com/ayusman/kuchipudi/internal/gesture"
 	"github.com/ayusman/kuchipudi/internal/plugin"
+	"github.com/ayusman/kuchipudi/internal/store"
 )
 
-// runPipeline is the main detection loop that processes frames from the camera.
+// runPipeline is the main detection loop that processes frames from the shared frame hub.
 // It manages the state transitions between idle and active modes based on motion detection.
 //
 // Pipeline logic:
-// 1. Start in idle mode (idleFPS=5)
-// 2. On motion detected, switch to active mode (activeFPS=15)
+// 1. Start in idle mode (IdleFPS by default), detecting motion on every frame
+// 2. On motion detected, switch to active mode (ActiveFPS by default), and back to idle after the idle timeout
 // 3. Run hand detection
-// 4. Match against static/dynamic gestures
-// 5. Buffer path for dynamic gestures (last 60 frames)
-// 6. After 2s no motion, switch back to idle mode
-// 7. Clear path buffer on dynamic match to prevent repeated triggers
-func (a *App) runPipeline() {
-	// Path buffer for dynamic gesture detection
-	pathBuffer := make([]gesture.PathPoint, 0, PathBufferSize)
+// 4. Follow each hand across frames, releasing the gestures of hands that left
+// 5. Match each hand against static gestures, ignoring weak matches and ambiguous ones too close to the runner-up
+// 6. Buffer the path of each hand (last 60 frames), spotting dynamic gestures anywhere in it and dropping it up to the end of a match
+// 7. Match two-hand gestures when both hands are visible, in place of the poses of each hand
+// 8. Debounce static matches through the activator (hold, min frames, cooldown, release), and repeat the actions of held gestures
+//
+// Hands are tracked across frames, so that each keeps its own path however
+// many are visible, and a hand entering or leaving the frame starts or ends
+// its track without disturbing the others. When a track ends, the gestures its
+// hand held are released and its repeats and match contexts forgotten.
+func (a *App) runPipeline(sub *capture.Subscription, stopCh chan struct{}) {
+	// What is remembered of each hand, and of the gestures it holds, between frames
+	st := newPipelineState()
 
 	// Track whether we're in active mode
 	activeMode := false
@@ -29,161 +41,353 @@ func (a *App) runPipeline() {
 	// Track the last motion detection time
 	lastMotionTime := time.Now()
 
-	// Frame interval based on current FPS
-	frameInterval := time.Second / time.Duration(IdleFPS)
-
-	ticker := time.NewTicker(frameInterval)
-	defer ticker.Stop()
+	// Frame rate requested from the subscription, which starts at the idle rate
+	currentFPS, _, _ := a.frameRates()
 
 	for {
 		select {
-		case <-a.stopCh:
+		case <-stopCh:
 			return
-		case <-ticker.C:
+		case shared, ok := <-sub.Frames():
+			if !ok {
+				return
+			}
+
 			// Skip processing if detection is disabled
 			if !a.IsEnabled() {
+				shared.Release()
 				continue
 			}
 
-			// Read a frame from the camera
-			frame, err := a.camera.ReadFrame()
-			if err != nil {
-				log.Printf("Error reading frame: %v", err)
-				continue
-			}
+			frame := shared.Mat
 
 			// Step 1: Motion detection
 			motionDetected, _ := a.motion.Detect(frame)
 
+			idleFPS, activeFPS, idleTimeout := a.frameRates()
+
+			// Step 2: Switch between idle and active mode
 			if motionDetected {
 				lastMotionTime = time.Now()
 
 				// Switch to active mode if not already
 				if !activeMode {
 					activeMode = true
-					a.camera.SetFPS(ActiveFPS)
-					frameInterval = time.Second / time.Duration(ActiveFPS)
-					ticker.Reset(frameInterval)
 					log.Println("Switched to active mode")
 				}
 			} else if activeMode {
 				// Check if we should switch back to idle mode
-				if time.Since(lastMotionTime) > time.Duration(IdleTimeoutMs)*time.Millisecond {
+				if time.Since(lastMotionTime) > idleTimeout {
 					activeMode = false
-					a.camera.SetFPS(IdleFPS)
-					frameInterval = time.Second / time.Duration(IdleFPS)
-					ticker.Reset(frameInterval)
-					pathBuffer = pathBuffer[:0] // Clear path buffer
+					a.resetPipeline(st) // Forget the hands, their paths and held gestures
 					log.Println("Switched to idle mode")
 				}
 			}
 
+			// Follow mode switches and frame rate settings changes
+			wantFPS := idleFPS
+			if activeMode {
+				wantFPS = activeFPS
+			}
+			if wantFPS != currentFPS {
+				sub.SetFPS(wantFPS)
+				currentFPS = wantFPS
+			}
+
 			// Skip further processing if not in active mode or no detector
 			if !activeMode || a.detector == nil {
-				frame.Close()
+				shared.Release()
 				continue
 			}
 
-			// Step 2: Hand detection
+			// Step 3: Hand detection
 			hands, err := a.detector.Detect(frame)
-			frame.Close() // Done with the frame
+			shared.Release() // Done with the frame
 
 			if err != nil {
 				log.Printf("Error detecting hands: %v", err)
 				continue
 			}
 
-			if len(hands) == 0 {
-				continue
+			a.processHands(time.Now(), hands, st)
+		}
+	}
+}
+
+// handGesture identifies a gesture made by one hand, by the ID of its track,
+// or by gesture.BothHands.
+type handGesture struct {
+	hand      int
+	gestureID string
+}
+
+// pipelineState is what the pipeline remembers from one frame to the next.
+// Everything kept about a hand is forgotten when its track ends.
+type pipelineState struct {
+	// Hands followed across frames, each with its path buffer for dynamic gesture detection
+	tracker *gesture.HandTracker
+
+	// Actions that repeat while their gesture is held
+	repeats *repeater
+
+	// Latest match context of each static gesture on each hand, used when its action fires
+	contexts map[handGesture]actionContext
+
+	// Ambiguous matches already reported, to report poses held between two gestures once
+	ambiguous *ambiguities
+}
+
+// newPipelineState creates the state of a pipeline that has seen no hands.
+func newPipelineState() *pipelineState {
+	return &pipelineState{
+		tracker:   gesture.NewHandTracker(),
+		repeats:   newRepeater(),
+		contexts:  make(map[handGesture]actionContext),
+		ambiguous: newAmbiguities(),
+	}
+}
+
+// forgetHand drops the repeats and match contexts of a hand whose track ended.
+func (st *pipelineState) forgetHand(hand int) {
+	st.repeats.forgetHand(hand)
+	for key := range st.contexts {
+		if key.hand == hand {
+			delete(st.contexts, key)
+		}
+	}
+}
+
+// resetPipeline forgets every hand and ends every held gesture without firing it.
+func (a *App) resetPipeline(st *pipelineState) {
+	a.activator.Reset()
+	for _, track := range st.tracker.Reset() {
+		a.activator.ForgetHand(time.Now(), track.ID)
+		st.forgetHand(track.ID)
+	}
+	st.repeats.reset()
+}
+
+// processHands matches the hands detected on a frame at now against the
+// gestures and runs the actions of those that fire (steps 4 to 8).
+func (a *App) processHands(now time.Time, hands []detector.HandLandmarks, st *pipelineState) {
+	ambiguity := a.matchAmbiguity()
+	st.ambiguous.nextFrame()
+
+	// Step 4: Follow the hands; those that left release the gestures they held
+	tracks, ended := st.tracker.Update(now, hands)
+	for _, track := range ended {
+		a.fireSightings(now, a.activator.ForgetHand(now, track.ID), st)
+		st.forgetHand(track.ID)
+	}
+
+	// Best static match of each hand, fed to the activator once per frame
+	var staticMatched []gesture.Sighting
+
+	// Process each detected hand
+	for i := range hands {
+		hand, track := &hands[i], tracks[i]
+
+		// Go by the handedness the hand was labelled with over its latest
+		// frames, so a single mislabelled frame does not change its gestures
+		hand.Handedness = track.Handedness
+
+		// Step 5: Static gesture matching
+		switch d := ambiguity.Decide(a.staticMatcher.Match(hand)); d.Outcome {
+		case gesture.OutcomeMatch:
+			staticMatched = append(staticMatched, gesture.Sighting{Hand: track.ID, Template: d.Best.Template})
+			st.contexts[handGesture{track.ID, d.Best.Template.ID}] = newActionContext(d.Best.Template.Name, d.Best.Score, hand)
+		case gesture.OutcomeAmbiguous:
+			if st.ambiguous.observe(d) {
+				a.reportAmbiguous(now, d)
 			}
+		}
 
-			// Process each detected hand
-			for i := range hands {
-				hand := &hands[i]
+		// Step 6: Buffer path for dynamic gesture detection
+		// Use the index finger tip position for tracking, keeping the whole
+		// hand for gestures that follow other landmarks, depth or pose
+		indexTip := hand.Points[8] // IndexTip = 8
+		pathPoint := gesture.PathPoint{
+			X:         indexTip.X,
+			Y:         indexTip.Y,
+			Timestamp: now.UnixMilli(),
+			Hand:      slices.Clone(hand.Points[:]),
+		}
 
-				// Step 3: Static gesture matching
-				staticMatches := a.staticMatcher.Match(hand)
-				if len(staticMatches) > 0 {
-					best := staticMatches[0]
-					log.Printf("Static gesture matched: %s (score: %.3f)", best.Template.Name, best.Score)
-					a.executeAction(best.Template.ID, best.Template.Name)
-				}
+		// Add to the path buffer of this hand
+		track.AddPoint(pathPoint, PathBufferSize)
 
-				// Step 4: Buffer path for dynamic gesture detection
-				// Use the index finger tip position for tracking
-				indexTip := hand.Points[8] // IndexTip = 8
-				pathPoint := gesture.PathPoint{
-					X:         indexTip.X,
-					Y:         indexTip.Y,
-					Timestamp: time.Now().UnixMilli(),
+		// Dynamic gesture matching (need at least some points)
+		if len(track.Path) >= 10 {
+			d := ambiguity.Decide(a.dynamicMatcher.MatchClosest(track.Path, ambiguity))
+			switch d.Outcome {
+			case gesture.OutcomeMatch:
+				best := d.Best
+				log.Printf("Dynamic gesture matched: %s (score: %.3f)", best.Template.Name, best.Score)
+				if a.activator.Trigger(now, track.ID, best.Template) {
+					a.emitMatch(now, best.Template, best.Score)
+					a.executeAction(best.Template.ID, newActionContext(best.Template.Name, best.Score, hand))
 				}
+			case gesture.OutcomeAmbiguous:
+				a.reportAmbiguous(now, d)
+			}
 
-				// Add to path buffer
-				if len(pathBuffer) >= PathBufferSize {
-					// Shift buffer left by 1, removing oldest point
-					copy(pathBuffer, pathBuffer[1:])
-					pathBuffer = pathBuffer[:PathBufferSize-1]
-				}
-				pathBuffer = append(pathBuffer, pathPoint)
-
-				// Step 5: Dynamic gesture matching (need at least some points)
-				if len(pathBuffer) >= 10 {
-					dynamicMatches := a.dynamicMatcher.Match(pathBuffer)
-					if len(dynamicMatches) > 0 {
-						best := dynamicMatches[0]
-						log.Printf("Dynamic gesture matched: %s (score: %.3f)", best.Template.Name, best.Score)
-						a.executeAction(best.Template.ID, best.Template.Name)
-
-						// Clear path buffer to prevent repeated triggers
-						pathBuffer = pathBuffer[:0]
-					}
-				}
+			// Drop the matched motion to prevent repeated triggers, or repeated
+			// reports of a motion that could not be told apart, keeping what
+			// followed it as the possible start of the next gesture
+			if d.Outcome != gesture.OutcomeNone {
+				track.DropPath(d.Best.End)
 			}
 		}
 	}
+
+	// Step 7: A pose made with both hands takes precedence over the poses of each hand
+	if len(hands) >= 2 {
+		left, right := gesture.PairHands(hands)
+		switch d := ambiguity.Decide(a.twoHandMatcher.Match(left, right)); d.Outcome {
+		case gesture.OutcomeMatch:
+			staticMatched = []gesture.Sighting{{Hand: gesture.BothHands, Template: d.Best.Template}}
+			st.contexts[handGesture{gesture.BothHands, d.Best.Template.ID}] = newActionContext(d.Best.Template.Name, d.Best.Score, left)
+		case gesture.OutcomeAmbiguous:
+			// Neither pose of the two hands can be trusted either
+			staticMatched = nil
+			if st.ambiguous.observe(d) {
+				a.reportAmbiguous(now, d)
+			}
+		}
+	}
+
+	// Step 8: Fire static gestures whose activation requirements are met.
+	// The activator sees frames without hands too, so held gestures are released
+	a.fireActivated(now, a.activator.Observe(now, staticMatched), st)
+}
+
+// fireActivated executes the actions of static gestures the activator decided to fire,
+// then repeats the actions of gestures that are still held and due for a repeat.
+func (a *App) fireActivated(now time.Time, fired []gesture.Sighting, st *pipelineState) {
+	a.fireSightings(now, fired, st)
+
+	// Held gestures were matched on a recent frame, so their context is known
+	for _, key := range st.repeats.due(now, a.activator.Held()) {
+		action := a.boundAction(key.gestureID)
+		if action != nil && !action.Enabled {
+			action = nil
+		}
+		if st.repeats.repeat(now, key, action) {
+			a.runAction(action, st.contexts[key])
+		}
+	}
+}
+
+// fireSightings executes the actions of static gestures that fired, each with
+// the latest match context recorded for its gesture on the hand it fired for.
+func (a *App) fireSightings(now time.Time, fired []gesture.Sighting, st *pipelineState) {
+	for _, s := range fired {
+		t := s.Template
+		log.Printf("Static gesture activated: %s", t.Name)
+		key := handGesture{s.Hand, t.ID}
+		ctx, ok := st.contexts[key]
+		if !ok {
+			ctx = actionContext{Gesture: t.Name}
+		}
+		a.emitMatch(now, t, ctx.Score)
+		action := a.executeAction(t.ID, ctx)
+		st.repeats.start(now, key, action)
+	}
 }
 
 // executeAction executes the action associated with a recognized gesture.
 // It looks up the action binding in the database and executes the corresponding plugin.
-func (a *App) executeAction(gestureID, gestureName string) {
+// Returns the action that was executed, or nil if none is bound or enabled.
+func (a *App) executeAction(gestureID string, ctx actionContext) *store.Action {
+	action := a.boundAction(gestureID)
+	if action == nil {
+		return nil // No action bound - silent skip
+	}
+	if !action.Enabled {
+		a.recordRun(newActionRun(action, ctx, "action disabled"))
+		return nil
+	}
+
+	a.runAction(action, ctx)
+	return action
+}
+
+// boundAction looks up the action bound to a gesture in the database.
+// Returns nil if none is bound, or no store is configured.
+func (a *App) boundAction(gestureID string) *store.Action {
 	// Skip if no store configured
 	if a.config.Store == nil {
-		return
+		return nil
 	}
 
-	// Look up action binding
 	action, err := a.config.Store.Actions().GetByGestureID(gestureID)
 	if err != nil {
 		log.Printf("Error looking up action: %v", err)
-		return
+		return nil
 	}
-	if action == nil || !action.Enabled {
-		return // No action bound or disabled - silent skip
+	return action
+}
+
+// newActionRun starts a history record for running action in ctx.
+func newActionRun(action *store.Action, ctx actionContext, errMsg string) *store.ActionRun {
+	return &store.ActionRun{
+		GestureID:   action.GestureID,
+		GestureName: ctx.Gesture,
+		ActionID:    action.ID,
+		PluginName:  action.PluginName,
+		ActionName:  action.ActionName,
+		Error:       errMsg,
 	}
+}
 
+// runAction executes an action's plugin asynchronously and records the outcome in the history.
+// Placeholders in the action's params are filled in from ctx.
+func (a *App) runAction(action *store.Action, ctx actionContext) {
 	// Get plugin
 	plug, err := a.pluginMgr.Get(action.PluginName)
 	if err != nil {
 		log.Printf("Plugin not found: %s", action.PluginName)
+		a.recordRun(newActionRun(action, ctx, "plugin not found"))
+		return
+	}
+
+	params, err := expandParams(action.Params, ctx)
+	if err != nil {
+		log.Printf("Invalid params for action %s: %v", action.ID, err)
+		a.recordRun(newActionRun(action, ctx, fmt.Sprintf("invalid params: %v", err)))
 		return
 	}
 
 	// Build request
 	req := &plugin.Request{
 		Action:  action.ActionName,
-		Gesture: gestureName,
+		Gesture: ctx.Gesture,
 		Config:  action.Config,
+		Params:  params,
 	}
 
+	run := newActionRun(action, ctx, "")
+	run.Request, _ = json.Marshal(req)
+
 	// Execute async to not block pipeline
 	go func() {
+		start := time.Now()
 		resp, err := a.pluginExec.Execute(plug, req)
-		if err != nil {
+		run.LatencyMs = time.Since(start).Milliseconds()
+
+		switch {
+		case err != nil:
 			log.Printf("Plugin execution failed: %v", err)
-			return
-		}
-		if !resp.Success {
+			run.Error = err.Error()
+		case !resp.Success:
 			log.Printf("Plugin returned error: %s", resp.Error)
+			run.Response, _ = json.Marshal(resp)
+			run.Error = resp.Error
+		default:
+			run.Response, _ = json.Marshal(resp)
+			run.Success = true
 		}
+
+		a.recordRun(run)
 	}()
 }
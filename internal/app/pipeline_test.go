@@ -0,0 +1,163 @@
+package app
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/plugin"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// frameInterval is the time between frames at the pipeline's active frame rate.
+const frameInterval = 66 * time.Millisecond
+
+// newPipelineApp returns an app matching an open palm held for three frames,
+// recording the events it emits.
+func newPipelineApp(t *testing.T) (*App, *[]MatchEvent) {
+	t.Helper()
+
+	a := &App{
+		staticMatcher:  gesture.NewStaticMatcher(),
+		dynamicMatcher: gesture.NewDynamicMatcher(),
+		twoHandMatcher: gesture.NewTwoHandMatcher(),
+		activator:      gesture.NewActivator(),
+	}
+	palm := detector.OpenPalmLandmarks()
+	a.staticMatcher.AddTemplate(&gesture.Template{
+		ID:         "palm",
+		Name:       "Palm",
+		Type:       gesture.TypeStatic,
+		Landmarks:  palm.Normalize().Points[:],
+		Tolerance:  0.3,
+		Activation: gesture.ActivationConfig{MinFrames: 3},
+	})
+
+	var events []MatchEvent
+	a.SetEventHandler(func(e MatchEvent) { events = append(events, e) })
+	return a, &events
+}
+
+// palmAt returns an open palm labelled handedness, moved by dx across the image.
+func palmAt(dx float64, handedness string) detector.HandLandmarks {
+	h := detector.OpenPalmLandmarks()
+	h.Handedness = handedness
+	for i := range h.Points {
+		h.Points[i].X += dx
+	}
+	return h
+}
+
+func TestPipeline_TwoHandsSamePose(t *testing.T) {
+	a, events := newPipelineApp(t)
+	st := newPipelineState()
+	now := time.Now()
+
+	frame := func(hands ...detector.HandLandmarks) {
+		a.processHands(now, hands, st)
+		now = now.Add(frameInterval)
+	}
+
+	// Each hand holds the palm for three frames of its own
+	for range 3 {
+		frame(palmAt(-0.3, gesture.HandednessRight), palmAt(0.3, gesture.HandednessLeft))
+	}
+	if len(*events) != 2 {
+		t.Fatalf("expected the palm to fire once for each hand, got %d events", len(*events))
+	}
+	if held := a.activator.Held(); len(held) != 2 || held[0].Hand == held[1].Hand {
+		t.Fatalf("expected the palm held by both hands, got %v", held)
+	}
+	hands := make(map[string]bool)
+	for key, ctx := range st.contexts {
+		if key.gestureID == "palm" {
+			hands[ctx.Handedness] = true
+		}
+	}
+	if !hands[gesture.HandednessLeft] || !hands[gesture.HandednessRight] {
+		t.Errorf("expected a match context for each hand, got %v", st.contexts)
+	}
+
+	// Both hands leave: once their tracks end, nothing is left of them
+	frame()
+	now = now.Add(gesture.TrackTimeout)
+	frame()
+	if held := a.activator.Held(); len(held) != 0 {
+		t.Errorf("expected no gestures held by hands that left, got %v", held)
+	}
+	if len(st.contexts) != 0 || len(st.repeats.states) != 0 || len(st.tracker.Tracks()) != 0 {
+		t.Errorf("expected the state of the hands forgotten, got %d contexts, %d repeats and %d tracks",
+			len(st.contexts), len(st.repeats.states), len(st.tracker.Tracks()))
+	}
+}
+
+func TestPipeline_HandsDoNotShareHolds(t *testing.T) {
+	a, events := newPipelineApp(t)
+	st := newPipelineState()
+	now := time.Now()
+
+	// One hand holds the palm for two frames, then another, elsewhere, for one:
+	// three frames of the pose, but never three on the same hand
+	for _, dx := range []float64{-0.3, -0.3, 0.3} {
+		a.processHands(now, []detector.HandLandmarks{palmAt(dx, gesture.HandednessRight)}, st)
+		now = now.Add(frameInterval)
+	}
+	if len(*events) != 0 {
+		t.Errorf("expected no firing, got %+v", *events)
+	}
+}
+
+func TestPipeline_RepeatsFollowTheBinding(t *testing.T) {
+	s, err := store.New(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("store.New() error = %v", err)
+	}
+	defer s.Close()
+	if err := s.Gestures().Create(&store.Gesture{ID: "palm", Name: "Palm", Type: store.GestureTypeStatic, Tolerance: 0.3}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	action := &store.Action{ID: "a1", GestureID: "palm", PluginName: "missing", ActionName: "run", Enabled: true,
+		RepeatMode: store.RepeatInterval, RepeatIntervalMs: 200}
+	if err := s.Actions().Create(action); err != nil {
+		t.Fatalf("failed to create action: %v", err)
+	}
+
+	// Every run of the action, none of which finds its plugin, is recorded
+	a, _ := newPipelineApp(t)
+	a.config.Store = s
+	a.pluginMgr = plugin.NewManager(t.TempDir())
+	runs := func() int {
+		t.Helper()
+		_, total, err := s.History().List(store.ActionRunFilter{})
+		if err != nil {
+			t.Fatalf("failed to list runs: %v", err)
+		}
+		return total
+	}
+
+	st := newPipelineState()
+	now := time.Now()
+	hold := func(d time.Duration) {
+		for end := now.Add(d); now.Before(end); now = now.Add(frameInterval) {
+			a.processHands(now, []detector.HandLandmarks{palmAt(0, gesture.HandednessRight)}, st)
+		}
+	}
+
+	hold(time.Second)
+	if got := runs(); got < 4 {
+		t.Fatalf("expected the held palm to fire and repeat, got %d runs", got)
+	}
+
+	// Disabling the action while the palm is held stops the repeats
+	action.Enabled = false
+	if err := s.Actions().Update(action); err != nil {
+		t.Fatalf("failed to update action: %v", err)
+	}
+	before := runs()
+	hold(time.Second)
+	if got := runs(); got != before {
+		t.Errorf("expected no repeats of a disabled action, got %d more runs", got-before)
+	}
+}
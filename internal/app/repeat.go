@@ -0,0 +1,122 @@
+package app
+
+import (
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// repeatAcceleration is the factor applied to the interval after each accelerating repeat.
+const repeatAcceleration = 0.75
+
+// repeatState tracks the repeats of one held gesture.
+type repeatState struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// repeater fires the actions of held gestures again according to their repeat mode,
+// like a key that auto-repeats while held down. Each hand repeats the gestures it
+// holds on its own. It keeps no copy of the actions: the action bound to a gesture
+// is looked up again for every repeat, so that changing, disabling or deleting it
+// takes effect while the gesture is held. It is used only by the pipeline goroutine.
+type repeater struct {
+	states map[handGesture]*repeatState
+}
+
+// newRepeater creates a new repeater with no held gestures.
+func newRepeater() *repeater {
+	return &repeater{
+		states: make(map[handGesture]*repeatState),
+	}
+}
+
+// repeats reports whether action fires again while its gesture is held.
+func repeats(action *store.Action) bool {
+	if action == nil || action.RepeatIntervalMs <= 0 {
+		return false
+	}
+	return action.RepeatMode == store.RepeatInterval || action.RepeatMode == store.RepeatAccelerate
+}
+
+// start begins repeating the action of a gesture that has just fired on a hand.
+// Actions that fire once are ignored.
+func (r *repeater) start(now time.Time, key handGesture, action *store.Action) {
+	if !repeats(action) {
+		return
+	}
+
+	interval := time.Duration(action.RepeatIntervalMs) * time.Millisecond
+	r.states[key] = &repeatState{
+		interval: interval,
+		next:     now.Add(interval),
+	}
+}
+
+// due returns the gestures whose repeat is due now. Gestures that are no longer
+// in held, on the same hand, stop repeating. Each due gesture must be passed to
+// repeat with its current action.
+func (r *repeater) due(now time.Time, held []gesture.Sighting) []handGesture {
+	if len(r.states) == 0 {
+		return nil
+	}
+
+	stillHeld := make(map[handGesture]bool, len(held))
+	for _, s := range held {
+		stillHeld[handGesture{s.Hand, s.Template.ID}] = true
+	}
+
+	var due []handGesture
+	for key, st := range r.states {
+		if !stillHeld[key] {
+			delete(r.states, key)
+			continue
+		}
+		if !now.Before(st.next) {
+			due = append(due, key)
+		}
+	}
+
+	return due
+}
+
+// repeat schedules the next repeat of a due gesture whose action is now action,
+// nil if it is no longer bound or enabled. Returns whether the action should
+// fire now; if not, the gesture stops repeating until it fires again.
+func (r *repeater) repeat(now time.Time, key handGesture, action *store.Action) bool {
+	st, ok := r.states[key]
+	if !ok {
+		return false
+	}
+	if !repeats(action) {
+		delete(r.states, key)
+		return false
+	}
+
+	if action.RepeatMode == store.RepeatAccelerate {
+		minInterval := time.Duration(action.RepeatMinIntervalMs) * time.Millisecond
+		st.interval = time.Duration(float64(st.interval) * repeatAcceleration)
+		if st.interval < minInterval {
+			st.interval = minInterval
+		}
+	} else {
+		st.interval = time.Duration(action.RepeatIntervalMs) * time.Millisecond
+	}
+	st.next = now.Add(st.interval)
+	return true
+}
+
+// forgetHand stops the repeats of a hand that is gone.
+func (r *repeater) forgetHand(hand int) {
+	for key := range r.states {
+		if key.hand == hand {
+			delete(r.states, key)
+		}
+	}
+}
+
+// reset stops all repeats.
+func (r *repeater) reset() {
+	r.states = make(map[handGesture]*repeatState)
+}
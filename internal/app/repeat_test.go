@@ -0,0 +1,159 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// palm is the palm gesture held by the hand of track 1.
+var palm = handGesture{hand: 1, gestureID: "palm"}
+
+// repeatNow fires the repeats due now, with action as the current action of
+// every gesture, and returns the gestures that repeated.
+func repeatNow(r *repeater, now time.Time, held []gesture.Sighting, action *store.Action) []handGesture {
+	var repeated []handGesture
+	for _, key := range r.due(now, held) {
+		if r.repeat(now, key, action) {
+			repeated = append(repeated, key)
+		}
+	}
+	return repeated
+}
+
+func TestRepeater_Once(t *testing.T) {
+	r := newRepeater()
+	held := []gesture.Sighting{{Hand: 1, Template: &gesture.Template{ID: "palm"}}}
+	start := time.Now()
+
+	action := &store.Action{RepeatMode: store.RepeatOnce, RepeatIntervalMs: 100}
+	r.start(start, palm, action)
+
+	if due := repeatNow(r, start.Add(time.Second), held, action); len(due) != 0 {
+		t.Errorf("expected no repeats for a once action, got %d", len(due))
+	}
+}
+
+func TestRepeater_Interval(t *testing.T) {
+	r := newRepeater()
+	held := []gesture.Sighting{{Hand: 1, Template: &gesture.Template{ID: "palm"}}}
+	start := time.Now()
+
+	action := &store.Action{RepeatMode: store.RepeatInterval, RepeatIntervalMs: 100}
+	r.start(start, palm, action)
+
+	if due := repeatNow(r, start.Add(50*time.Millisecond), held, action); len(due) != 0 {
+		t.Fatal("expected no repeat before the interval")
+	}
+
+	repeats := 0
+	for ms := 100; ms <= 1000; ms += 100 {
+		repeats += len(repeatNow(r, start.Add(time.Duration(ms)*time.Millisecond), held, action))
+	}
+	if repeats != 10 {
+		t.Errorf("expected 10 repeats over one second, got %d", repeats)
+	}
+}
+
+func TestRepeater_Accelerate(t *testing.T) {
+	r := newRepeater()
+	held := []gesture.Sighting{{Hand: 1, Template: &gesture.Template{ID: "palm"}}}
+	now := time.Now()
+
+	action := &store.Action{
+		RepeatMode:          store.RepeatAccelerate,
+		RepeatIntervalMs:    400,
+		RepeatMinIntervalMs: 100,
+	}
+	r.start(now, palm, action)
+
+	var intervals []time.Duration
+	last := now
+	for i := 0; i < 400 && len(intervals) < 8; i++ {
+		now = now.Add(10 * time.Millisecond)
+		if len(repeatNow(r, now, held, action)) > 0 {
+			intervals = append(intervals, now.Sub(last))
+			last = now
+		}
+	}
+
+	if len(intervals) < 8 {
+		t.Fatalf("expected 8 repeats, got %d", len(intervals))
+	}
+	for i := 1; i < len(intervals); i++ {
+		if intervals[i] > intervals[i-1] {
+			t.Errorf("expected intervals to shrink, got %v", intervals)
+			break
+		}
+	}
+	if got := intervals[len(intervals)-1]; got != 100*time.Millisecond {
+		t.Errorf("expected intervals to settle at the minimum, got %v", got)
+	}
+}
+
+func TestRepeater_StopsOnRelease(t *testing.T) {
+	r := newRepeater()
+	start := time.Now()
+
+	action := &store.Action{RepeatMode: store.RepeatInterval, RepeatIntervalMs: 100}
+	r.start(start, palm, action)
+
+	if due := repeatNow(r, start.Add(200*time.Millisecond), nil, action); len(due) != 0 {
+		t.Error("expected no repeat once the gesture is released")
+	}
+	if due := repeatNow(r, start.Add(300*time.Millisecond), []gesture.Sighting{{Hand: 1, Template: &gesture.Template{ID: "palm"}}}, action); len(due) != 0 {
+		t.Error("expected a released gesture to need a new activation before repeating")
+	}
+}
+
+func TestRepeater_EachHand(t *testing.T) {
+	r := newRepeater()
+	start := time.Now()
+	other := handGesture{hand: 2, gestureID: "palm"}
+	action := &store.Action{RepeatMode: store.RepeatInterval, RepeatIntervalMs: 100}
+
+	r.start(start, palm, action)
+	r.start(start, other, action)
+
+	// The pose held by one hand only keeps repeating on that hand
+	held := []gesture.Sighting{{Hand: 2, Template: &gesture.Template{ID: "palm"}}}
+	due := repeatNow(r, start.Add(100*time.Millisecond), held, action)
+	if len(due) != 1 || due[0] != other {
+		t.Fatalf("expected only the hand still holding to repeat, got %d repeats", len(due))
+	}
+
+	r.forgetHand(2)
+	if due := repeatNow(r, start.Add(200*time.Millisecond), held, action); len(due) != 0 {
+		t.Errorf("expected a forgotten hand to stop repeating, got %d repeats", len(due))
+	}
+}
+
+func TestRepeater_FollowsActionChanges(t *testing.T) {
+	r := newRepeater()
+	held := []gesture.Sighting{{Hand: 1, Template: &gesture.Template{ID: "palm"}}}
+	start := time.Now()
+
+	r.start(start, palm, &store.Action{RepeatMode: store.RepeatInterval, RepeatIntervalMs: 100})
+
+	// A new interval applies from the next repeat
+	faster := &store.Action{RepeatMode: store.RepeatInterval, RepeatIntervalMs: 50}
+	if due := repeatNow(r, start.Add(100*time.Millisecond), held, faster); len(due) != 1 {
+		t.Fatalf("expected a repeat, got %d", len(due))
+	}
+	if due := repeatNow(r, start.Add(150*time.Millisecond), held, faster); len(due) != 1 {
+		t.Errorf("expected the new interval to apply, got %d repeats", len(due))
+	}
+
+	// An action that was unbound, disabled or switched to once stops the repeats
+	for _, action := range []*store.Action{nil, {RepeatMode: store.RepeatOnce, RepeatIntervalMs: 50}} {
+		r.start(start, palm, faster)
+		if due := repeatNow(r, start.Add(time.Second), held, action); len(due) != 0 {
+			t.Errorf("expected no repeat for action %+v, got %d", action, len(due))
+		}
+		if due := repeatNow(r, start.Add(2*time.Second), held, faster); len(due) != 0 {
+			t.Errorf("expected the repeats to stay stopped until the gesture fires again, got %d", len(due))
+		}
+	}
+}
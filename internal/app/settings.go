@@ -0,0 +1,42 @@
+package app
+
+import (
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// ApplySettings applies settings to the running application: the motion
+// threshold, pipeline frame rates, idle timeout, plugin timeout and the
+// confidence required of matches take effect immediately. The camera ID and
+// server port are only read at startup.
+func (a *App) ApplySettings(s store.Settings) {
+	a.motion.SetThreshold(s.MotionThreshold)
+	a.pluginExec.SetTimeout(s.PluginTimeoutMs)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.idleFPS = s.IdleFPS
+	a.activeFPS = s.ActiveFPS
+	a.idleTimeout = time.Duration(s.IdleTimeoutMs) * time.Millisecond
+	a.ambiguity = gesture.Ambiguity{MinScore: s.MinMatchScore, Margin: s.AmbiguityMargin}
+}
+
+// frameRates returns the current idle and active frame rates and the time
+// without motion after which the pipeline returns to idle.
+func (a *App) frameRates() (idleFPS, activeFPS int, idleTimeout time.Duration) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.idleFPS, a.activeFPS, a.idleTimeout
+}
+
+// matchAmbiguity returns how confident the best match must be to act on.
+func (a *App) matchAmbiguity() gesture.Ambiguity {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	return a.ambiguity
+}
@@ -0,0 +1,292 @@
+package capture
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+// readRetryDelay is how long the hub waits before retrying after a failed frame read.
+const readRetryDelay = 100 * time.Millisecond
+
+// SharedFrame is a reference-counted camera frame delivered to hub subscribers.
+// Every receiver must call Release exactly once when it is done with the frame;
+// the underlying Mat is closed when the last reference is released.
+type SharedFrame struct {
+	Mat       *gocv.Mat
+	Timestamp time.Time
+	refs      int32
+}
+
+// Release drops one reference to the frame.
+func (f *SharedFrame) Release() {
+	if atomic.AddInt32(&f.refs, -1) == 0 {
+		f.Mat.Close()
+	}
+}
+
+// FrameHub owns a single Camera and broadcasts its frames to many subscribers.
+// The camera is opened when the first subscriber arrives and closed when the
+// last one leaves. The camera runs at the highest rate any subscriber asks for,
+// and each subscriber only receives frames at its own requested rate.
+type FrameHub struct {
+	camera Camera
+
+	// lifecycle serializes opening and closing the camera.
+	lifecycle sync.Mutex
+
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	fps    int
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// Subscription receives frames from a FrameHub.
+type Subscription struct {
+	hub      *FrameHub
+	frames   chan *SharedFrame
+	fps      int
+	lastSent time.Time
+	closed   bool
+}
+
+// NewFrameHub creates a new FrameHub that shares the given camera.
+func NewFrameHub(camera Camera) *FrameHub {
+	return &FrameHub{
+		camera: camera,
+		subs:   make(map[*Subscription]struct{}),
+		fps:    DefaultFPS,
+	}
+}
+
+// Camera returns the camera owned by the hub.
+func (h *FrameHub) Camera() Camera {
+	return h.camera
+}
+
+// Subscribe registers a subscriber that wants up to fps frames per second.
+// The camera is opened if this is the first subscriber.
+// Values of fps less than or equal to 0 use DefaultFPS.
+func (h *FrameHub) Subscribe(fps int) (*Subscription, error) {
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+
+	h.lifecycle.Lock()
+	defer h.lifecycle.Unlock()
+
+	sub := &Subscription{
+		hub:    h,
+		frames: make(chan *SharedFrame, 1),
+		fps:    fps,
+	}
+
+	h.mu.Lock()
+	first := len(h.subs) == 0
+	h.subs[sub] = struct{}{}
+	h.updateFPSLocked()
+	h.mu.Unlock()
+
+	if first {
+		if err := h.camera.Open(); err != nil {
+			h.mu.Lock()
+			delete(h.subs, sub)
+			h.mu.Unlock()
+			return nil, err
+		}
+
+		h.camera.SetFPS(h.FPS())
+
+		stopCh := make(chan struct{})
+		doneCh := make(chan struct{})
+		h.mu.Lock()
+		h.stopCh = stopCh
+		h.doneCh = doneCh
+		h.mu.Unlock()
+
+		go h.run(stopCh, doneCh)
+	}
+
+	return sub, nil
+}
+
+// SubscriberCount returns the number of active subscribers.
+func (h *FrameHub) SubscriberCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// FPS returns the capture rate, which is the highest rate requested by any subscriber.
+func (h *FrameHub) FPS() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.fps
+}
+
+// unsubscribe removes a subscriber and closes the camera if it was the last one.
+func (h *FrameHub) unsubscribe(sub *Subscription) {
+	h.lifecycle.Lock()
+	defer h.lifecycle.Unlock()
+
+	h.mu.Lock()
+	if sub.closed {
+		h.mu.Unlock()
+		return
+	}
+	sub.closed = true
+	delete(h.subs, sub)
+
+	// Release any frame still queued for this subscriber
+	select {
+	case f := <-sub.frames:
+		f.Release()
+	default:
+	}
+	close(sub.frames)
+
+	last := len(h.subs) == 0
+	stopCh, doneCh := h.stopCh, h.doneCh
+	if last {
+		h.stopCh = nil
+		h.doneCh = nil
+	} else {
+		h.updateFPSLocked()
+	}
+	fps := h.fps
+	h.mu.Unlock()
+
+	if !last {
+		h.camera.SetFPS(fps)
+		return
+	}
+
+	if stopCh != nil {
+		close(stopCh)
+		<-doneCh
+	}
+	h.camera.Close()
+}
+
+// updateFPSLocked recomputes the capture rate from the subscribers.
+// The caller must hold h.mu.
+func (h *FrameHub) updateFPSLocked() {
+	fps := 0
+	for sub := range h.subs {
+		if sub.fps > fps {
+			fps = sub.fps
+		}
+	}
+	if fps <= 0 {
+		fps = DefaultFPS
+	}
+	h.fps = fps
+}
+
+// run reads frames from the camera and dispatches them until stopCh is closed.
+func (h *FrameHub) run(stopCh, doneCh chan struct{}) {
+	defer close(doneCh)
+
+	for {
+		select {
+		case <-stopCh:
+			return
+		default:
+		}
+
+		start := time.Now()
+		interval := time.Second / time.Duration(h.FPS())
+
+		mat, err := h.camera.ReadFrame()
+		if err != nil {
+			interval = readRetryDelay
+		} else {
+			h.dispatch(mat, start)
+		}
+
+		wait := interval - time.Since(start)
+		if wait <= 0 {
+			continue
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// dispatch hands a frame to every subscriber that is due for one.
+// Subscribers that have not consumed their previous frame get it replaced.
+func (h *FrameHub) dispatch(mat *gocv.Mat, now time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var due []*Subscription
+	for sub := range h.subs {
+		interval := time.Second / time.Duration(sub.fps)
+		// Allow 10% jitter so subscribers at the capture rate get every frame
+		if sub.lastSent.IsZero() || now.Sub(sub.lastSent)+interval/10 >= interval {
+			due = append(due, sub)
+		}
+	}
+
+	if len(due) == 0 {
+		mat.Close()
+		return
+	}
+
+	frame := &SharedFrame{
+		Mat:       mat,
+		Timestamp: now,
+		refs:      int32(len(due)),
+	}
+
+	for _, sub := range due {
+		// Drop a stale frame the subscriber has not picked up yet
+		select {
+		case old := <-sub.frames:
+			old.Release()
+		default:
+		}
+
+		select {
+		case sub.frames <- frame:
+			sub.lastSent = now
+		default:
+			frame.Release()
+		}
+	}
+}
+
+// Frames returns the channel on which frames are delivered.
+// The channel is closed when the subscription is closed.
+func (s *Subscription) Frames() <-chan *SharedFrame {
+	return s.frames
+}
+
+// SetFPS changes the rate at which this subscriber receives frames.
+// Values less than or equal to 0 are ignored.
+func (s *Subscription) SetFPS(fps int) {
+	if fps <= 0 {
+		return
+	}
+
+	h := s.hub
+	h.mu.Lock()
+	s.fps = fps
+	h.updateFPSLocked()
+	hubFPS := h.fps
+	h.mu.Unlock()
+
+	h.camera.SetFPS(hubFPS)
+}
+
+// Close unsubscribes from the hub. It is safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.unsubscribe(s)
+}
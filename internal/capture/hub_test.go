@@ -0,0 +1,142 @@
+package capture
+
+import (
+	"testing"
+	"time"
+
+	"gocv.io/x/gocv"
+)
+
+func newTestHub(t *testing.T) (*FrameHub, *MockCamera) {
+	t.Helper()
+
+	frame := gocv.NewMatWithSize(480, 640, gocv.MatTypeCV8UC3)
+	t.Cleanup(func() { frame.Close() })
+
+	cam := NewMockCamera([]*gocv.Mat{&frame}, true)
+	return NewFrameHub(cam), cam
+}
+
+func receiveFrame(t *testing.T, sub *Subscription) *SharedFrame {
+	t.Helper()
+
+	select {
+	case f, ok := <-sub.Frames():
+		if !ok {
+			t.Fatal("frames channel closed unexpectedly")
+		}
+		return f
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for frame")
+	}
+	return nil
+}
+
+func TestFrameHub_OpensAndClosesCameraBySubscriberCount(t *testing.T) {
+	hub, cam := newTestHub(t)
+
+	if cam.IsOpen() {
+		t.Fatal("camera should not be open before any subscriber")
+	}
+
+	sub1, err := hub.Subscribe(30)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	sub2, err := hub.Subscribe(30)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if !cam.IsOpen() {
+		t.Error("camera should be open with subscribers")
+	}
+	if hub.SubscriberCount() != 2 {
+		t.Errorf("SubscriberCount() = %d, want 2", hub.SubscriberCount())
+	}
+
+	sub1.Close()
+	if !cam.IsOpen() {
+		t.Error("camera should stay open while a subscriber remains")
+	}
+
+	sub2.Close()
+	if cam.IsOpen() {
+		t.Error("camera should be closed after the last subscriber leaves")
+	}
+
+	// Closing twice is a no-op
+	sub2.Close()
+}
+
+func TestFrameHub_BroadcastsToAllSubscribers(t *testing.T) {
+	hub, _ := newTestHub(t)
+
+	sub1, err := hub.Subscribe(30)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer sub1.Close()
+
+	sub2, err := hub.Subscribe(30)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer sub2.Close()
+
+	f1 := receiveFrame(t, sub1)
+	f2 := receiveFrame(t, sub2)
+
+	if f1.Mat == nil || f2.Mat == nil {
+		t.Fatal("received frame without a Mat")
+	}
+	f1.Release()
+	f2.Release()
+}
+
+func TestFrameHub_FPSIsHighestRequested(t *testing.T) {
+	hub, _ := newTestHub(t)
+
+	slow, err := hub.Subscribe(5)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	defer slow.Close()
+
+	if hub.FPS() != 5 {
+		t.Errorf("FPS() = %d, want 5", hub.FPS())
+	}
+
+	fast, err := hub.Subscribe(15)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+
+	if hub.FPS() != 15 {
+		t.Errorf("FPS() = %d, want 15", hub.FPS())
+	}
+
+	fast.Close()
+	if hub.FPS() != 5 {
+		t.Errorf("FPS() after fast subscriber left = %d, want 5", hub.FPS())
+	}
+
+	slow.SetFPS(10)
+	if hub.FPS() != 10 {
+		t.Errorf("FPS() after SetFPS = %d, want 10", hub.FPS())
+	}
+}
+
+func TestSharedFrame_Release(t *testing.T) {
+	mat := gocv.NewMatWithSize(10, 10, gocv.MatTypeCV8UC3)
+	f := &SharedFrame{Mat: &mat, refs: 2}
+
+	f.Release()
+	if f.refs != 1 {
+		t.Errorf("refs = %d, want 1", f.refs)
+	}
+	f.Release()
+	if f.refs != 0 {
+		t.Errorf("refs = %d, want 0", f.refs)
+	}
+}
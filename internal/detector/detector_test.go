@@ -108,6 +108,58 @@ func TestHandLandmarks_Normalize(t *testing.T) {
 	})
 }
 
+// rotateHand rotates every landmark of h by angle radians around its wrist,
+// first in the image plane and then, by the same angle, around the vertical axis.
+func rotateHand(h HandLandmarks, angle float64) HandLandmarks {
+	sin, cos := math.Sincos(angle)
+	wrist := h.Points[Wrist]
+	for i, p := range h.Points {
+		x, y, z := p.X-wrist.X, p.Y-wrist.Y, p.Z-wrist.Z
+		x, y = x*cos-y*sin, x*sin+y*cos
+		x, z = x*cos+z*sin, -x*sin+z*cos
+		h.Points[i] = Point3D{X: wrist.X + x, Y: wrist.Y + y, Z: wrist.Z + z}
+	}
+	return h
+}
+
+func TestHandLandmarks_NormalizeOrientation(t *testing.T) {
+	hand := ThumbsUpLandmarks()
+	want := hand.NormalizeOrientation()
+
+	// The wrist→middle MCP axis points up
+	mcp := want.Points[MiddleMCP]
+	if math.Abs(mcp.X) > 1e-9 || math.Abs(mcp.Y+1) > 1e-9 || math.Abs(mcp.Z) > 1e-9 {
+		t.Errorf("expected the middle MCP at (0, -1, 0), got %+v", mcp)
+	}
+
+	// Rotating the whole hand does not change the canonical pose
+	for _, degrees := range []float64{20, -45, 180} {
+		rotated := rotateHand(hand, degrees*math.Pi/180)
+		got := rotated.NormalizeOrientation()
+		for i := range got.Points {
+			if distance3D(got.Points[i], want.Points[i]) > 1e-9 {
+				t.Errorf("rotated by %v°: point %d is %+v, expected %+v", degrees, i, got.Points[i], want.Points[i])
+				break
+			}
+		}
+	}
+
+	// Canonical poses are already in canonical orientation
+	again := CanonicalOrientation(want.Points[:])
+	for i := range again {
+		if distance3D(again[i], want.Points[i]) > 1e-9 {
+			t.Errorf("expected canonicalization to be idempotent, point %d moved to %+v", i, again[i])
+			break
+		}
+	}
+
+	// Partial landmark sets are returned unchanged
+	partial := []Point3D{{X: 1, Y: 2, Z: 3}}
+	if got := CanonicalOrientation(partial); len(got) != 1 || got[0] != partial[0] {
+		t.Errorf("expected a partial set to be returned unchanged, got %+v", got)
+	}
+}
+
 func TestMockDetector(t *testing.T) {
 	t.Run("returns empty hands by default", func(t *testing.T) {
 		mock := NewMockDetector()
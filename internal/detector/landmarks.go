@@ -37,6 +37,49 @@ type Point3D struct {
 	Z float64 `json:"z"`
 }
 
+// Add returns p plus q.
+func (p Point3D) Add(q Point3D) Point3D {
+	return Point3D{X: p.X + q.X, Y: p.Y + q.Y, Z: p.Z + q.Z}
+}
+
+// Sub returns p minus q.
+func (p Point3D) Sub(q Point3D) Point3D {
+	return Point3D{X: p.X - q.X, Y: p.Y - q.Y, Z: p.Z - q.Z}
+}
+
+// Scale returns p multiplied by s.
+func (p Point3D) Scale(s float64) Point3D {
+	return Point3D{X: p.X * s, Y: p.Y * s, Z: p.Z * s}
+}
+
+// Dot returns the dot product of p and q.
+func (p Point3D) Dot(q Point3D) float64 {
+	return p.X*q.X + p.Y*q.Y + p.Z*q.Z
+}
+
+// Cross returns the cross product of p and q.
+func (p Point3D) Cross(q Point3D) Point3D {
+	return Point3D{
+		X: p.Y*q.Z - p.Z*q.Y,
+		Y: p.Z*q.X - p.X*q.Z,
+		Z: p.X*q.Y - p.Y*q.X,
+	}
+}
+
+// Length returns the distance of p from the origin.
+func (p Point3D) Length() float64 {
+	return math.Sqrt(p.Dot(p))
+}
+
+// Unit returns p scaled to length 1, or false if p is too short to have a direction.
+func (p Point3D) Unit() (Point3D, bool) {
+	length := p.Length()
+	if length < 1e-10 {
+		return Point3D{}, false
+	}
+	return p.Scale(1 / length), true
+}
+
 // HandLandmarks represents the 21 hand landmarks detected by MediaPipe.
 type HandLandmarks struct {
 	Points     [NumLandmarks]Point3D `json:"points"`
@@ -52,6 +95,12 @@ func distance3D(a, b Point3D) float64 {
 	return math.Sqrt(dx*dx + dy*dy + dz*dz)
 }
 
+// Size returns the distance from the wrist to the middle finger MCP, the
+// reference length used to normalize hand landmarks.
+func (h *HandLandmarks) Size() float64 {
+	return distance3D(h.Points[Wrist], h.Points[MiddleMCP])
+}
+
 // Normalize normalizes the hand landmarks relative to wrist position and hand size.
 // The normalized landmarks have the wrist at origin (0,0,0) and are scaled
 // so that the distance from wrist to middle finger MCP is 1.0.
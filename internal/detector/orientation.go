@@ -0,0 +1,50 @@
+package detector
+
+// CanonicalOrientation rotates normalized hand landmarks (wrist at the origin)
+// into a canonical orientation: the wrist to middle finger MCP axis points along
+// -Y, as it does for an upright hand in image coordinates, and the palm normal
+// (wrist→index MCP crossed with wrist→pinky MCP) points along +Z.
+// Poses that differ only by a rotation of the whole hand end up identical.
+//
+// points must hold NumLandmarks normalized points. Other slices, and hands whose
+// palm is too degenerate to define a plane, are returned as an unrotated copy.
+func CanonicalOrientation(points []Point3D) []Point3D {
+	rotated := make([]Point3D, len(points))
+	copy(rotated, points)
+	if len(points) != NumLandmarks {
+		return rotated
+	}
+
+	// The wrist→middle MCP axis becomes -Y
+	yAxis, ok := points[MiddleMCP].Scale(-1).Unit()
+	if !ok {
+		return rotated
+	}
+
+	// The palm normal, made perpendicular to that axis, becomes +Z
+	normal := points[IndexMCP].Cross(points[PinkyMCP])
+	zAxis, ok := normal.Sub(yAxis.Scale(normal.Dot(yAxis))).Unit()
+	if !ok {
+		return rotated
+	}
+
+	xAxis := yAxis.Cross(zAxis)
+
+	for i, p := range points {
+		rotated[i] = Point3D{X: p.Dot(xAxis), Y: p.Dot(yAxis), Z: p.Dot(zAxis)}
+	}
+	return rotated
+}
+
+// NormalizeOrientation normalizes the hand landmarks like Normalize and then
+// rotates them into the canonical orientation described by CanonicalOrientation.
+// Returns a new HandLandmarks instance with the rotated points.
+func (h *HandLandmarks) NormalizeOrientation() *HandLandmarks {
+	normalized := h.Normalize()
+	if normalized == nil {
+		return nil
+	}
+
+	copy(normalized.Points[:], CanonicalOrientation(normalized.Points[:]))
+	return normalized
+}
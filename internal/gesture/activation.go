@@ -0,0 +1,242 @@
+package gesture
+
+import (
+	"sync"
+	"time"
+)
+
+// ReleaseTimeout is how long a held gesture may go unseen before it counts as released.
+// It absorbs single dropped or mismatched frames so a steady pose is not split into several holds.
+const ReleaseTimeout = 250 * time.Millisecond
+
+// ActivationConfig controls when a recognized gesture fires its action.
+type ActivationConfig struct {
+	MinHold       time.Duration // How long the gesture must be held before it activates
+	MinFrames     int           // Minimum consecutive matching frames before it activates
+	Cooldown      time.Duration // Minimum time between two firings of the same gesture
+	FireOnRelease bool          // Fire when the activated gesture is released instead of when it activates
+}
+
+// BothHands is the hand a gesture made with both hands together is sighted on,
+// which no single hand's track ID is.
+const BothHands = 0
+
+// Sighting is a gesture seen on one hand, identified by the ID of its track,
+// or on BothHands.
+type Sighting struct {
+	Hand     int
+	Template *Template
+}
+
+// activationKey identifies a gesture made by one hand.
+type activationKey struct {
+	hand int
+	id   string
+}
+
+// activationState tracks one gesture made by one hand through a hold.
+type activationState struct {
+	template  *Template
+	firstSeen time.Time
+	lastSeen  time.Time
+	frames    int
+	active    bool
+	fired     bool
+}
+
+// Activator sits between matching and action execution. It debounces static
+// gestures so that a held pose fires once instead of on every frame, and
+// enforces per-gesture hold, frame-count and cooldown requirements.
+// Each hand holds its gestures on its own: two hands making the same pose are
+// two holds, and the frames of one never count toward the other's. The
+// cooldown belongs to the gesture, whichever hand makes it, so that neither a
+// second hand nor a hand that left and came back fires it again too soon.
+type Activator struct {
+	mu        sync.Mutex
+	states    map[activationKey]*activationState
+	lastFired map[string]time.Time // Last firing of each gesture, by template ID
+}
+
+// NewActivator creates a new Activator instance.
+func NewActivator() *Activator {
+	return &Activator{
+		states:    make(map[activationKey]*activationState),
+		lastFired: make(map[string]time.Time),
+	}
+}
+
+// Observe feeds the static gestures matched on each hand in one frame into the
+// state machine. It must be called for every processed frame, including frames
+// with no matches, so that releases are detected. Returns the gestures that
+// fire on this frame, with the hands they fire for.
+func (a *Activator) Observe(now time.Time, matched []Sighting) []Sighting {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var fired []Sighting
+	seen := make(map[activationKey]bool, len(matched))
+
+	for _, s := range matched {
+		t := s.Template
+		if t == nil {
+			continue
+		}
+		key := activationKey{s.Hand, t.ID}
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		st := a.state(key, t)
+		st.template = t
+		if st.frames == 0 {
+			st.firstSeen = now
+		}
+		st.frames++
+		st.lastSeen = now
+
+		cfg := t.Activation
+		minFrames := cfg.MinFrames
+		if minFrames < 1 {
+			minFrames = 1
+		}
+
+		if !st.active && st.frames >= minFrames && now.Sub(st.firstSeen) >= cfg.MinHold {
+			st.active = true
+			if !cfg.FireOnRelease && a.canFire(now, t) {
+				a.fire(now, st)
+				fired = append(fired, s)
+			}
+		}
+	}
+
+	// Release gestures that have not been seen recently
+	for key, st := range a.states {
+		if seen[key] || st.frames == 0 || now.Sub(st.lastSeen) <= ReleaseTimeout {
+			continue
+		}
+		if a.release(now, st) {
+			fired = append(fired, Sighting{key.hand, st.template})
+		}
+	}
+
+	return fired
+}
+
+// Trigger reports whether a one-shot gesture, such as a completed dynamic
+// gesture, may fire now for hand. Only the cooldown applies to one-shot gestures.
+func (a *Activator) Trigger(now time.Time, hand int, t *Template) bool {
+	if t == nil {
+		return false
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if !a.canFire(now, t) {
+		return false
+	}
+	st := a.state(activationKey{hand, t.ID}, t)
+	st.template = t
+	a.fire(now, st)
+	return true
+}
+
+// Held returns the gestures that fired on activation and are still being held,
+// with the hands holding them. Gestures that fire on release are never reported
+// as held.
+func (a *Activator) Held() []Sighting {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var held []Sighting
+	for key, st := range a.states {
+		if st.frames > 0 && st.active && st.fired && !st.template.Activation.FireOnRelease {
+			held = append(held, Sighting{key.hand, st.template})
+		}
+	}
+	return held
+}
+
+// Forget drops all state for a gesture, for example after it was deleted.
+func (a *Activator) Forget(id string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for key := range a.states {
+		if key.id == id {
+			delete(a.states, key)
+		}
+	}
+	delete(a.lastFired, id)
+}
+
+// ForgetHand releases the gestures held by a hand that is gone, and drops
+// all state for it. The cooldowns of the gestures it fired still apply.
+// Returns the gestures that fire on their release.
+func (a *Activator) ForgetHand(now time.Time, hand int) []Sighting {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var fired []Sighting
+	for key, st := range a.states {
+		if key.hand != hand {
+			continue
+		}
+		if st.frames > 0 && a.release(now, st) {
+			fired = append(fired, Sighting{hand, st.template})
+		}
+		delete(a.states, key)
+	}
+	return fired
+}
+
+// Reset releases all held gestures without firing them.
+func (a *Activator) Reset() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, st := range a.states {
+		st.reset()
+	}
+}
+
+// state returns the tracked state for a template on a hand, creating it if needed.
+// The caller must hold a.mu.
+func (a *Activator) state(key activationKey, t *Template) *activationState {
+	st, ok := a.states[key]
+	if !ok {
+		st = &activationState{template: t}
+		a.states[key] = st
+	}
+	return st
+}
+
+// canFire reports whether the cooldown of a gesture has elapsed since it last
+// fired on any hand. The caller must hold a.mu.
+func (a *Activator) canFire(now time.Time, t *Template) bool {
+	last, ok := a.lastFired[t.ID]
+	return !ok || now.Sub(last) >= t.Activation.Cooldown
+}
+
+// fire records that the gesture held in st fired. The caller must hold a.mu.
+func (a *Activator) fire(now time.Time, st *activationState) {
+	st.fired = true
+	a.lastFired[st.template.ID] = now
+}
+
+// release ends the hold in st, firing the gesture if it fires on release.
+// Returns whether it fired. The caller must hold a.mu.
+func (a *Activator) release(now time.Time, st *activationState) bool {
+	fire := st.active && !st.fired && st.template.Activation.FireOnRelease && a.canFire(now, st.template)
+	if fire {
+		a.fire(now, st)
+	}
+	st.reset()
+	return fire
+}
+
+// reset ends the current hold.
+func (st *activationState) reset() {
+	st.frames = 0
+	st.active = false
+	st.fired = false
+}
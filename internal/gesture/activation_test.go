@@ -0,0 +1,251 @@
+package gesture
+
+import (
+	"testing"
+	"time"
+)
+
+// frameInterval is the time between frames in activation tests (roughly 15 FPS).
+const frameInterval = 66 * time.Millisecond
+
+// onHand returns the sightings of templates on hand.
+func onHand(hand int, templates ...*Template) []Sighting {
+	sightings := make([]Sighting, len(templates))
+	for i, t := range templates {
+		sightings[i] = Sighting{Hand: hand, Template: t}
+	}
+	return sightings
+}
+
+// observeFrames feeds n consecutive frames matching t and returns how many times it fired.
+func observeFrames(a *Activator, start time.Time, n int, t *Template) (time.Time, int) {
+	fired := 0
+	now := start
+	for i := 0; i < n; i++ {
+		fired += len(a.Observe(now, onHand(1, t)))
+		now = now.Add(frameInterval)
+	}
+	return now, fired
+}
+
+func TestActivator_FiresOncePerHold(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 3}}
+
+	_, fired := observeFrames(a, time.Now(), 30, tmpl)
+	if fired != 1 {
+		t.Errorf("expected a held gesture to fire once, fired %d times", fired)
+	}
+}
+
+func TestActivator_MinFrames(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 3}}
+	now := time.Now()
+
+	now, fired := observeFrames(a, now, 2, tmpl)
+	if fired != 0 {
+		t.Fatalf("expected no firing before min frames, fired %d times", fired)
+	}
+
+	if got := a.Observe(now, onHand(1, tmpl)); len(got) != 1 {
+		t.Errorf("expected firing on the third frame, got %d", len(got))
+	}
+}
+
+func TestActivator_MinHold(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 1, MinHold: 500 * time.Millisecond}}
+	start := time.Now()
+
+	if got := a.Observe(start, onHand(1, tmpl)); len(got) != 0 {
+		t.Fatal("expected no firing before the hold time")
+	}
+	if got := a.Observe(start.Add(200*time.Millisecond), onHand(1, tmpl)); len(got) != 0 {
+		t.Fatal("expected no firing before the hold time")
+	}
+	if got := a.Observe(start.Add(500*time.Millisecond), onHand(1, tmpl)); len(got) != 1 {
+		t.Errorf("expected firing once the hold time elapsed, got %d", len(got))
+	}
+}
+
+func TestActivator_Cooldown(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 1, Cooldown: time.Second}}
+	start := time.Now()
+
+	if got := a.Observe(start, onHand(1, tmpl)); len(got) != 1 {
+		t.Fatal("expected the first hold to fire")
+	}
+
+	// Release, then hold again within the cooldown
+	a.Observe(start.Add(400*time.Millisecond), nil)
+	if got := a.Observe(start.Add(500*time.Millisecond), onHand(1, tmpl)); len(got) != 0 {
+		t.Error("expected no firing within the cooldown")
+	}
+
+	// Release, then hold again after the cooldown
+	a.Observe(start.Add(900*time.Millisecond), nil)
+	if got := a.Observe(start.Add(1100*time.Millisecond), onHand(1, tmpl)); len(got) != 1 {
+		t.Error("expected firing after the cooldown")
+	}
+}
+
+func TestActivator_FireOnRelease(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 3, FireOnRelease: true}}
+
+	now, fired := observeFrames(a, time.Now(), 10, tmpl)
+	if fired != 0 {
+		t.Fatalf("expected no firing while held, fired %d times", fired)
+	}
+
+	// A single missed frame within the release timeout does not release the gesture
+	if got := a.Observe(now, nil); len(got) != 0 {
+		t.Fatal("expected no firing before the release timeout")
+	}
+
+	got := a.Observe(now.Add(ReleaseTimeout+frameInterval), nil)
+	if len(got) != 1 || got[0].Template != tmpl {
+		t.Errorf("expected firing on release, got %d", len(got))
+	}
+}
+
+func TestActivator_FireOnRelease_NotActivated(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 5, FireOnRelease: true}}
+
+	now, _ := observeFrames(a, time.Now(), 2, tmpl)
+	if got := a.Observe(now.Add(ReleaseTimeout+frameInterval), nil); len(got) != 0 {
+		t.Error("expected a gesture released before activation not to fire")
+	}
+}
+
+func TestActivator_Trigger(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "swipe", Activation: ActivationConfig{Cooldown: time.Second}}
+	start := time.Now()
+
+	if !a.Trigger(start, 1, tmpl) {
+		t.Fatal("expected the first trigger to fire")
+	}
+	if a.Trigger(start.Add(500*time.Millisecond), 1, tmpl) {
+		t.Error("expected no firing within the cooldown")
+	}
+	if !a.Trigger(start.Add(time.Second), 1, tmpl) {
+		t.Error("expected firing after the cooldown")
+	}
+}
+
+func TestActivator_Reset(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 1, FireOnRelease: true}}
+	start := time.Now()
+
+	a.Observe(start, onHand(1, tmpl))
+	a.Reset()
+
+	if got := a.Observe(start.Add(time.Second), nil); len(got) != 0 {
+		t.Error("expected a reset gesture not to fire on release")
+	}
+}
+
+func TestActivator_Held(t *testing.T) {
+	a := NewActivator()
+	palm := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 2}}
+	fist := &Template{ID: "fist", Activation: ActivationConfig{MinFrames: 2, FireOnRelease: true}}
+	start := time.Now()
+
+	a.Observe(start, onHand(1, palm, fist))
+	if held := a.Held(); len(held) != 0 {
+		t.Fatalf("expected nothing held before activation, got %d", len(held))
+	}
+
+	a.Observe(start.Add(frameInterval), onHand(1, palm, fist))
+	held := a.Held()
+	if len(held) != 1 || held[0].Template != palm {
+		t.Fatalf("expected only the fired gesture to be held, got %v", held)
+	}
+
+	a.Observe(start.Add(time.Second), nil)
+	if held := a.Held(); len(held) != 0 {
+		t.Errorf("expected nothing held after release, got %d", len(held))
+	}
+}
+
+func TestActivator_HandsHoldApart(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 3}}
+	now := time.Now()
+
+	// One hand, then the other, never three frames on the same hand
+	for _, hand := range []int{1, 2, 1, 2} {
+		if got := a.Observe(now, onHand(hand, tmpl)); len(got) != 0 {
+			t.Fatalf("expected the frames of one hand not to count toward the other, fired %v", got)
+		}
+		now = now.Add(frameInterval)
+	}
+
+	// Both hands hold the pose, each firing once
+	var fired []Sighting
+	for range 3 {
+		fired = append(fired, a.Observe(now, append(onHand(1, tmpl), onHand(2, tmpl)...))...)
+		now = now.Add(frameInterval)
+	}
+	if len(fired) != 2 || fired[0].Hand == fired[1].Hand {
+		t.Errorf("expected each hand to fire once, got %v", fired)
+	}
+	if held := a.Held(); len(held) != 2 {
+		t.Errorf("expected the pose held by both hands, got %v", held)
+	}
+}
+
+func TestActivator_ForgetHand(t *testing.T) {
+	a := NewActivator()
+	palm := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 1}}
+	fist := &Template{ID: "fist", Activation: ActivationConfig{MinFrames: 1, FireOnRelease: true}}
+	now := time.Now()
+
+	a.Observe(now, append(onHand(1, palm, fist), onHand(2, palm)...))
+
+	// The hand leaves holding both: the gesture that fires on release fires
+	got := a.ForgetHand(now.Add(frameInterval), 1)
+	if len(got) != 1 || got[0].Template != fist || got[0].Hand != 1 {
+		t.Fatalf("expected the fist to fire on release, got %v", got)
+	}
+	if held := a.Held(); len(held) != 1 || held[0].Hand != 2 {
+		t.Errorf("expected only the other hand still holding, got %v", held)
+	}
+
+	// Nothing is left of the hand
+	if got := a.Observe(now.Add(time.Second), nil); len(got) != 0 {
+		t.Errorf("expected a forgotten hand not to fire again, got %v", got)
+	}
+}
+
+func TestActivator_CooldownAcrossHands(t *testing.T) {
+	a := NewActivator()
+	tmpl := &Template{ID: "palm", Activation: ActivationConfig{MinFrames: 1, Cooldown: 2 * time.Second}}
+	now := time.Now()
+
+	if got := a.Observe(now, onHand(1, tmpl)); len(got) != 1 {
+		t.Fatalf("expected the first hand to fire, got %v", got)
+	}
+
+	// The hand leaves for longer than its track lasts and comes back as a new
+	// hand, still within the cooldown
+	a.ForgetHand(now.Add(frameInterval), 1)
+	now = now.Add(TrackTimeout + time.Second)
+	if got := a.Observe(now, onHand(2, tmpl)); len(got) != 0 {
+		t.Errorf("expected no firing for a returning hand within the cooldown, got %v", got)
+	}
+
+	// Two hands making the pose together fire it once
+	now = now.Add(5 * time.Second)
+	if got := a.Observe(now, append(onHand(3, tmpl), onHand(4, tmpl)...)); len(got) != 1 {
+		t.Errorf("expected two hands to fire the gesture once, got %v", got)
+	}
+	if held := a.Held(); len(held) != 1 {
+		t.Errorf("expected only the hand that fired to count as holding, got %v", held)
+	}
+}
@@ -0,0 +1,67 @@
+package gesture
+
+// Outcome is what matching a hand, or a pair of hands, decided.
+type Outcome string
+
+const (
+	// OutcomeNone means no template matched confidently enough.
+	OutcomeNone Outcome = "none"
+	// OutcomeMatch means the best template matched clearly.
+	OutcomeMatch Outcome = "match"
+	// OutcomeAmbiguous means two templates matched almost equally well, so
+	// neither can be told apart from the other.
+	OutcomeAmbiguous Outcome = "ambiguous"
+)
+
+// Ambiguity decides whether the best of several matches is confident enough
+// to act on. The zero value accepts the best match unconditionally.
+type Ambiguity struct {
+	// MinScore is the score the best match must reach for any gesture to be
+	// recognized, on top of the tolerance of its template. 0 disables it.
+	MinScore float64
+	// Margin is how much better than the runner-up the best match must score,
+	// relative to its own score: (best - runner-up) / best. Below it, the
+	// outcome is ambiguous. 0 disables it.
+	Margin float64
+}
+
+// Decision is the outcome of matching, with the matches it was decided from.
+type Decision struct {
+	Outcome  Outcome
+	Best     *Match // Best match, nil if there were no matches
+	RunnerUp *Match // Second best match, nil if there was only one
+}
+
+// Decide applies the confidence floor and the margin test to matches, which
+// must be sorted best first as returned by the matchers.
+func (a Ambiguity) Decide(matches []Match) Decision {
+	var d Decision
+	if len(matches) == 0 {
+		d.Outcome = OutcomeNone
+		return d
+	}
+
+	d.Best = &matches[0]
+	if len(matches) > 1 {
+		d.RunnerUp = &matches[1]
+	}
+
+	switch {
+	case d.Best.Score < a.MinScore:
+		d.Outcome = OutcomeNone
+	case d.RunnerUp != nil && d.Best.Score > 0 && (d.Best.Score-d.RunnerUp.Score)/d.Best.Score < a.Margin:
+		d.Outcome = OutcomeAmbiguous
+	default:
+		d.Outcome = OutcomeMatch
+	}
+	return d
+}
+
+// Matched returns the template the decision settled on, or nil unless the
+// outcome is a match.
+func (d Decision) Matched() *Template {
+	if d.Outcome != OutcomeMatch {
+		return nil
+	}
+	return d.Best.Template
+}
@@ -0,0 +1,74 @@
+package gesture
+
+import (
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+func TestAmbiguity_Decide(t *testing.T) {
+	a := &Template{ID: "a"}
+	b := &Template{ID: "b"}
+
+	tests := []struct {
+		name      string
+		ambiguity Ambiguity
+		matches   []Match
+		want      Outcome
+	}{
+		{"no matches", Ambiguity{Margin: 0.05}, nil, OutcomeNone},
+		{"a single match", Ambiguity{Margin: 0.05}, []Match{{Template: a, Score: 0.5}}, OutcomeMatch},
+		{"a clear winner", Ambiguity{Margin: 0.05}, []Match{{Template: a, Score: 0.9}, {Template: b, Score: 0.7}}, OutcomeMatch},
+		{"nearly equal scores", Ambiguity{Margin: 0.05}, []Match{{Template: a, Score: 0.9}, {Template: b, Score: 0.88}}, OutcomeAmbiguous},
+		{"nearly equal scores without a margin", Ambiguity{}, []Match{{Template: a, Score: 0.9}, {Template: b, Score: 0.88}}, OutcomeMatch},
+		{"below the confidence floor", Ambiguity{MinScore: 0.8}, []Match{{Template: a, Score: 0.7}}, OutcomeNone},
+		{"the floor applies before the margin", Ambiguity{MinScore: 0.8, Margin: 0.05}, []Match{{Template: a, Score: 0.7}, {Template: b, Score: 0.7}}, OutcomeNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.ambiguity.Decide(tt.matches)
+			if d.Outcome != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, d.Outcome)
+			}
+
+			matched := d.Matched()
+			if tt.want == OutcomeMatch && matched != a {
+				t.Errorf("expected template a to be matched, got %v", matched)
+			}
+			if tt.want != OutcomeMatch && matched != nil {
+				t.Errorf("expected no template to be matched, got %v", matched)
+			}
+			if len(tt.matches) > 1 && d.RunnerUp != &tt.matches[1] {
+				t.Errorf("expected the second match as runner-up, got %v", d.RunnerUp)
+			}
+		})
+	}
+}
+
+func TestAmbiguity_NearIdenticalTemplates(t *testing.T) {
+	palm := detector.OpenPalmLandmarks()
+	thumbsUp := detector.ThumbsUpLandmarks()
+	tilted := tiltHand(palm, 0.01)
+	between := tiltHand(palm, 0.005)
+
+	matcher := NewStaticMatcher()
+	matcher.AddTemplate(&Template{ID: "palm", Type: TypeStatic, Landmarks: palm.Normalize().Points[:], Tolerance: 0.5})
+	matcher.AddTemplate(&Template{ID: "wave", Type: TypeStatic, Landmarks: tilted.Normalize().Points[:], Tolerance: 0.5})
+	matcher.AddTemplate(&Template{ID: "thumbs-up", Type: TypeStatic, Landmarks: thumbsUp.Normalize().Points[:], Tolerance: 0.5})
+
+	ambiguity := Ambiguity{Margin: 0.05}
+
+	// A pose halfway between two templates recorded from nearly the same pose
+	// cannot be told apart
+	d := ambiguity.Decide(matcher.Match(&between))
+	if d.Outcome != OutcomeAmbiguous {
+		t.Errorf("expected a pose between the palms to be ambiguous, got %s", d.Outcome)
+	}
+
+	// A distinct pose still matches
+	d = ambiguity.Decide(matcher.Match(&thumbsUp))
+	if d.Outcome != OutcomeMatch || d.Best.Template.ID != "thumbs-up" {
+		t.Errorf("expected a thumbs up to match, got %+v", d)
+	}
+}
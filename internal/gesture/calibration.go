@@ -0,0 +1,75 @@
+package gesture
+
+import "sort"
+
+// toleranceMargin is how much room a recommended tolerance leaves beyond the
+// training sample furthest from the template, for the variation of live input.
+const toleranceMargin = 1.25
+
+// Calibration recommends a tolerance for a trained template and reports which
+// other templates its gesture is likely to be confused with.
+type Calibration struct {
+	Tolerance  float64     `json:"tolerance"`            // Recommended tolerance
+	Spread     float64     `json:"spread"`               // Distance of the training sample furthest from the template
+	Separation float64     `json:"separation,omitempty"` // Mean distance of the samples from the nearest other template, 0 if none
+	Confusions []Confusion `json:"confusions,omitempty"` // Other templates, nearest first
+}
+
+// Confusion compares the training samples of a gesture with another template.
+type Confusion struct {
+	ID       string  `json:"id"`
+	Name     string  `json:"name"`
+	Distance float64 `json:"distance"` // Mean distance of the samples from the other template
+	Samples  int     `json:"samples"`  // Samples nearer to the other template than to their own
+	Likely   bool    `json:"likely"`   // Input meant for one of the gestures may be taken for the other
+}
+
+// Comparison holds the distances of a gesture's training samples from another
+// template, measured the way the gesture's own template compares input.
+type Comparison struct {
+	ID          string
+	Name        string
+	Diagnostics *Diagnostics
+}
+
+// Calibrate recommends a tolerance from how far the training samples lie from
+// their own template (diag) and from the other templates of the same type.
+// The tolerance covers the furthest sample with some margin, but stays below
+// half the separation from the nearest other template so that the two do not
+// overlap. fallback is recommended when neither gives anything to go by, such
+// as for a single sample and no other gestures.
+func Calibrate(diag *Diagnostics, others []Comparison, fallback float64) *Calibration {
+	c := &Calibration{Spread: diag.MaxDeviation}
+	needed := c.Spread * toleranceMargin
+
+	for _, other := range others {
+		confusion := Confusion{
+			ID:       other.ID,
+			Name:     other.Name,
+			Distance: other.Diagnostics.MeanDeviation,
+		}
+		for i, d := range other.Diagnostics.Deviations {
+			if i < len(diag.Deviations) && d < diag.Deviations[i] {
+				confusion.Samples++
+			}
+		}
+		confusion.Likely = confusion.Samples > 0 || confusion.Distance < 2*needed
+		c.Confusions = append(c.Confusions, confusion)
+	}
+	sort.SliceStable(c.Confusions, func(i, j int) bool {
+		return c.Confusions[i].Distance < c.Confusions[j].Distance
+	})
+
+	c.Tolerance = needed
+	if len(c.Confusions) > 0 {
+		c.Separation = c.Confusions[0].Distance
+		if limit := c.Separation / 2; limit > 0 && (c.Tolerance == 0 || c.Tolerance > limit) {
+			c.Tolerance = limit
+		}
+	}
+	if c.Tolerance == 0 {
+		c.Tolerance = fallback
+	}
+
+	return c
+}
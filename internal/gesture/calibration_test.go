@@ -0,0 +1,58 @@
+package gesture
+
+import "testing"
+
+func TestCalibrate(t *testing.T) {
+	own := newDiagnostics([]float64{0.1, 0.2, 0.4})
+
+	tests := []struct {
+		name          string
+		diag          *Diagnostics
+		others        []Comparison
+		wantTolerance float64
+	}{
+		{"covers the furthest sample with a margin", own, nil, 0.5},
+		{"falls back without anything to go by", newDiagnostics([]float64{0}), nil, 0.15},
+		{"stays clear of a distant template", own, []Comparison{
+			{ID: "far", Diagnostics: newDiagnostics([]float64{3, 3, 3})},
+		}, 0.5},
+		{"stops halfway to a nearby template", own, []Comparison{
+			{ID: "far", Diagnostics: newDiagnostics([]float64{3, 3, 3})},
+			{ID: "near", Diagnostics: newDiagnostics([]float64{0.7, 0.8, 0.9})},
+		}, 0.4},
+		{"stops halfway to the nearest template for a single sample", newDiagnostics([]float64{0}), []Comparison{
+			{ID: "near", Diagnostics: newDiagnostics([]float64{0.6})},
+		}, 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := Calibrate(tt.diag, tt.others, 0.15)
+			if !floatEqual(c.Tolerance, tt.wantTolerance) {
+				t.Errorf("expected tolerance %f, got %f", tt.wantTolerance, c.Tolerance)
+			}
+		})
+	}
+}
+
+func TestCalibrate_Confusions(t *testing.T) {
+	own := newDiagnostics([]float64{0.1, 0.2, 0.4})
+	c := Calibrate(own, []Comparison{
+		{ID: "far", Name: "Far", Diagnostics: newDiagnostics([]float64{3, 3, 3})},
+		// The third sample lies nearer to this template than to its own
+		{ID: "near", Name: "Near", Diagnostics: newDiagnostics([]float64{0.7, 0.8, 0.3})},
+	}, 0.15)
+
+	if len(c.Confusions) != 2 || c.Confusions[0].ID != "near" || c.Confusions[1].ID != "far" {
+		t.Fatalf("expected confusions nearest first, got %+v", c.Confusions)
+	}
+	if near := c.Confusions[0]; !near.Likely || near.Samples != 1 || !floatEqual(near.Distance, 0.6) {
+		t.Errorf("expected a likely confusion with one sample nearer to it, got %+v", near)
+	}
+	if far := c.Confusions[1]; far.Likely || far.Samples != 0 {
+		t.Errorf("expected no confusion with the distant template, got %+v", far)
+	}
+	if !floatEqual(c.Separation, 0.6) || !floatEqual(c.Spread, 0.4) {
+		t.Errorf("expected separation 0.6 and spread 0.4, got %+v", c)
+	}
+}
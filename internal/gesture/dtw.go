@@ -2,44 +2,125 @@ package gesture
 
 import (
 	"math"
+	"slices"
 	"sort"
+	"sync"
 )
 
+// dtwBand is the width of the Sakoe-Chiba band dynamic gestures are warped
+// within, as a fraction of the template's length: each point of a performance
+// is only compared with the template points that close to where the diagonal
+// from the first points to the last crosses its row. Performances of a gesture
+// differ mostly in their overall speed, which the diagonal follows, and the
+// band keeps DTW from pairing up unrelated parts of two paths while bounding
+// its cost.
+const dtwBand = 0.25
+
 // DTWDistance calculates Dynamic Time Warping distance between two paths.
 // Returns infinity if either path is empty.
 // The distance is normalized by the maximum path length.
 func DTWDistance(path1, path2 []PathPoint) float64 {
-	n := len(path1)
-	m := len(path2)
+	var w warper
+	return w.distance(len(path1), len(path2), max(len(path1), len(path2)), math.Inf(1), func(i, j int) float64 {
+		return pointDistance(path1[i], path2[j])
+	})
+}
 
-	// Handle empty paths
+// bandRadius returns how many template points away from the diagonal a
+// sequence of n elements is warped against a template of m elements:
+// dtwBand of the template, widened where the diagonal is so steep that a
+// narrower band would leave no way from one row to the next.
+func bandRadius(n, m int) int {
+	radius := int(math.Ceil(dtwBand * float64(m)))
+	if n > 1 {
+		radius = max(radius, int(math.Ceil(float64(m-1)/float64(n-1))))
+	}
+	return max(radius, 1)
+}
+
+// warper computes DTW distances with two rows of the cost matrix, reused
+// from one computation to the next. The zero value is ready to use; a warper
+// must not be used by several goroutines at once.
+type warper struct {
+	prev, curr           []float64
+	prevStart, currStart []int
+}
+
+// rows returns the two rows for a matrix n cells wide, every cell infinite.
+func (w *warper) rows(n int) (prev, curr []float64) {
+	if cap(w.prev) < n {
+		w.prev = make([]float64, n)
+		w.curr = make([]float64, n)
+	}
+	prev, curr = w.prev[:n], w.curr[:n]
+	for j := range prev {
+		prev[j] = math.Inf(1)
+		curr[j] = math.Inf(1)
+	}
+	return prev, curr
+}
+
+// distance calculates the Dynamic Time Warping distance between sequences of
+// lengths n and m, where cost(i, j) is the distance between their i-th and
+// j-th elements, normalized by the maximum sequence length.
+// Element i of the first sequence is only compared with elements of the second
+// within radius of the diagonal running from their first elements to their
+// last (a Sakoe-Chiba band); a radius of m or more compares every pair.
+// The computation is abandoned as soon as the distance is certain to exceed
+// limit. Returns infinity if it was, or if either sequence is empty.
+func (w *warper) distance(n, m, radius int, limit float64, cost func(i, j int) float64) float64 {
+	// Handle empty sequences
 	if n == 0 || m == 0 {
 		return math.Inf(1)
 	}
 
-	// Create (n+1) x (m+1) cost matrix initialized to infinity
-	dtw := make([][]float64, n+1)
-	for i := range dtw {
-		dtw[i] = make([]float64, m+1)
-		for j := range dtw[i] {
-			dtw[i][j] = math.Inf(1)
+	prev, curr := w.rows(m)
+	norm := float64(max(n, m))
+
+	for i := 0; i < n; i++ {
+		// Cells of row i within the band
+		lo, hi := 0, m-1
+		if n > 1 {
+			center := float64(i) * float64(m-1) / float64(n-1)
+			lo = max(lo, int(math.Ceil(center-float64(radius))))
+			hi = min(hi, int(math.Floor(center+float64(radius))))
+		}
+		if lo > 0 {
+			curr[lo-1] = math.Inf(1) // Left of the band, holding a stale row
 		}
-	}
 
-	// Set dtw[0][0] = 0
-	dtw[0][0] = 0
+		rowMin := math.Inf(1)
+		for j := lo; j <= hi; j++ {
+			// Cost is the distance between current elements plus minimum of three neighbors
+			var best float64
+			switch {
+			case i == 0 && j == 0:
+				best = 0
+			case i == 0:
+				best = curr[j-1]
+			case j == 0:
+				best = prev[j]
+			default:
+				best = min3(prev[j], curr[j-1], prev[j-1])
+			}
+			if math.IsInf(best, 1) {
+				curr[j] = best
+				continue
+			}
+			curr[j] = best + cost(i, j)
+			rowMin = math.Min(rowMin, curr[j])
+		}
 
-	// Fill in the cost matrix
-	for i := 1; i <= n; i++ {
-		for j := 1; j <= m; j++ {
-			// Cost is the distance between current points plus minimum of three neighbors
-			cost := pointDistance(path1[i-1], path2[j-1])
-			dtw[i][j] = cost + min3(dtw[i-1][j], dtw[i][j-1], dtw[i-1][j-1])
+		// Every warping path crosses every row, and costs only add up
+		if rowMin > limit*norm {
+			return math.Inf(1)
 		}
+
+		prev, curr = curr, prev
 	}
 
 	// Return normalized distance
-	return dtw[n][m] / float64(max(n, m))
+	return prev[m-1] / norm
 }
 
 // pointDistance calculates the Euclidean distance between two PathPoints.
@@ -69,85 +150,231 @@ func max(a, b int) int {
 }
 
 // DynamicMatcher matches dynamic gestures against registered templates using DTW.
+// Templates may be added or removed while Match is running on another goroutine.
 type DynamicMatcher struct {
-	templates []*Template
+	mu        sync.RWMutex
+	templates []*dynamicTemplate
+	warpers   sync.Pool
 	OnMatch   func(id, name string)
 }
 
+// dynamicTemplate is a template with its path prepared for matching once, when
+// it is added, rather than on every frame.
+type dynamicTemplate struct {
+	*Template
+	locating    *motion // Path as the locating options prepare it, nil if the template cannot be matched
+	locatingKey string
+	motion      *motion // Path with every feature
+	envelope    envelope
+	extent      float64
+}
+
+// newDynamicTemplate prepares t for matching.
+func newDynamicTemplate(t *Template) *dynamicTemplate {
+	d := &dynamicTemplate{Template: t}
+	if t.Type != TypeDynamic || len(t.Path) == 0 {
+		return d
+	}
+
+	locating := t.Dynamic.locating()
+	located, ok := locating.motion(t.Path)
+	if !ok {
+		return d
+	}
+	full, ok := t.Dynamic.motion(t.Path)
+	if !ok {
+		return d
+	}
+
+	m := len(full.vectors)
+	d.locating, d.locatingKey, d.motion = located, locating.key(), full
+	d.envelope = newEnvelope(full, bandRadius(m, m))
+	d.extent = t.Dynamic.extent(t.Path)
+	return d
+}
+
 // NewDynamicMatcher creates a new DynamicMatcher instance.
 func NewDynamicMatcher() *DynamicMatcher {
 	return &DynamicMatcher{
-		templates: make([]*Template, 0),
+		templates: make([]*dynamicTemplate, 0),
+		warpers:   sync.Pool{New: func() any { return new(warper) }},
 	}
 }
 
 // AddTemplate adds a gesture template to the matcher.
+// A template with the same ID is replaced.
 func (m *DynamicMatcher) AddTemplate(t *Template) {
 	if t == nil {
 		return
 	}
-	m.templates = append(m.templates, t)
+
+	prepared := newDynamicTemplate(t)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = upsertTemplate(m.templates, prepared)
 }
 
 // RemoveTemplate removes a template by its ID.
 func (m *DynamicMatcher) RemoveTemplate(id string) {
-	for i, t := range m.templates {
-		if t.ID == id {
-			// Remove element by shifting
-			m.templates = append(m.templates[:i], m.templates[i+1:]...)
-			return
-		}
-	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = removeTemplate(m.templates, id)
+}
+
+// snapshot returns the current template list.
+// The returned slice is never modified, so it can be read without holding the lock.
+func (m *DynamicMatcher) snapshot() []*dynamicTemplate {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.templates
 }
 
 // Match finds matching templates for the given path.
+// The path may hold more than the gesture: each template is matched against the
+// window of the path it fits best, reported in the match's Start and End.
 // Returns matches sorted by score in descending order (best matches first).
 func (m *DynamicMatcher) Match(path []PathPoint) []Match {
+	return m.search(path, search{maxDistance: math.Inf(1)})
+}
+
+// MatchClosest finds the matches of path that ambiguity decides between, for
+// a path that may still be growing: those whose window ended before the last
+// point of path (see Completed), and of them only the best and those scoring
+// close enough to it to make it ambiguous. Templates that cannot be among them
+// are ruled out as early as possible, which makes it much cheaper than Match
+// with many templates.
+// ambiguity.Decide(MatchClosest(path, ambiguity)) reaches the same outcome,
+// with the same best match, as ambiguity.Decide(Completed(Match(path), len(path))).
+func (m *DynamicMatcher) MatchClosest(path []PathPoint, ambiguity Ambiguity) []Match {
+	s := search{completed: true, closest: true, margin: ambiguity.Margin, maxDistance: math.Inf(1)}
+	if ambiguity.MinScore > 0 {
+		// The best match must score at least MinScore, so lie within this distance
+		s.maxDistance = 1/ambiguity.MinScore - 1
+	}
+	return m.search(path, s)
+}
+
+// search selects the matches a search for templates in a path looks for.
+type search struct {
+	completed   bool    // Only windows that ended before the last point of the path
+	closest     bool    // Only the best match and those within margin of it
+	margin      float64 // Ambiguity.Margin, for closest
+	maxDistance float64 // Distance beyond which no match can be the best one
+}
+
+// limit returns the distance beyond which a template cannot be among the
+// matches searched for, once a template has been found at distance best.
+func (s search) limit(best float64) float64 {
+	if !s.closest {
+		return math.Inf(1)
+	}
+
+	// A runner-up is close enough to be ambiguous while
+	// (1/(1+best) - 1/(1+d)) / (1/(1+best)) < margin
+	best = math.Min(best, s.maxDistance)
+	if s.margin >= 1 {
+		return math.Inf(1)
+	}
+	return (1+best)/(1-s.margin) - 1
+}
+
+// search finds the matches of the templates in path selected by s: each
+// template is located in path, its window bounded from below cheaply against
+// the template's envelope, and only then measured by banded DTW, closest
+// bound first and abandoned as soon as it is out of tolerance or, when s asks
+// for the closest matches only, further than the best so far allows.
+// Returns matches sorted by score in descending order (best matches first).
+func (m *DynamicMatcher) search(path []PathPoint, s search) []Match {
 	if len(path) == 0 {
 		return nil
 	}
 
-	// Normalize input path
-	normalizedInput := normalizePath(path)
-	if len(normalizedInput) == 0 {
-		return nil
+	w := m.warpers.Get().(*warper)
+	defer m.warpers.Put(w)
+
+	// Input prepared the way each template locates itself, computed on first use
+	inputs := make(map[string]*motion)
+
+	type candidate struct {
+		template   *dynamicTemplate
+		window     *motion
+		start, end int
+		bound      float64
 	}
+	var candidates []candidate
 
-	var matches []Match
+	for _, template := range m.snapshot() {
+		// Skip templates that are not dynamic, have empty paths or cannot be compared
+		if template.locating == nil {
+			continue
+		}
 
-	for _, template := range m.templates {
-		// Skip non-dynamic templates
-		if template.Type != TypeDynamic {
+		// Prepare the input path, skipping templates that follow landmarks it lacks
+		input, seen := inputs[template.locatingKey]
+		if !seen {
+			input, _ = template.Dynamic.locating().motion(path)
+			inputs[template.locatingKey] = input
+		}
+		if input == nil {
 			continue
 		}
 
-		// Skip templates with empty paths
-		if len(template.Path) == 0 {
+		// Find the window of the input performing the gesture
+		start, end, ok := template.locate(w, path, input)
+		if !ok || (s.completed && end >= len(path)) {
 			continue
 		}
 
-		// Normalize template path
-		normalizedTemplate := normalizePath(template.Path)
+		window, ok := template.Dynamic.motion(path[start:end])
+		if !ok {
+			continue
+		}
 
-		// Calculate DTW distance
-		distance := DTWDistance(normalizedInput, normalizedTemplate)
+		// Rule out windows that cannot come within tolerance
+		limit := math.Min(template.Tolerance, s.limit(s.maxDistance))
+		n := len(window.vectors)
+		bound := template.envelope.lowerBound(window, bandRadius(n, len(template.motion.vectors)), limit)
+		if bound > limit {
+			continue
+		}
+		candidates = append(candidates, candidate{template, window, start, end, bound})
+	}
+
+	// The closest bounds first, so the best match is likely found early and
+	// rules out the rest
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].bound < candidates[j].bound
+	})
 
-		// Skip infinite distances
-		if math.IsInf(distance, 1) {
+	var matches []Match
+	best := math.Inf(1)
+
+	for _, c := range candidates {
+		limit := math.Min(c.template.Tolerance, s.limit(best))
+		if c.bound > limit {
 			continue
 		}
 
-		// Calculate score: 1.0 / (1.0 + distance)
-		score := 1.0 / (1.0 + distance)
+		a, b := c.window, c.template.motion
+		n, tm := len(a.vectors), len(b.vectors)
+		distance := w.distance(n, tm, bandRadius(n, tm), limit, func(i, j int) float64 {
+			return a.cost(i, b, j)
+		})
 
 		// Only include if distance is within tolerance
-		if distance <= template.Tolerance {
-			matches = append(matches, Match{
-				Template: template,
-				Score:    score,
-				Distance: distance,
-			})
+		if math.IsInf(distance, 1) || distance > c.template.Tolerance {
+			continue
 		}
+		best = math.Min(best, distance)
+
+		// Calculate score: 1.0 / (1.0 + distance)
+		matches = append(matches, Match{
+			Template: c.template.Template,
+			Score:    1.0 / (1.0 + distance),
+			Distance: distance,
+			Start:    c.start,
+			End:      c.end,
+		})
 	}
 
 	// Sort matches by score descending
@@ -155,6 +382,14 @@ func (m *DynamicMatcher) Match(path []PathPoint) []Match {
 		return matches[i].Score > matches[j].Score
 	})
 
+	// Measured before the best was found, some may not be close to it
+	if s.closest && len(matches) > 1 {
+		top := matches[0].Score
+		matches = append(matches[:1], slices.DeleteFunc(matches[1:], func(match Match) bool {
+			return (top-match.Score)/top >= s.margin
+		})...)
+	}
+
 	return matches
 }
 
@@ -1,6 +1,7 @@
 package gesture
 
 import (
+	"fmt"
 	"math"
 	"testing"
 )
@@ -415,3 +416,255 @@ func TestNormalizePath_PreservesTimestamp(t *testing.T) {
 		}
 	}
 }
+
+func TestDynamicMatcher_AddTemplate_ReplacesByID(t *testing.T) {
+	matcher := NewDynamicMatcher()
+
+	path := []PathPoint{{X: 0, Y: 0, Timestamp: 0}, {X: 1, Y: 1, Timestamp: 100}}
+	matcher.AddTemplate(&Template{ID: "g1", Name: "Old", Type: TypeDynamic, Path: path, Tolerance: 0.5})
+	matcher.AddTemplate(&Template{ID: "g1", Name: "New", Type: TypeDynamic, Path: path, Tolerance: 0.5})
+
+	if len(matcher.templates) != 1 {
+		t.Fatalf("expected 1 template after replace, got %d", len(matcher.templates))
+	}
+	if matcher.templates[0].Name != "New" {
+		t.Errorf("expected template to be replaced, got %q", matcher.templates[0].Name)
+	}
+}
+
+// fullDTW is the textbook DTW distance over the whole cost matrix, which the
+// banded and abandoning warper is checked and measured against.
+func fullDTW(n, m int, cost func(i, j int) float64) float64 {
+	if n == 0 || m == 0 {
+		return math.Inf(1)
+	}
+
+	matrix := make([][]float64, n+1)
+	for i := range matrix {
+		matrix[i] = make([]float64, m+1)
+		for j := range matrix[i] {
+			matrix[i][j] = math.Inf(1)
+		}
+	}
+	matrix[0][0] = 0
+
+	for i := 1; i <= n; i++ {
+		for j := 1; j <= m; j++ {
+			matrix[i][j] = cost(i-1, j-1) + min3(matrix[i-1][j], matrix[i][j-1], matrix[i-1][j-1])
+		}
+	}
+	return matrix[n][m] / float64(max(n, m))
+}
+
+// manyTemplates returns count dynamic templates, as a large gesture library
+// would hold: swipes in evenly spread directions and circles of several sizes
+// drawn from evenly spread starting points, each recorded at its own speed.
+func manyTemplates(count int) []*Template {
+	templates := make([]*Template, count)
+	for i := range templates {
+		angle := 2 * math.Pi * float64(i) / float64(count)
+		cos, sin := math.Cos(angle), math.Sin(angle)
+
+		t := &Template{
+			ID:        fmt.Sprintf("gesture-%d", i),
+			Type:      TypeDynamic,
+			Dynamic:   PathOptions{Normalization: NormalizeUniform},
+			Tolerance: 0.15,
+		}
+		if i%2 == 0 {
+			t.Path = newRecorder(0.5, 0.5).move(0.5+0.25*cos, 0.5+0.25*sin, 12+i%9).path
+		} else {
+			radius := 0.1 + 0.05*float64(i%3)
+			t.Path = newRecorder(0.5+radius*cos, 0.5+radius*sin).circle(0.5, 0.5, 20+i%11).path
+			t.Dynamic.Features = PathDisplacement
+		}
+		templates[i] = t
+	}
+	return templates
+}
+
+// motions returns the paths of manyTemplates, all prepared the same way so
+// that they can be compared with one another.
+func motions(count int) []*motion {
+	opts := PathOptions{Normalization: NormalizeUniform, Features: PathDisplacement}
+	var prepared []*motion
+	for _, t := range manyTemplates(count) {
+		m, _ := opts.motion(t.Path)
+		prepared = append(prepared, m)
+	}
+	return prepared
+}
+
+func TestWarper_Distance(t *testing.T) {
+	var w warper
+	prepared := motions(12)
+
+	for x, a := range prepared {
+		for y, b := range prepared {
+			n, m := len(a.vectors), len(b.vectors)
+			cost := func(i, j int) float64 { return a.cost(i, b, j) }
+
+			exact := fullDTW(n, m, cost)
+			if d := w.distance(n, m, max(n, m), math.Inf(1), cost); !floatEqual(d, exact) {
+				t.Fatalf("%d vs %d: expected an unbounded band to give %f, got %f", x, y, exact, d)
+			}
+
+			// The band only rules warping paths out
+			banded := w.distance(n, m, bandRadius(n, m), math.Inf(1), cost)
+			if banded < exact-1e-9 {
+				t.Errorf("%d vs %d: expected the banded distance %f to be at least %f", x, y, banded, exact)
+			}
+
+			// Abandoned only beyond the limit, and otherwise exact
+			if d := w.distance(n, m, bandRadius(n, m), banded, cost); !floatEqual(d, banded) {
+				t.Errorf("%d vs %d: expected %f within its own limit, got %f", x, y, banded, d)
+			}
+			if d := w.distance(n, m, bandRadius(n, m), banded*0.5, cost); !math.IsInf(d, 1) && !floatEqual(d, banded) {
+				t.Errorf("%d vs %d: expected %f beyond the limit to be abandoned or exact, got %f", x, y, banded, d)
+			}
+		}
+	}
+}
+
+func TestEnvelope_LowerBound(t *testing.T) {
+	var w warper
+	prepared := motions(12)
+
+	for x, query := range prepared {
+		for y, template := range prepared {
+			n, m := len(query.vectors), len(template.vectors)
+			radius := bandRadius(n, m)
+			e := newEnvelope(template, bandRadius(m, m))
+
+			distance := w.distance(n, m, radius, math.Inf(1), func(i, j int) float64 {
+				return query.cost(i, template, j)
+			})
+			bound := e.lowerBound(query, radius, math.Inf(1))
+			if bound > distance+1e-9 {
+				t.Errorf("%d vs %d: expected a bound of at most %f, got %f", x, y, distance, bound)
+			}
+			if x == y && bound != 0 {
+				t.Errorf("%d: expected no bound against itself, got %f", x, bound)
+			}
+			if bound > 0 && !math.IsInf(e.lowerBound(query, radius, bound*0.5), 1) {
+				t.Errorf("%d vs %d: expected the bound to be abandoned beyond the limit", x, y)
+			}
+		}
+	}
+
+	// A wider band than the envelope covers cannot be bounded by it
+	e := newEnvelope(prepared[0], 1)
+	if bound := e.lowerBound(prepared[1], 2, math.Inf(1)); bound != 0 {
+		t.Errorf("expected no bound for a band wider than the envelope, got %f", bound)
+	}
+}
+
+func TestDynamicMatcher_MatchClosest(t *testing.T) {
+	matcher := NewDynamicMatcher()
+	for _, template := range manyTemplates(60) {
+		matcher.AddTemplate(template)
+	}
+
+	paths := map[string][]PathPoint{
+		"swipe":              newRecorder(0.5, 0.5).rest(20).move(0.75, 0.5, 12).rest(28).path,
+		"diagonal swipe":     newRecorder(0.5, 0.5).rest(20).move(0.7, 0.35, 14).rest(26).path,
+		"circle":             newRecorder(0.65, 0.5).rest(15).circle(0.5, 0.5, 24).rest(21).path,
+		"swipe still going":  newRecorder(0.5, 0.5).rest(48).move(0.75, 0.5, 12).path,
+		"idle jitter":        newRecorder(0.5, 0.5).rest(60).path,
+		"swipe then circle":  newRecorder(0.5, 0.5).move(0.3, 0.5, 12).rest(5).circle(0.4, 0.5, 24).rest(19).path,
+		"circle of the hand": newRecorder(0.6, 0.4).rest(10).circle(0.5, 0.5, 30).rest(20).path,
+	}
+	ambiguities := []Ambiguity{
+		{},
+		{Margin: 0.05},
+		{MinScore: 0.9, Margin: 0.05},
+		{MinScore: 0.5, Margin: 0.3},
+		{MinScore: 0.99},
+	}
+
+	for name, path := range paths {
+		for _, ambiguity := range ambiguities {
+			want := ambiguity.Decide(Completed(matcher.Match(path), len(path)))
+			got := ambiguity.Decide(matcher.MatchClosest(path, ambiguity))
+
+			if got.Outcome != want.Outcome {
+				t.Errorf("%s with %+v: expected %s, got %s", name, ambiguity, want.Outcome, got.Outcome)
+				continue
+			}
+			if want.Outcome == OutcomeNone {
+				continue
+			}
+			if got.Best.Template.ID != want.Best.Template.ID || !floatEqual(got.Best.Score, want.Best.Score) || got.Best.End != want.Best.End {
+				t.Errorf("%s with %+v: expected best %+v, got %+v", name, ambiguity, *want.Best, *got.Best)
+			}
+			if want.Outcome == OutcomeAmbiguous && got.RunnerUp.Template.ID != want.RunnerUp.Template.ID {
+				t.Errorf("%s with %+v: expected runner-up %s, got %s", name, ambiguity, want.RunnerUp.Template.ID, got.RunnerUp.Template.ID)
+			}
+		}
+	}
+}
+
+func BenchmarkDTW(b *testing.B) {
+	prepared := motions(60)
+	var w warper
+
+	// Every template against the others, as Match measures a window against each
+	benchmark := func(b *testing.B, distance func(n, m int, cost func(i, j int) float64) float64) {
+		for b.Loop() {
+			for _, query := range prepared[:10] {
+				for _, template := range prepared {
+					distance(len(query.vectors), len(template.vectors), func(i, j int) float64 {
+						return query.cost(i, template, j)
+					})
+				}
+			}
+		}
+	}
+
+	b.Run("full matrix", func(b *testing.B) {
+		benchmark(b, fullDTW)
+	})
+	b.Run("two rows", func(b *testing.B) {
+		benchmark(b, func(n, m int, cost func(i, j int) float64) float64 {
+			return w.distance(n, m, max(n, m), math.Inf(1), cost)
+		})
+	})
+	b.Run("banded", func(b *testing.B) {
+		benchmark(b, func(n, m int, cost func(i, j int) float64) float64 {
+			return w.distance(n, m, bandRadius(n, m), math.Inf(1), cost)
+		})
+	})
+	b.Run("banded and abandoned", func(b *testing.B) {
+		benchmark(b, func(n, m int, cost func(i, j int) float64) float64 {
+			return w.distance(n, m, bandRadius(n, m), 0.15, cost)
+		})
+	})
+}
+
+func BenchmarkDynamicMatcher(b *testing.B) {
+	matcher := NewDynamicMatcher()
+	for _, template := range manyTemplates(60) {
+		matcher.AddTemplate(template)
+	}
+
+	ambiguity := Ambiguity{MinScore: 0.8, Margin: 0.05}
+	paths := map[string][]PathPoint{
+		// A full buffer holding a swipe between idle jitter
+		"swipe": newRecorder(0.5, 0.5).rest(20).move(0.75, 0.5, 12).rest(28).path,
+		// The hand held still, as it is most of the time
+		"idle": newRecorder(0.5, 0.5).rest(60).path,
+	}
+
+	for name, path := range paths {
+		b.Run(name+"/Match", func(b *testing.B) {
+			for b.Loop() {
+				ambiguity.Decide(Completed(matcher.Match(path), len(path)))
+			}
+		})
+		b.Run(name+"/MatchClosest", func(b *testing.B) {
+			for b.Loop() {
+				ambiguity.Decide(matcher.MatchClosest(path, ambiguity))
+			}
+		})
+	}
+}
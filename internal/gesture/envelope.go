@@ -0,0 +1,77 @@
+package gesture
+
+import "math"
+
+// envelope bounds a template's motion around each of its points: the lowest
+// and highest value every feature takes within radius points of it. Any point
+// of the template DTW may pair with a point of a performance lies within the
+// envelope around the point the band is centered on, which gives a lower bound
+// on the DTW distance that is much cheaper to compute (LB_Keogh).
+type envelope struct {
+	radius       int
+	lower, upper [][]float64
+}
+
+// newEnvelope builds the envelope of template within radius points of each point.
+func newEnvelope(template *motion, radius int) envelope {
+	e := envelope{
+		radius: radius,
+		lower:  make([][]float64, len(template.vectors)),
+		upper:  make([][]float64, len(template.vectors)),
+	}
+	for i := range template.vectors {
+		lo, hi := max(0, i-radius), min(len(template.vectors)-1, i+radius)
+		e.lower[i] = append([]float64(nil), template.vectors[lo]...)
+		e.upper[i] = append([]float64(nil), template.vectors[lo]...)
+		for _, v := range template.vectors[lo+1 : hi+1] {
+			for k, x := range v {
+				e.lower[i][k] = math.Min(e.lower[i][k], x)
+				e.upper[i][k] = math.Max(e.upper[i][k], x)
+			}
+		}
+	}
+	return e
+}
+
+// lowerBound returns a lower bound on the DTW distance between query and the
+// template e was built from, warped within radius of the diagonal as
+// warper.distance does: every warping path pairs each query point with some
+// template point within the band, at a cost no less than how far, group by
+// group, the query point lies outside the envelope there.
+// The computation is abandoned as soon as the bound exceeds limit. Returns 0
+// if the envelope is too narrow to bound a band of radius.
+func (e envelope) lowerBound(query *motion, radius int, limit float64) float64 {
+	n, m := len(query.vectors), len(e.lower)
+	if n <= 1 || m == 0 || radius > e.radius {
+		return 0
+	}
+
+	norm := float64(max(n, m))
+	var total float64
+	for i, q := range query.vectors {
+		// Every template point within the band lies within radius of the nearest point to its center
+		center := int(math.Round(float64(i) * float64(m-1) / float64(n-1)))
+		lower, upper := e.lower[center], e.upper[center]
+
+		k := 0
+		for _, size := range query.groups {
+			var sum float64
+			for end := k + size; k < end; k++ {
+				var d float64
+				switch {
+				case q[k] < lower[k]:
+					d = lower[k] - q[k]
+				case q[k] > upper[k]:
+					d = q[k] - upper[k]
+				}
+				sum += d * d
+			}
+			total += math.Sqrt(sum)
+		}
+
+		if total > limit*norm {
+			return math.Inf(1)
+		}
+	}
+	return total / norm
+}
@@ -0,0 +1,144 @@
+package gesture
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// outlierFactor is how many times further from its nearest neighbour than the
+// median sample is from its own a training sample must be to be rejected.
+const outlierFactor = 3
+
+// minOutlierSamples is the fewest samples outliers can be told apart in:
+// with two samples, either one could be the odd one out.
+const minOutlierSamples = 3
+
+// nearestExemplars compares input with every exemplar and applies a k-NN vote
+// over the k = opts.Neighbors nearest ones: a majority of them must lie within
+// tolerance for the input to match.
+// Returns the mean distance to the voting majority and whether the vote passed.
+func (o StaticOptions) nearestExemplars(input []detector.Point3D, exemplars [][]detector.Point3D, tolerance float64) (float64, bool) {
+	if len(exemplars) == 0 {
+		return 0, false
+	}
+
+	distances := make([]float64, len(exemplars))
+	for i, exemplar := range exemplars {
+		distances[i] = o.distance(input, exemplar)
+	}
+	sort.Float64s(distances)
+
+	k := o.Neighbors
+	if k < 1 {
+		k = 1
+	}
+	if k > len(distances) {
+		k = len(distances)
+	}
+	majority := distances[:k/2+1]
+
+	var sum float64
+	for _, d := range majority {
+		sum += d
+	}
+	return sum / float64(len(majority)), majority[len(majority)-1] <= tolerance
+}
+
+// StaticExemplars keeps every static sample as an exemplar for k-NN matching,
+// prepared the same way TrainStaticWith prepares samples for averaging.
+// Exemplars are returned in the order of samples.
+func (t *Trainer) StaticExemplars(samples []json.RawMessage, opts StaticOptions) ([][]detector.Point3D, error) {
+	allLandmarks, _, err := parseStaticSamples(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.RotationInvariant {
+		for i, landmarks := range allLandmarks {
+			allLandmarks[i] = detector.CanonicalOrientation(landmarks)
+		}
+	}
+	return allLandmarks, nil
+}
+
+// StaticOutliers returns the positions of the samples that should not be used
+// as exemplars: those much further from their nearest neighbour than the
+// other samples are from theirs.
+func (t *Trainer) StaticOutliers(samples []json.RawMessage, opts StaticOptions) ([]int, error) {
+	exemplars, err := t.StaticExemplars(samples, opts)
+	if err != nil {
+		return nil, err
+	}
+	return findOutliers(exemplars, opts), nil
+}
+
+// DiagnoseExemplars measures how every static sample would match the exemplars
+// trained from the others, voting over opts.Neighbors of them.
+// Samples rejected as outliers are measured against all remaining exemplars,
+// and a lone sample has nothing to deviate from.
+func (t *Trainer) DiagnoseExemplars(samples []json.RawMessage, opts StaticOptions) (*Diagnostics, error) {
+	allLandmarks, err := t.StaticExemplars(samples, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	outliers := findOutliers(allLandmarks, opts)
+	rejected := make(map[int]bool, len(outliers))
+	for _, i := range outliers {
+		rejected[i] = true
+	}
+
+	deviations := make([]float64, len(allLandmarks))
+	for i, landmarks := range allLandmarks {
+		others := make([][]detector.Point3D, 0, len(allLandmarks)-1)
+		for j, other := range allLandmarks {
+			if j != i && !rejected[j] {
+				others = append(others, other)
+			}
+		}
+		deviations[i], _ = opts.nearestExemplars(landmarks, others, 0)
+	}
+
+	return newDiagnostics(deviations), nil
+}
+
+// findOutliers returns the positions of samples whose nearest neighbour is
+// more than outlierFactor times further away than is typical for the samples.
+// Comparing with the nearest neighbour rather than the average keeps samples
+// of a pose performed in several distinct ways, as long as each way was
+// recorded more than once.
+func findOutliers(allLandmarks [][]detector.Point3D, opts StaticOptions) []int {
+	if len(allLandmarks) < minOutlierSamples {
+		return nil
+	}
+
+	nearest := make([]float64, len(allLandmarks))
+	for i, a := range allLandmarks {
+		nearest[i] = -1
+		for j, b := range allLandmarks {
+			if i == j {
+				continue
+			}
+			if d := opts.distance(a, b); nearest[i] < 0 || d < nearest[i] {
+				nearest[i] = d
+			}
+		}
+	}
+
+	sorted := append([]float64(nil), nearest...)
+	sort.Float64s(sorted)
+	median := sorted[len(sorted)/2]
+	if median == 0 {
+		return nil
+	}
+
+	var outliers []int
+	for i, d := range nearest {
+		if d > outlierFactor*median {
+			outliers = append(outliers, i)
+		}
+	}
+	return outliers
+}
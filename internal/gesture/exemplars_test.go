@@ -0,0 +1,158 @@
+package gesture
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// staticSamples encodes hands as recorded static samples.
+func staticSamples(t *testing.T, hands ...detector.HandLandmarks) []json.RawMessage {
+	t.Helper()
+
+	samples := make([]json.RawMessage, len(hands))
+	for i, hand := range hands {
+		raw, err := json.Marshal(StaticSample{Type: "static", Landmarks: hand.Points[:]})
+		if err != nil {
+			t.Fatalf("failed to marshal sample: %v", err)
+		}
+		samples[i] = raw
+	}
+	return samples
+}
+
+func TestStaticMatcher_Exemplars(t *testing.T) {
+	palm := detector.OpenPalmLandmarks()
+	thumbsUp := detector.ThumbsUpLandmarks()
+	// A "hello" gesture some people make with an open palm and others with a thumbs up
+	exemplars := [][]detector.Point3D{palm.Normalize().Points[:], thumbsUp.Normalize().Points[:]}
+
+	averaged, err := NewTrainer().TrainStatic(staticSamples(t, palm, thumbsUp))
+	if err != nil {
+		t.Fatalf("TrainStatic() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		neighbors int
+		want      bool
+	}{
+		{"the averaged pose matches neither way", 0, false},
+		{"the nearest exemplar matches either way", 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewStaticMatcher()
+			matcher.AddTemplate(&Template{
+				ID:        "hello",
+				Type:      TypeStatic,
+				Landmarks: averaged,
+				Exemplars: exemplars,
+				Static:    StaticOptions{Neighbors: tt.neighbors},
+				Tolerance: 0.5,
+			})
+
+			for _, hand := range []detector.HandLandmarks{palm, thumbsUp} {
+				matches := matcher.Match(&hand)
+				if got := len(matches) == 1; got != tt.want {
+					t.Fatalf("expected match %v, got %+v", tt.want, matches)
+				}
+				if tt.want && matches[0].Score < 1-1e-9 {
+					t.Errorf("expected a perfect score against an identical exemplar, got %f", matches[0].Score)
+				}
+			}
+		})
+	}
+}
+
+func TestStaticMatcher_ExemplarVote(t *testing.T) {
+	palm := detector.OpenPalmLandmarks()
+	thumbsUp := detector.ThumbsUpLandmarks()
+	tilted := tiltHand(palm, 0.01)
+
+	// Two open palms outvote a single stray thumbs up
+	exemplars := [][]detector.Point3D{
+		palm.Normalize().Points[:],
+		tilted.Normalize().Points[:],
+		thumbsUp.Normalize().Points[:],
+	}
+
+	tests := []struct {
+		name      string
+		neighbors int
+		input     detector.HandLandmarks
+		want      bool
+	}{
+		{"nearest neighbour accepts the stray pose", 1, thumbsUp, true},
+		{"3-NN vote rejects the stray pose", 3, thumbsUp, false},
+		{"3-NN vote accepts the majority pose", 3, palm, true},
+		{"k larger than the exemplars votes over all of them", 10, palm, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewStaticMatcher()
+			matcher.AddTemplate(&Template{
+				ID:        "palm",
+				Type:      TypeStatic,
+				Landmarks: exemplars[0],
+				Exemplars: exemplars,
+				Static:    StaticOptions{Neighbors: tt.neighbors},
+				Tolerance: 0.5,
+			})
+
+			matches := matcher.Match(&tt.input)
+			if got := len(matches) == 1; got != tt.want {
+				t.Errorf("expected match %v, got %+v", tt.want, matches)
+			}
+		})
+	}
+}
+
+func TestTrainer_StaticOutliers(t *testing.T) {
+	trainer := NewTrainer()
+	palm := detector.OpenPalmLandmarks()
+	thumbsUp := detector.ThumbsUpLandmarks()
+
+	// One thumbs up among slightly varied open palms is rejected
+	samples := staticSamples(t, palm, tiltHand(palm, 0.01), tiltHand(palm, -0.01), thumbsUp, tiltHand(palm, 0.02))
+	outliers, err := trainer.StaticOutliers(samples, StaticOptions{})
+	if err != nil {
+		t.Fatalf("StaticOutliers() error = %v", err)
+	}
+	if !reflect.DeepEqual(outliers, []int{3}) {
+		t.Errorf("expected sample 3 to be rejected, got %v", outliers)
+	}
+
+	// A pose recorded two ways, each more than once, keeps every sample
+	samples = staticSamples(t, palm, tiltHand(palm, 0.01), thumbsUp, tiltHand(thumbsUp, 0.01))
+	if outliers, _ := trainer.StaticOutliers(samples, StaticOptions{}); len(outliers) != 0 {
+		t.Errorf("expected no outliers, got %v", outliers)
+	}
+
+	exemplars, err := trainer.StaticExemplars(samples, StaticOptions{})
+	if err != nil {
+		t.Fatalf("StaticExemplars() error = %v", err)
+	}
+	if len(exemplars) != 4 || !floatEqual(exemplars[0][detector.Wrist].X, 0) {
+		t.Errorf("expected 4 normalized exemplars, got %d", len(exemplars))
+	}
+
+	// Every sample matches the exemplars of its own kind
+	diag, err := trainer.DiagnoseExemplars(samples, StaticOptions{Neighbors: 1})
+	if err != nil {
+		t.Fatalf("DiagnoseExemplars() error = %v", err)
+	}
+	if diag.SampleCount != 4 || diag.MaxDeviation > 0.5 {
+		t.Errorf("expected every sample close to another, got %+v", diag)
+	}
+
+	// A lone sample has nothing to deviate from
+	diag, err = trainer.DiagnoseExemplars(samples[:1], StaticOptions{Neighbors: 1})
+	if err != nil || diag.MaxDeviation != 0 {
+		t.Errorf("expected no deviation for a single sample, got %+v, %v", diag, err)
+	}
+}
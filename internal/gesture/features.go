@@ -0,0 +1,133 @@
+package gesture
+
+import (
+	"math"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// Fingers in the order used by HandFeatures.
+const (
+	FingerThumb = iota
+	FingerIndex
+	FingerMiddle
+	FingerRing
+	FingerPinky
+	NumFingers
+)
+
+// fingerJoints lists the landmarks of each finger from its base to its tip.
+// Every finger starts at the wrist so that the bend at its first knuckle is measured too.
+var fingerJoints = [NumFingers][]int{
+	FingerThumb:  {detector.Wrist, detector.ThumbCMC, detector.ThumbMCP, detector.ThumbIP, detector.ThumbTip},
+	FingerIndex:  {detector.Wrist, detector.IndexMCP, detector.IndexPIP, detector.IndexDIP, detector.IndexTip},
+	FingerMiddle: {detector.Wrist, detector.MiddleMCP, detector.MiddlePIP, detector.MiddleDIP, detector.MiddleTip},
+	FingerRing:   {detector.Wrist, detector.RingMCP, detector.RingPIP, detector.RingDIP, detector.RingTip},
+	FingerPinky:  {detector.Wrist, detector.PinkyMCP, detector.PinkyPIP, detector.PinkyDIP, detector.PinkyTip},
+}
+
+// HandFeatures describes a hand pose by the shape of its fingers rather than
+// by the position of every landmark, which makes it less sensitive to the
+// proportions of a particular hand.
+type HandFeatures struct {
+	Curl         [NumFingers]float64     // Sum of the bend angles along each finger, in radians (0 is straight)
+	Extended     [NumFingers]float64     // 1 if the finger is extended, 0 if folded
+	TipDistances [NumFingers - 1]float64 // Distance between neighbouring fingertips, in hand sizes
+	PalmNormal   detector.Point3D        // Unit vector facing out of the palm
+}
+
+// FeatureWeights sets how much each kind of feature contributes to the
+// distance between two HandFeatures.
+type FeatureWeights struct {
+	Curl        float64 // Per radian of curl difference, per finger
+	Extension   float64 // Per finger whose extension differs
+	TipDistance float64 // Per hand size of fingertip distance difference
+	Palm        float64 // Per unit of 1 - cos(angle between palm normals)
+}
+
+// DefaultFeatureWeights balances the features so that a finger folding,
+// which changes its curl by about two radians and flips its extension,
+// outweighs the spread of the fingers or a tilt of the palm.
+var DefaultFeatureWeights = FeatureWeights{
+	Curl:        0.5,
+	Extension:   1,
+	TipDistance: 1,
+	Palm:        0.5,
+}
+
+// ExtractFeatures computes the features of a hand from its normalized landmarks.
+// Returns false unless points holds all NumLandmarks landmarks.
+func ExtractFeatures(points []detector.Point3D) (*HandFeatures, bool) {
+	if len(points) != detector.NumLandmarks {
+		return nil, false
+	}
+
+	f := &HandFeatures{}
+	wrist := points[detector.Wrist]
+
+	for finger, joints := range fingerJoints {
+		var bends [3]float64
+		for j := 1; j < len(joints)-1; j++ {
+			bends[j-1] = bendAngle(points[joints[j-1]], points[joints[j]], points[joints[j+1]])
+			f.Curl[finger] += bends[j-1]
+		}
+
+		tip := points[joints[len(joints)-1]]
+		middle := points[joints[2]]
+		if finger == FingerThumb {
+			// The thumb folds across the palm, so it is extended when its tip
+			// is further from the pinky knuckle than its own knuckle is
+			reference := points[detector.PinkyMCP]
+			if tip.Sub(reference).Length() > middle.Sub(reference).Length() {
+				f.Extended[finger] = 1
+			}
+			continue
+		}
+
+		// Other fingers are extended when they point away from the wrist and
+		// their two outer joints are bent by less than a right angle together
+		if tip.Sub(wrist).Length() > middle.Sub(wrist).Length() && bends[1]+bends[2] < math.Pi/2 {
+			f.Extended[finger] = 1
+		}
+	}
+
+	for finger := 0; finger < NumFingers-1; finger++ {
+		a := points[fingerJoints[finger][len(fingerJoints[finger])-1]]
+		b := points[fingerJoints[finger+1][len(fingerJoints[finger+1])-1]]
+		f.TipDistances[finger] = a.Sub(b).Length()
+	}
+
+	normal := points[detector.IndexMCP].Sub(wrist).Cross(points[detector.PinkyMCP].Sub(wrist))
+	f.PalmNormal, _ = normal.Unit()
+
+	return f, true
+}
+
+// bendAngle returns how far the segment b→c turns away from the direction of a→b.
+// Returns 0 if either segment has no length.
+func bendAngle(a, b, c detector.Point3D) float64 {
+	u, ok := b.Sub(a).Unit()
+	if !ok {
+		return 0
+	}
+	v, ok := c.Sub(b).Unit()
+	if !ok {
+		return 0
+	}
+	return math.Acos(math.Max(-1, math.Min(1, u.Dot(v))))
+}
+
+// Distance returns the weighted difference between two sets of features.
+func (f *HandFeatures) Distance(other *HandFeatures, w FeatureWeights) float64 {
+	var curl, extension, tips float64
+	for i := 0; i < NumFingers; i++ {
+		curl += math.Abs(f.Curl[i] - other.Curl[i])
+		extension += math.Abs(f.Extended[i] - other.Extended[i])
+	}
+	for i := range f.TipDistances {
+		tips += math.Abs(f.TipDistances[i] - other.TipDistances[i])
+	}
+	palm := 1 - f.PalmNormal.Dot(other.PalmNormal)
+
+	return w.Curl*curl + w.Extension*extension + w.TipDistance*tips + w.Palm*palm
+}
@@ -0,0 +1,152 @@
+package gesture
+
+import (
+	"math"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// withFingers returns an open palm with every finger not listed folded into the palm
+// the way the curled fingers of ThumbsUpLandmarks are.
+func withFingers(h detector.HandLandmarks, extended ...int) detector.HandLandmarks {
+	fist := detector.ThumbsUpLandmarks()
+	keep := make(map[int]bool)
+	for _, f := range extended {
+		keep[f] = true
+	}
+	for finger, joints := range fingerJoints {
+		if keep[finger] {
+			continue
+		}
+		if finger == FingerThumb {
+			// Tuck the thumb across the palm towards the middle knuckle
+			mcp := h.Points[detector.ThumbMCP]
+			target := h.Points[detector.MiddleMCP]
+			h.Points[detector.ThumbIP] = mcp.Add(target.Sub(mcp).Scale(0.5))
+			h.Points[detector.ThumbTip] = target
+			continue
+		}
+		// Reuse the curled index finger's shape relative to its knuckle
+		base := h.Points[joints[1]]
+		curled := fingerJoints[FingerIndex]
+		for j := 2; j < len(joints); j++ {
+			h.Points[joints[j]] = base.Add(fist.Points[curled[j]].Sub(fist.Points[curled[1]]))
+		}
+	}
+	return h
+}
+
+// stretchFingers lengthens every finger beyond its knuckle by factor, as a hand
+// with longer fingers would make the same pose.
+func stretchFingers(h detector.HandLandmarks, factor float64) detector.HandLandmarks {
+	for _, joints := range fingerJoints {
+		base := h.Points[joints[1]]
+		for _, j := range joints[2:] {
+			h.Points[j] = base.Add(h.Points[j].Sub(base).Scale(factor))
+		}
+	}
+	return h
+}
+
+func TestExtractFeatures(t *testing.T) {
+	palm := detector.OpenPalmLandmarks()
+	thumbsUp := detector.ThumbsUpLandmarks()
+
+	open, ok := ExtractFeatures(palm.Normalize().Points[:])
+	if !ok {
+		t.Fatal("expected features for a full hand")
+	}
+	fist, _ := ExtractFeatures(thumbsUp.Normalize().Points[:])
+
+	for finger := 0; finger < NumFingers; finger++ {
+		if open.Extended[finger] != 1 {
+			t.Errorf("finger %d: expected extended in an open palm", finger)
+		}
+		want := 0.0
+		if finger == FingerThumb {
+			want = 1
+		}
+		if fist.Extended[finger] != want {
+			t.Errorf("finger %d: expected extension %v in thumbs up, got %v", finger, want, fist.Extended[finger])
+		}
+		if finger != FingerThumb && fist.Curl[finger] < open.Curl[finger]+math.Pi/2 {
+			t.Errorf("finger %d: expected a folded finger to curl much more, got %f and %f", finger, fist.Curl[finger], open.Curl[finger])
+		}
+	}
+	if math.Abs(open.PalmNormal.Length()-1) > 1e-9 {
+		t.Errorf("expected a unit palm normal, got %+v", open.PalmNormal)
+	}
+
+	if _, ok := ExtractFeatures(palm.Points[:5]); ok {
+		t.Error("expected no features for a partial hand")
+	}
+}
+
+func TestExtractFeatures_IgnoresPlacementAndTilt(t *testing.T) {
+	peace := withFingers(detector.OpenPalmLandmarks(), FingerIndex, FingerMiddle)
+	moved := tiltHand(shiftHand(peace, peace.Handedness, 0.1, -0.05), 0.4)
+
+	want, _ := ExtractFeatures(peace.Normalize().Points[:])
+	got, _ := ExtractFeatures(moved.Normalize().Points[:])
+	if d := want.Distance(got, DefaultFeatureWeights); d > 1e-9 {
+		t.Errorf("expected the same features for a moved and tilted hand, distance %f", d)
+	}
+}
+
+func TestStaticMatcher_FeatureMode(t *testing.T) {
+	peace := withFingers(detector.OpenPalmLandmarks(), FingerIndex, FingerMiddle)
+	two := withFingers(detector.OpenPalmLandmarks(), FingerThumb, FingerIndex)
+	// The same peace sign made by a hand with longer fingers
+	longPeace := stretchFingers(peace, 1.3)
+
+	tests := []struct {
+		name string
+		mode MatchMode
+		want []string
+	}{
+		{"finger features match the pose despite the hand's proportions", MatchFeatures, []string{"peace"}},
+		{"landmark positions are thrown off by the hand's proportions", MatchLandmarks, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewStaticMatcher()
+			for id, h := range map[string]detector.HandLandmarks{"peace": peace, "two": two} {
+				matcher.AddTemplate(&Template{
+					ID:        id,
+					Type:      TypeStatic,
+					Landmarks: h.Normalize().Points[:],
+					Static:    StaticOptions{Mode: tt.mode},
+					Tolerance: 3,
+				})
+			}
+
+			matches := matcher.Match(&longPeace)
+			var got []string
+			for _, m := range matches {
+				got = append(got, m.Template.ID)
+			}
+			if len(got) != len(tt.want) || (len(got) > 0 && got[0] != tt.want[0]) {
+				t.Errorf("expected matches %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestStaticOptions_FeatureWeights(t *testing.T) {
+	peace := withFingers(detector.OpenPalmLandmarks(), FingerIndex, FingerMiddle)
+	two := withFingers(detector.OpenPalmLandmarks(), FingerThumb, FingerIndex)
+	a, b := peace.Normalize().Points[:], two.Normalize().Points[:]
+
+	// Only counting extended fingers, peace and two differ in the thumb and middle finger
+	opts := StaticOptions{Mode: MatchFeatures, Weights: &FeatureWeights{Extension: 1}}
+	if d := opts.distance(a, b); !floatEqual(d, 2) {
+		t.Errorf("expected distance 2, got %f", d)
+	}
+
+	// Partial landmark sets fall back to landmark distances
+	if d, want := opts.distance(a[:3], b[:3]), euclideanDistance(a[:3], b[:3]); !floatEqual(d, want) {
+		t.Errorf("expected landmark distance %f, got %f", want, d)
+	}
+}
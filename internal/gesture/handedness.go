@@ -0,0 +1,71 @@
+package gesture
+
+import "github.com/ayusman/kuchipudi/internal/detector"
+
+// Hand restricts which hands may make a one-hand gesture.
+type Hand string
+
+const (
+	// HandEither lets either hand make the gesture. A pose recorded with one
+	// hand is matched against the other hand by mirroring it.
+	HandEither Hand = "either"
+	// HandLeft only matches hands the detector labels as left.
+	HandLeft Hand = "left"
+	// HandRight only matches hands the detector labels as right.
+	HandRight Hand = "right"
+)
+
+// Allows reports whether a hand with the given detector label may make the gesture.
+// Hands without a label are allowed, since their handedness is unknown.
+func (h Hand) Allows(handedness string) bool {
+	switch h {
+	case HandLeft:
+		return handedness != HandednessRight
+	case HandRight:
+		return handedness != HandednessLeft
+	default:
+		return true
+	}
+}
+
+// needsMirror reports whether a hand labelled handedness must be mirrored to
+// compare it with a template recorded with the recorded hand.
+// Nothing is mirrored when either label is unknown.
+func needsMirror(recorded, handedness string) bool {
+	return recorded != "" && handedness != "" && recorded != handedness
+}
+
+// mirrorX reflects normalized landmarks across the vertical axis through the wrist,
+// turning a left hand pose into the matching right hand pose and back.
+func mirrorX(points []detector.Point3D) []detector.Point3D {
+	mirrored := make([]detector.Point3D, len(points))
+	for i, p := range points {
+		mirrored[i] = detector.Point3D{X: -p.X, Y: p.Y, Z: p.Z}
+	}
+	return mirrored
+}
+
+// majorityHandedness returns the handedness label most of the given labels agree on.
+// Empty labels are ignored and ties go to the label seen first.
+// Returns "" if no label is known.
+func majorityHandedness(labels []string) string {
+	counts := make(map[string]int)
+	var order []string
+	for _, l := range labels {
+		if l == "" {
+			continue
+		}
+		if counts[l] == 0 {
+			order = append(order, l)
+		}
+		counts[l]++
+	}
+
+	var best string
+	for _, l := range order {
+		if counts[l] > counts[best] {
+			best = l
+		}
+	}
+	return best
+}
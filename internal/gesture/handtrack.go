@@ -0,0 +1,172 @@
+package gesture
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// TrackTimeout is how long a hand may go undetected before its track ends.
+// It bridges frames the detector misses a hand on, so that the path and state
+// of the hand survive them, while a hand that left the frame is soon forgotten.
+const TrackTimeout = 500 * time.Millisecond
+
+// MaxTrackDistance is how far, in normalized image coordinates, the wrist of a
+// hand may move between the frames it is detected on and still be taken for
+// the same hand.
+const MaxTrackDistance = 0.25
+
+// handednessPenalty is added to the distance between a track and a hand
+// labelled the other way. It tells apart hands close together by their labels,
+// without letting a mislabelled frame pull a hand away from its track.
+const handednessPenalty = 0.1
+
+// handednessHistory is how many of its latest labels the handedness of a track
+// is decided from.
+const handednessHistory = 15
+
+// HandTrack follows one hand across frames, keeping what gesture matching
+// needs to remember about it from one frame to the next.
+type HandTrack struct {
+	ID         int                    // Unique among the tracks of a tracker, never reused
+	Hand       detector.HandLandmarks // Latest detection of the hand
+	Handedness string                 // Label most of its latest detections agree on, "" if none
+	Path       []PathPoint            // Buffered path of the hand for dynamic gestures
+	FirstSeen  time.Time
+	LastSeen   time.Time
+	labels     []string
+}
+
+// AddPoint appends p to the path of the track, dropping the oldest point once
+// the path holds size points.
+func (t *HandTrack) AddPoint(p PathPoint, size int) {
+	if len(t.Path) >= size {
+		// Shift buffer left, removing the oldest points
+		copy(t.Path, t.Path[len(t.Path)-size+1:])
+		t.Path = t.Path[:size-1]
+	}
+	t.Path = append(t.Path, p)
+}
+
+// DropPath drops the path of the track up to end, keeping what followed it.
+func (t *HandTrack) DropPath(end int) {
+	t.Path = append(t.Path[:0], t.Path[min(end, len(t.Path)):]...)
+}
+
+// observe records a detection of the hand at now.
+func (t *HandTrack) observe(now time.Time, hand detector.HandLandmarks) {
+	t.Hand = hand
+	t.LastSeen = now
+	t.labels = append(t.labels, hand.Handedness)
+	if len(t.labels) > handednessHistory {
+		t.labels = t.labels[len(t.labels)-handednessHistory:]
+	}
+	t.Handedness = majorityHandedness(t.labels)
+}
+
+// HandTracker gives the hands detected on each frame persistent identities.
+// A hand is taken for the tracked hand whose wrist was last seen closest to
+// it, preferring one of the same handedness; a hand close to none starts a new
+// track, and a track whose hand is not seen for TrackTimeout ends.
+// A HandTracker must not be used by several goroutines at once.
+type HandTracker struct {
+	tracks []*HandTrack
+	nextID int
+}
+
+// NewHandTracker creates a new HandTracker instance.
+func NewHandTracker() *HandTracker {
+	return &HandTracker{
+		tracks: make([]*HandTrack, 0),
+		nextID: 1,
+	}
+}
+
+// Update assigns the hands detected on a frame at now to tracks, starting
+// tracks for hands that entered the frame and ending those of hands that left
+// it. It must be called for every processed frame, including frames without
+// hands, so that tracks end on time.
+// Returns the track of each hand, in the order of hands, and the tracks that
+// ended, so that whatever was kept for their hands can be released.
+func (t *HandTracker) Update(now time.Time, hands []detector.HandLandmarks) (tracks, ended []*HandTrack) {
+	// End the tracks of hands that left
+	live := t.tracks[:0]
+	for _, track := range t.tracks {
+		if now.Sub(track.LastSeen) <= TrackTimeout {
+			live = append(live, track)
+		} else {
+			ended = append(ended, track)
+		}
+	}
+	clear(t.tracks[len(live):])
+	t.tracks = live
+
+	// Every plausible pairing of a track and a hand, closest first
+	type pairing struct {
+		track, hand int
+		cost        float64
+	}
+	var pairings []pairing
+	for k, track := range t.tracks {
+		for i := range hands {
+			distance := wristDistance(&track.Hand, &hands[i])
+			if distance > MaxTrackDistance {
+				continue
+			}
+			cost := distance
+			if label := hands[i].Handedness; track.Handedness != "" && label != "" && label != track.Handedness {
+				cost += handednessPenalty
+			}
+			pairings = append(pairings, pairing{k, i, cost})
+		}
+	}
+	sort.SliceStable(pairings, func(a, b int) bool {
+		return pairings[a].cost < pairings[b].cost
+	})
+
+	assigned := make([]*HandTrack, len(hands))
+	taken := make([]bool, len(t.tracks))
+	for _, p := range pairings {
+		if assigned[p.hand] != nil || taken[p.track] {
+			continue
+		}
+		assigned[p.hand] = t.tracks[p.track]
+		taken[p.track] = true
+	}
+
+	// Hands that entered the frame start their own tracks
+	for i := range hands {
+		if assigned[i] == nil {
+			assigned[i] = &HandTrack{ID: t.nextID, FirstSeen: now}
+			t.nextID++
+			t.tracks = append(t.tracks, assigned[i])
+		}
+		assigned[i].observe(now, hands[i])
+	}
+	return assigned, ended
+}
+
+// Tracks returns the current tracks, oldest first, including those of hands
+// missed on the latest frames that have not yet timed out.
+func (t *HandTracker) Tracks() []*HandTrack {
+	return t.tracks
+}
+
+// Reset ends every track.
+// Returns the tracks that ended.
+func (t *HandTracker) Reset() []*HandTrack {
+	ended := slices.Clone(t.tracks)
+	clear(t.tracks)
+	t.tracks = t.tracks[:0]
+	return ended
+}
+
+// wristDistance returns the distance between the wrists of two hands in the image.
+func wristDistance(a, b *detector.HandLandmarks) float64 {
+	p, q := a.Points[detector.Wrist], b.Points[detector.Wrist]
+	dx, dy := p.X-q.X, p.Y-q.Y
+	return math.Sqrt(dx*dx + dy*dy)
+}
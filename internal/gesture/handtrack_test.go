@@ -0,0 +1,164 @@
+package gesture
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// handAt returns an open palm labelled handedness with its wrist at (x, y).
+func handAt(x, y float64, handedness string) detector.HandLandmarks {
+	h := detector.OpenPalmLandmarks()
+	wrist := h.Points[detector.Wrist]
+	return shiftHand(h, handedness, x-wrist.X, y-wrist.Y)
+}
+
+// trackIDs returns the IDs of tracks.
+func trackIDs(tracks []*HandTrack) []int {
+	ids := make([]int, len(tracks))
+	for i, track := range tracks {
+		ids[i] = track.ID
+	}
+	return ids
+}
+
+func TestHandTracker_KeepsHandsApart(t *testing.T) {
+	tracker := NewHandTracker()
+	now := time.Now()
+
+	// Two hands moving toward each other, detected in either order
+	var a, b *HandTrack
+	for i := range 10 {
+		dx := 0.02 * float64(i)
+		hands := []detector.HandLandmarks{handAt(0.2+dx, 0.6, HandednessRight), handAt(0.8-dx, 0.6, HandednessLeft)}
+		if i%2 == 1 {
+			hands[0], hands[1] = hands[1], hands[0]
+		}
+
+		tracks, _ := tracker.Update(now, hands)
+		if i%2 == 1 {
+			tracks[0], tracks[1] = tracks[1], tracks[0]
+		}
+		if i == 0 {
+			a, b = tracks[0], tracks[1]
+		}
+		if tracks[0] != a || tracks[1] != b {
+			t.Fatalf("frame %d: expected tracks %d and %d, got %v", i, a.ID, b.ID, trackIDs(tracks))
+		}
+		a.AddPoint(PathPoint{X: 0.2 + dx}, 60)
+		now = now.Add(frameInterval)
+	}
+
+	if a.ID == b.ID {
+		t.Errorf("expected each hand to have its own track, got %d for both", a.ID)
+	}
+	if len(a.Path) != 10 || len(b.Path) != 0 {
+		t.Errorf("expected a path for the hand it was added to only, got %d and %d points", len(a.Path), len(b.Path))
+	}
+	if a.Handedness != HandednessRight || b.Handedness != HandednessLeft {
+		t.Errorf("expected the tracks labelled as their hands, got %q and %q", a.Handedness, b.Handedness)
+	}
+}
+
+func TestHandTracker_HandednessBreaksTies(t *testing.T) {
+	tracker := NewHandTracker()
+	now := time.Now()
+
+	tracks, _ := tracker.Update(now, []detector.HandLandmarks{handAt(0.48, 0.5, HandednessRight), handAt(0.52, 0.5, HandednessLeft)})
+	right, left := tracks[0], tracks[1]
+
+	// The hands cross, each now closer to where the other was
+	now = now.Add(frameInterval)
+	tracks, _ = tracker.Update(now, []detector.HandLandmarks{handAt(0.53, 0.5, HandednessRight), handAt(0.47, 0.5, HandednessLeft)})
+	if tracks[0] != right || tracks[1] != left {
+		t.Errorf("expected hands close together to follow their handedness, got %v", trackIDs(tracks))
+	}
+
+	// A single mislabelled frame neither moves the hand to another track nor relabels its own
+	now = now.Add(frameInterval)
+	tracks, _ = tracker.Update(now, []detector.HandLandmarks{handAt(0.2, 0.5, HandednessLeft)})
+	now = now.Add(frameInterval)
+	tracks, _ = tracker.Update(now, []detector.HandLandmarks{handAt(0.21, 0.5, HandednessLeft)})
+	if tracks[0] == right || tracks[0] == left {
+		t.Fatalf("expected a hand far from both to start a track")
+	}
+	wrong := handAt(0.22, 0.5, HandednessRight)
+	now = now.Add(frameInterval)
+	if tracks, _ := tracker.Update(now, []detector.HandLandmarks{wrong}); tracks[0].Handedness != HandednessLeft {
+		t.Errorf("expected the track to stay left, got %q", tracks[0].Handedness)
+	}
+}
+
+func TestHandTracker_EnterAndLeave(t *testing.T) {
+	tracker := NewHandTracker()
+	now := time.Now()
+
+	tracks, _ := tracker.Update(now, []detector.HandLandmarks{handAt(0.3, 0.5, HandednessRight)})
+	first := tracks[0]
+	first.AddPoint(PathPoint{X: 0.3}, 60)
+
+	// A second hand enters
+	now = now.Add(frameInterval)
+	tracks, _ = tracker.Update(now, []detector.HandLandmarks{handAt(0.7, 0.5, HandednessLeft), handAt(0.31, 0.5, HandednessRight)})
+	second := tracks[0]
+	if tracks[1] != first || second == first {
+		t.Fatalf("expected the new hand to start a track, got %v", trackIDs(tracks))
+	}
+
+	// The first hand is missed for a few frames, then seen again
+	for range 3 {
+		now = now.Add(frameInterval)
+		tracker.Update(now, []detector.HandLandmarks{handAt(0.7, 0.5, HandednessLeft)})
+	}
+	if len(tracker.Tracks()) != 2 {
+		t.Errorf("expected a briefly missed hand to keep its track, got %d tracks", len(tracker.Tracks()))
+	}
+	now = now.Add(frameInterval)
+	if tracks, _ := tracker.Update(now, []detector.HandLandmarks{handAt(0.32, 0.5, HandednessRight)}); tracks[0] != first || len(first.Path) != 1 {
+		t.Errorf("expected the hand back on its track and path, got %v", trackIDs(tracks))
+	}
+
+	// Both hands leave for good
+	now = now.Add(TrackTimeout + frameInterval)
+	tracks, ended := tracker.Update(now, nil)
+	if len(tracks) != 0 || len(tracker.Tracks()) != 0 {
+		t.Errorf("expected the tracks to end, got %d", len(tracker.Tracks()))
+	}
+	if len(ended) != 2 || ended[0] != first || ended[1] != second {
+		t.Errorf("expected both tracks reported ended, got %v", trackIDs(ended))
+	}
+
+	// A hand seen where one left is a new hand
+	now = now.Add(frameInterval)
+	tracks, _ = tracker.Update(now, []detector.HandLandmarks{handAt(0.32, 0.5, HandednessRight)})
+	if tracks[0].ID == first.ID || tracks[0].ID == second.ID || len(tracks[0].Path) != 0 {
+		t.Errorf("expected a new track with an empty path, got track %d", tracks[0].ID)
+	}
+
+	if ended := tracker.Reset(); len(ended) != 1 || ended[0] != tracks[0] {
+		t.Errorf("expected the reset to end the new track, got %v", trackIDs(ended))
+	}
+	if len(tracker.Tracks()) != 0 {
+		t.Errorf("expected no tracks after a reset, got %d", len(tracker.Tracks()))
+	}
+}
+
+func TestHandTrack_Path(t *testing.T) {
+	var track HandTrack
+	for i := range 5 {
+		track.AddPoint(PathPoint{Timestamp: int64(i)}, 3)
+	}
+	if len(track.Path) != 3 || track.Path[0].Timestamp != 2 || track.Path[2].Timestamp != 4 {
+		t.Fatalf("expected the latest 3 points, got %+v", track.Path)
+	}
+
+	track.DropPath(2)
+	if len(track.Path) != 1 || track.Path[0].Timestamp != 4 {
+		t.Errorf("expected the points after the dropped ones, got %+v", track.Path)
+	}
+	track.DropPath(5)
+	if len(track.Path) != 0 {
+		t.Errorf("expected an empty path, got %+v", track.Path)
+	}
+}
@@ -4,6 +4,7 @@ package gesture
 import (
 	"math"
 	"sort"
+	"sync"
 
 	"github.com/ayusman/kuchipudi/internal/detector"
 )
@@ -16,16 +17,74 @@ const (
 	TypeStatic Type = "static"
 	// TypeDynamic represents a dynamic gesture (motion over time).
 	TypeDynamic Type = "dynamic"
+	// TypeTwoHand represents a static pose made with both hands.
+	TypeTwoHand Type = "two_hand"
 )
 
 // Template represents a gesture template for matching.
 type Template struct {
-	ID        string             // Unique identifier for the template
-	Name      string             // Human-readable name
-	Type      Type               // Static or dynamic gesture type
-	Landmarks []detector.Point3D // Normalized landmarks for static gestures
-	Path      []PathPoint        // Path points for dynamic gestures
-	Tolerance float64            // Maximum distance for a match
+	ID         string               // Unique identifier for the template
+	Name       string               // Human-readable name
+	Type       Type                 // Static or dynamic gesture type
+	Landmarks  []detector.Point3D   // Normalized landmarks for static gestures
+	Exemplars  [][]detector.Point3D // Individual training poses, compared instead of Landmarks if Static.Neighbors > 0
+	Hand       Hand                 // Which hands may make a static gesture (either if empty)
+	RecordedBy string               // Handedness label of the hand Landmarks were recorded with, "" if unknown
+	Static     StaticOptions        // How static poses are compared with Landmarks or Exemplars
+	Hands      *HandPair            // Both hands of two-hand gestures
+	Path       []PathPoint          // Path points for dynamic gestures
+	Dynamic    PathOptions          // How dynamic paths are compared with Path
+	Tolerance  float64              // Maximum distance for a match
+	Activation ActivationConfig     // When a match fires its action
+}
+
+// MatchMode selects what a static pose is compared by.
+type MatchMode string
+
+const (
+	// MatchLandmarks sums the distances between corresponding landmarks.
+	MatchLandmarks MatchMode = "landmarks"
+	// MatchFeatures compares finger curl, extension, fingertip spread and palm
+	// orientation; see HandFeatures.
+	MatchFeatures MatchMode = "features"
+)
+
+// StaticOptions controls how a static pose is compared with its template.
+// The zero value compares wrist-relative, size-normalized landmarks.
+type StaticOptions struct {
+	// RotationInvariant compares poses in canonical orientation, so tilting the
+	// hand does not matter. Leave it off for gestures told apart by orientation,
+	// such as thumbs up and thumbs down.
+	RotationInvariant bool
+	Mode              MatchMode       // MatchLandmarks if empty
+	Weights           *FeatureWeights // Weights for MatchFeatures, DefaultFeatureWeights if nil
+	// Neighbors, if positive, matches by a k-NN vote over the nearest
+	// Template.Exemplars instead of comparing with the averaged Landmarks.
+	// 1 matches the nearest exemplar.
+	Neighbors int
+}
+
+// distance compares normalized input landmarks with template landmarks.
+// Feature matching falls back to landmark distances for partial landmark sets.
+func (o StaticOptions) distance(input, template []detector.Point3D) float64 {
+	if o.RotationInvariant {
+		input = detector.CanonicalOrientation(input)
+		template = detector.CanonicalOrientation(template)
+	}
+
+	if o.Mode == MatchFeatures {
+		a, okA := ExtractFeatures(input)
+		b, okB := ExtractFeatures(template)
+		if okA && okB {
+			weights := DefaultFeatureWeights
+			if o.Weights != nil {
+				weights = *o.Weights
+			}
+			return a.Distance(b, weights)
+		}
+	}
+
+	return euclideanDistance(input, template)
 }
 
 // PathPoint represents a point in a dynamic gesture path.
@@ -33,6 +92,10 @@ type PathPoint struct {
 	X         float64 // X coordinate
 	Y         float64 // Y coordinate
 	Timestamp int64   // Timestamp in milliseconds
+
+	// Every landmark of the hand at this point, in image coordinates, for
+	// gestures that follow more than X and Y; nil if they were not recorded
+	Hand []detector.Point3D
 }
 
 // Match represents a matching result between input and a template.
@@ -40,10 +103,15 @@ type Match struct {
 	Template *Template // The matched template
 	Score    float64   // Match score (0-1, higher is better)
 	Distance float64   // Euclidean distance between input and template
+
+	// Window of the input path a dynamic match was found in, path[Start:End]
+	Start, End int
 }
 
 // StaticMatcher matches static hand gestures against registered templates.
+// Templates may be added or removed while Match is running on another goroutine.
 type StaticMatcher struct {
+	mu        sync.RWMutex
 	templates []*Template
 	OnMatch   func(id, name string)
 }
@@ -56,25 +124,35 @@ func NewStaticMatcher() *StaticMatcher {
 }
 
 // AddTemplate adds a gesture template to the matcher.
+// A template with the same ID is replaced.
 func (m *StaticMatcher) AddTemplate(t *Template) {
 	if t == nil {
 		return
 	}
-	m.templates = append(m.templates, t)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = upsertTemplate(m.templates, t)
 }
 
 // RemoveTemplate removes a template by its ID.
 func (m *StaticMatcher) RemoveTemplate(id string) {
-	for i, t := range m.templates {
-		if t.ID == id {
-			// Remove element by shifting
-			m.templates = append(m.templates[:i], m.templates[i+1:]...)
-			return
-		}
-	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = removeTemplate(m.templates, id)
+}
+
+// snapshot returns the current template list.
+// The returned slice is never modified, so it can be read without holding the lock.
+func (m *StaticMatcher) snapshot() []*Template {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.templates
 }
 
 // Match finds matching templates for the given hand landmarks.
+// Templates restricted to the other hand are skipped, and the input is mirrored
+// for templates recorded with the other hand.
 // Returns matches sorted by score in descending order (best matches first).
 func (m *StaticMatcher) Match(hand *detector.HandLandmarks) []Match {
 	if hand == nil {
@@ -88,23 +166,48 @@ func (m *StaticMatcher) Match(hand *detector.HandLandmarks) []Match {
 	}
 
 	inputLandmarks := normalized.Points[:]
+	var mirroredLandmarks []detector.Point3D // Computed on first use
 
 	var matches []Match
 
 	// Step 2-4: For each static template, compute distance and score
-	for _, template := range m.templates {
+	for _, template := range m.snapshot() {
 		if template.Type != TypeStatic {
 			continue
 		}
 
-		// Compute Euclidean distance
-		distance := euclideanDistance(inputLandmarks, template.Landmarks)
+		// Skip templates that have not been trained yet
+		if len(template.Landmarks) == 0 {
+			continue
+		}
+
+		if !template.Hand.Allows(hand.Handedness) {
+			continue
+		}
+
+		input := inputLandmarks
+		if needsMirror(template.RecordedBy, hand.Handedness) {
+			if mirroredLandmarks == nil {
+				mirroredLandmarks = mirrorX(inputLandmarks)
+			}
+			input = mirroredLandmarks
+		}
+
+		// Compare with the nearest exemplars, or the averaged landmarks
+		var distance float64
+		var matched bool
+		if template.Static.Neighbors > 0 && len(template.Exemplars) > 0 {
+			distance, matched = template.Static.nearestExemplars(input, template.Exemplars, template.Tolerance)
+		} else {
+			distance = template.Static.distance(input, template.Landmarks)
+			matched = distance <= template.Tolerance
+		}
 
 		// Calculate score: 1.0 / (1.0 + distance)
 		score := 1.0 / (1.0 + distance)
 
 		// Only include if distance is within tolerance
-		if distance <= template.Tolerance {
+		if matched {
 			matches = append(matches, Match{
 				Template: template,
 				Score:    score,
@@ -121,6 +224,47 @@ func (m *StaticMatcher) Match(hand *detector.HandLandmarks) []Match {
 	return matches
 }
 
+// identified is a template, or a template prepared for matching, known by its ID.
+type identified interface {
+	templateID() string
+}
+
+// templateID returns t.ID.
+func (t *Template) templateID() string {
+	return t.ID
+}
+
+// upsertTemplate returns a copy of templates with t added, replacing any template with the same ID.
+// The input slice is not modified, so readers holding it are unaffected.
+func upsertTemplate[T identified](templates []T, t T) []T {
+	updated := make([]T, 0, len(templates)+1)
+	replaced := false
+	for _, existing := range templates {
+		if existing.templateID() == t.templateID() {
+			updated = append(updated, t)
+			replaced = true
+			continue
+		}
+		updated = append(updated, existing)
+	}
+	if !replaced {
+		updated = append(updated, t)
+	}
+	return updated
+}
+
+// removeTemplate returns a copy of templates without the template with the given ID.
+// The input slice is not modified, so readers holding it are unaffected.
+func removeTemplate[T identified](templates []T, id string) []T {
+	updated := make([]T, 0, len(templates))
+	for _, existing := range templates {
+		if existing.templateID() != id {
+			updated = append(updated, existing)
+		}
+	}
+	return updated
+}
+
 // euclideanDistance calculates the total Euclidean distance between two sets of 3D points.
 // It sums the distances between corresponding points in the two slices.
 func euclideanDistance(a, b []detector.Point3D) float64 {
@@ -1,6 +1,7 @@
 package gesture
 
 import (
+	"math"
 	"testing"
 
 	"github.com/ayusman/kuchipudi/internal/detector"
@@ -228,3 +229,152 @@ func TestEuclideanDistance(t *testing.T) {
 		t.Errorf("expected distance 0 for empty slices, got %f", dist3)
 	}
 }
+
+func TestStaticMatcher_AddTemplate_ReplacesByID(t *testing.T) {
+	matcher := NewStaticMatcher()
+
+	thumbsUp := detector.ThumbsUpLandmarks()
+	normalized := thumbsUp.Normalize()
+
+	matcher.AddTemplate(&Template{ID: "g1", Name: "Old", Type: TypeStatic, Landmarks: normalized.Points[:], Tolerance: 0.5})
+	matcher.AddTemplate(&Template{ID: "g1", Name: "New", Type: TypeStatic, Landmarks: normalized.Points[:], Tolerance: 0.5})
+
+	if len(matcher.templates) != 1 {
+		t.Fatalf("expected 1 template after replace, got %d", len(matcher.templates))
+	}
+
+	matches := matcher.Match(&thumbsUp)
+	if len(matches) != 1 || matches[0].Template.Name != "New" {
+		t.Errorf("expected replaced template to match, got %+v", matches)
+	}
+}
+
+func TestStaticMatcher_SkipsUntrainedTemplates(t *testing.T) {
+	matcher := NewStaticMatcher()
+	matcher.AddTemplate(&Template{ID: "g1", Name: "Untrained", Type: TypeStatic, Tolerance: 0.5})
+
+	input := detector.ThumbsUpLandmarks()
+	if matches := matcher.Match(&input); len(matches) != 0 {
+		t.Errorf("untrained template should not match, got %d matches", len(matches))
+	}
+}
+
+func TestStaticMatcher_ConcurrentMutation(t *testing.T) {
+	matcher := NewStaticMatcher()
+
+	thumbsUp := detector.ThumbsUpLandmarks()
+	normalized := thumbsUp.Normalize()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			matcher.AddTemplate(&Template{ID: "g1", Type: TypeStatic, Landmarks: normalized.Points[:], Tolerance: 0.5})
+			matcher.RemoveTemplate("g1")
+		}
+	}()
+
+	for i := 0; i < 200; i++ {
+		matcher.Match(&thumbsUp)
+	}
+	<-done
+}
+
+// mirrorHand reflects a hand across the middle of the image and gives it the other label,
+// which is how the same pose made with the other hand appears to the camera.
+func mirrorHand(h detector.HandLandmarks, handedness string) detector.HandLandmarks {
+	h.Handedness = handedness
+	for i := range h.Points {
+		h.Points[i].X = 1 - h.Points[i].X
+	}
+	return h
+}
+
+func TestStaticMatcher_Handedness(t *testing.T) {
+	right := detector.ThumbsUpLandmarks() // Labelled "Right"
+	left := mirrorHand(right, HandednessLeft)
+	normalized := right.Normalize()
+
+	tests := []struct {
+		name       string
+		hand       Hand
+		recordedBy string
+		input      detector.HandLandmarks
+		want       bool
+	}{
+		{"either hand mirrors the other hand", HandEither, HandednessRight, left, true},
+		{"either hand matches the recorded hand", HandEither, HandednessRight, right, true},
+		{"right only skips the left hand", HandRight, HandednessRight, left, false},
+		{"left only mirrors a right-hand template", HandLeft, HandednessRight, left, true},
+		{"unknown recording hand is not mirrored", HandEither, "", left, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewStaticMatcher()
+			matcher.AddTemplate(&Template{
+				ID:         "thumbs-up",
+				Type:       TypeStatic,
+				Landmarks:  normalized.Points[:],
+				Hand:       tt.hand,
+				RecordedBy: tt.recordedBy,
+				Tolerance:  0.1,
+			})
+
+			matches := matcher.Match(&tt.input)
+			if got := len(matches) == 1; got != tt.want {
+				t.Errorf("expected match %v, got %+v", tt.want, matches)
+			}
+		})
+	}
+}
+
+// tiltHand rotates a hand by angle radians in the image plane around its wrist.
+func tiltHand(h detector.HandLandmarks, angle float64) detector.HandLandmarks {
+	sin, cos := math.Sincos(angle)
+	wrist := h.Points[detector.Wrist]
+	for i, p := range h.Points {
+		x, y := p.X-wrist.X, p.Y-wrist.Y
+		h.Points[i].X = wrist.X + x*cos - y*sin
+		h.Points[i].Y = wrist.Y + x*sin + y*cos
+	}
+	return h
+}
+
+func TestStaticMatcher_RotationInvariant(t *testing.T) {
+	thumbsUp := detector.ThumbsUpLandmarks()
+	normalized := thumbsUp.Normalize()
+
+	tilted := tiltHand(thumbsUp, 20*math.Pi/180)
+	thumbsDown := tiltHand(thumbsUp, math.Pi)
+
+	tests := []struct {
+		name      string
+		invariant bool
+		input     detector.HandLandmarks
+		want      bool
+	}{
+		{"tilted hand matches when rotation is ignored", true, tilted, true},
+		{"tilted hand does not match when orientation matters", false, tilted, false},
+		{"thumbs down matches thumbs up when rotation is ignored", true, thumbsDown, true},
+		{"thumbs down does not match thumbs up when orientation matters", false, thumbsDown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewStaticMatcher()
+			matcher.AddTemplate(&Template{
+				ID:        "thumbs-up",
+				Type:      TypeStatic,
+				Landmarks: normalized.Points[:],
+				Static:    StaticOptions{RotationInvariant: tt.invariant},
+				Tolerance: 0.1,
+			})
+
+			matches := matcher.Match(&tt.input)
+			if got := len(matches) == 1; got != tt.want {
+				t.Errorf("expected match %v, got %+v", tt.want, matches)
+			}
+		})
+	}
+}
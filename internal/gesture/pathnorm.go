@@ -0,0 +1,175 @@
+package gesture
+
+import "math"
+
+// PathNormalization selects how a dynamic path is made comparable however
+// large and wherever in the frame the gesture was made.
+type PathNormalization string
+
+const (
+	// NormalizeAxes stretches each axis of the path's bounding box to 0-1.
+	// Any two paths become the same size, but a small diagonal flick looks
+	// like a large straight swipe, and the jitter across a straight swipe is
+	// blown up to the full range.
+	NormalizeAxes PathNormalization = "axes"
+	// NormalizeUniform scales both axes by the larger side of the bounding box,
+	// keeping the shape of the path, with its bounding box at the origin.
+	NormalizeUniform PathNormalization = "uniform"
+	// NormalizeCentered scales like NormalizeUniform, with the centroid of the
+	// path at the origin, so that where a path lingers matters less than where
+	// its bounding box ends.
+	NormalizeCentered PathNormalization = "centered"
+)
+
+// PathFeatures selects what is compared at each point of a normalized path.
+type PathFeatures string
+
+const (
+	// PathPositions compares normalized positions only.
+	PathPositions PathFeatures = "positions"
+	// PathDisplacement also compares how far each point lies from the start of
+	// the path before normalization, so that a small and a large circle differ.
+	PathDisplacement PathFeatures = "displacement"
+	// PathVelocity also compares how fast the path moves at each point before
+	// normalization, in path units per second, so that a flick and a slow
+	// drag differ.
+	PathVelocity PathFeatures = "velocity"
+)
+
+// PathOptions controls how a dynamic path is compared with its template.
+// The zero value follows PathPoint.X and Y, stretching both axes to 0-1, and
+// compares positions only.
+type PathOptions struct {
+	Normalization PathNormalization // NormalizeAxes if empty
+	Features      PathFeatures      // PathPositions if empty
+
+	// What is followed along the path, which then needs the landmarks of the
+	// hand at every point. The points of the hand listed replace X and Y.
+	Track []Tracked
+}
+
+// normalize normalizes path as selected by o.Normalization.
+// Timestamps are preserved.
+func (o PathOptions) normalize(path []PathPoint) []PathPoint {
+	switch o.Normalization {
+	case NormalizeUniform, NormalizeCentered:
+		return normalizePathUniform(path, o.Normalization == NormalizeCentered)
+	default:
+		return normalizePath(path)
+	}
+}
+
+// distance measures the DTW distance between two paths as selected by o,
+// warped within the band dynamic gestures are matched in (see dtwBand).
+// Returns infinity if either path lacks the hand landmarks o needs.
+func (o PathOptions) distance(path, template []PathPoint) float64 {
+	a, ok := o.motion(path)
+	if !ok {
+		return math.Inf(1)
+	}
+	b, ok := o.motion(template)
+	if !ok {
+		return math.Inf(1)
+	}
+
+	var w warper
+	n, m := len(a.vectors), len(b.vectors)
+	return w.distance(n, m, bandRadius(n, m), math.Inf(1), func(i, j int) float64 {
+		return a.cost(i, b, j)
+	})
+}
+
+// locating returns the options spot locates a gesture in a longer path with:
+// positions and pose only, since displacement and depth are measured from the
+// start of the gesture, which is not known until it has been located. The
+// window found is then measured with every feature.
+func (o PathOptions) locating() PathOptions {
+	located := PathOptions{Normalization: o.Normalization}
+	for _, t := range o.Track {
+		if t != TrackDepth {
+			located.Track = append(located.Track, t)
+		}
+	}
+	return located
+}
+
+// key identifies the way o compares paths, so that paths prepared for one
+// template can be reused for another that compares them the same way.
+func (o PathOptions) key() string {
+	key := string(o.Normalization) + "/" + string(o.Features)
+	for _, t := range o.Track {
+		key += "/" + string(t)
+	}
+	return key
+}
+
+// normalizePathUniform scales path by the larger side of its bounding box,
+// placing either its bounding box or its centroid at the origin.
+// Timestamps are preserved.
+func normalizePathUniform(path []PathPoint, centered bool) []PathPoint {
+	if path == nil {
+		return nil
+	}
+	if len(path) == 0 {
+		return []PathPoint{}
+	}
+
+	minX, maxX := path[0].X, path[0].X
+	minY, maxY := path[0].Y, path[0].Y
+	var sumX, sumY float64
+	for _, p := range path {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+		sumX += p.X
+		sumY += p.Y
+	}
+
+	originX, originY := minX, minY
+	if centered {
+		originX, originY = sumX/float64(len(path)), sumY/float64(len(path))
+	}
+
+	scale := math.Max(maxX-minX, maxY-minY)
+	normalized := make([]PathPoint, len(path))
+	for i, p := range path {
+		normalized[i] = PathPoint{Timestamp: p.Timestamp}
+		if scale > 0 {
+			normalized[i].X = (p.X - originX) / scale
+			normalized[i].Y = (p.Y - originY) / scale
+		}
+	}
+	return normalized
+}
+
+// displacements returns how far each point of path lies from its first.
+func displacements(path []PathPoint) []PathPoint {
+	result := make([]PathPoint, len(path))
+	for i, p := range path {
+		result[i] = PathPoint{X: p.X - path[0].X, Y: p.Y - path[0].Y, Timestamp: p.Timestamp}
+	}
+	return result
+}
+
+// velocities returns the velocity of path at each point, in units per second,
+// from the step since the previous point. The first point takes the velocity
+// of the second, and a step without time between its points keeps the
+// previous velocity.
+func velocities(path []PathPoint) []PathPoint {
+	result := make([]PathPoint, len(path))
+	for i := 1; i < len(path); i++ {
+		dt := float64(path[i].Timestamp-path[i-1].Timestamp) / 1000
+		if dt <= 0 {
+			result[i] = result[i-1]
+		} else {
+			result[i] = PathPoint{
+				X: (path[i].X - path[i-1].X) / dt,
+				Y: (path[i].Y - path[i-1].Y) / dt,
+			}
+		}
+		result[i].Timestamp = path[i].Timestamp
+	}
+	if len(path) > 1 {
+		result[0] = PathPoint{X: result[1].X, Y: result[1].Y, Timestamp: path[0].Timestamp}
+	}
+	return result
+}
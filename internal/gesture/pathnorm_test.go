@@ -0,0 +1,128 @@
+package gesture
+
+import (
+	"math"
+	"testing"
+)
+
+func TestNormalizePathUniform(t *testing.T) {
+	// A 0.4 wide, 0.1 high path keeps its 4:1 aspect
+	path := []PathPoint{{X: 0.2, Y: 0.5, Timestamp: 7}, {X: 0.6, Y: 0.6}, {X: 0.4, Y: 0.55}}
+
+	got := normalizePathUniform(path, false)
+	want := []PathPoint{{X: 0, Y: 0, Timestamp: 7}, {X: 1, Y: 0.25}, {X: 0.5, Y: 0.125}}
+	for i := range want {
+		if !floatEqual(got[i].X, want[i].X) || !floatEqual(got[i].Y, want[i].Y) || got[i].Timestamp != want[i].Timestamp {
+			t.Errorf("point %d: expected %+v, got %+v", i, want[i], got[i])
+		}
+	}
+
+	// Centered, the centroid is at the origin
+	var sumX, sumY float64
+	for _, p := range normalizePathUniform(path, true) {
+		sumX += p.X
+		sumY += p.Y
+	}
+	if !floatEqual(sumX, 0) || !floatEqual(sumY, 0) {
+		t.Errorf("expected the centroid at the origin, got (%f, %f)", sumX/3, sumY/3)
+	}
+
+	if got := normalizePathUniform([]PathPoint{{X: 1, Y: 1}}, false); got[0].X != 0 || got[0].Y != 0 {
+		t.Errorf("expected a single point at the origin, got %+v", got)
+	}
+}
+
+func TestPathOptions_StraightSwipes(t *testing.T) {
+	// Straight swipes, with the jitter of a real hand across them
+	left := newRecorder(0.7, 0.5).move(0.3, 0.5, 12).path
+	right := newRecorder(0.3, 0.5).move(0.7, 0.5, 12).path
+	anotherLeft := newRecorder(0.65, 0.45).move(0.35, 0.45, 10).rest(1).path
+
+	for _, normalization := range []PathNormalization{NormalizeUniform, NormalizeCentered} {
+		opts := PathOptions{Normalization: normalization}
+		same, opposite := opts.distance(anotherLeft, left), opts.distance(anotherLeft, right)
+		if same > 0.05 || opposite < 0.3 {
+			t.Errorf("%s: expected swipes in the same direction to be close and opposite ones apart, got %f and %f", normalization, same, opposite)
+		}
+	}
+
+	// Stretching the jitter to full range makes even the same swipe look unlike itself
+	if d := (PathOptions{}).distance(anotherLeft, left); d < 0.1 {
+		t.Errorf("expected the jitter to be blown up by stretching each axis, got %f", d)
+	}
+}
+
+func TestPathOptions_Features(t *testing.T) {
+	small := newRecorder(0.55, 0.5).circle(0.5, 0.5, 24).path
+	large := newRecorder(0.7, 0.5).circle(0.5, 0.5, 24).path
+	slow := newRecorder(0.7, 0.4).move(0.3, 0.6, 24).path
+	fast := newRecorder(0.7, 0.4).move(0.3, 0.6, 6).path
+
+	tests := []struct {
+		name     string
+		features PathFeatures
+		a, b     []PathPoint
+		apart    bool
+	}{
+		{"positions alone do not tell circle sizes apart", PathPositions, small, large, false},
+		{"displacement tells circle sizes apart", PathDisplacement, small, large, true},
+		{"positions alone do not tell speeds apart", PathPositions, slow, fast, false},
+		{"velocity tells speeds apart", PathVelocity, slow, fast, true},
+		{"velocity does not tell apart the same speed", PathVelocity, fast, newRecorder(0.7, 0.4).move(0.3, 0.6, 6).path, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := PathOptions{Normalization: NormalizeUniform, Features: tt.features}
+			d := opts.distance(tt.a, tt.b)
+			if apart := d > 0.15; apart != tt.apart {
+				t.Errorf("expected apart %v, got distance %f", tt.apart, d)
+			}
+		})
+	}
+}
+
+func TestVelocities(t *testing.T) {
+	path := []PathPoint{
+		{X: 0, Y: 0, Timestamp: 0},
+		{X: 0.1, Y: 0, Timestamp: 100},
+		{X: 0.1, Y: 0.2, Timestamp: 200},
+		{X: 0.5, Y: 0.5, Timestamp: 200}, // No time passed
+	}
+
+	got := velocities(path)
+	want := [][2]float64{{1, 0}, {1, 0}, {0, 2}, {0, 2}}
+	for i, w := range want {
+		if math.Abs(got[i].X-w[0]) > 1e-9 || math.Abs(got[i].Y-w[1]) > 1e-9 {
+			t.Errorf("point %d: expected velocity %v, got (%f, %f)", i, w, got[i].X, got[i].Y)
+		}
+	}
+}
+
+func TestDynamicMatcher_PathOptions(t *testing.T) {
+	small := &Template{
+		ID:        "small-circle",
+		Type:      TypeDynamic,
+		Path:      newRecorder(0.55, 0.5).circle(0.5, 0.5, 24).path,
+		Dynamic:   PathOptions{Normalization: NormalizeCentered, Features: PathDisplacement},
+		Tolerance: 0.1,
+	}
+	large := &Template{
+		ID:        "large-circle",
+		Type:      TypeDynamic,
+		Path:      newRecorder(0.7, 0.5).circle(0.5, 0.5, 24).path,
+		Dynamic:   PathOptions{Normalization: NormalizeCentered, Features: PathDisplacement},
+		Tolerance: 0.1,
+	}
+
+	matcher := NewDynamicMatcher()
+	matcher.AddTemplate(small)
+	matcher.AddTemplate(large)
+
+	// A large circle drawn elsewhere in the frame, between idle jitter
+	path := newRecorder(0.4, 0.3).rest(10).circle(0.2, 0.3, 24).rest(10).path
+	matches := matcher.Match(path)
+	if len(matches) != 1 || matches[0].Template.ID != "large-circle" {
+		t.Fatalf("expected only the large circle to match, got %+v", matches)
+	}
+}
@@ -0,0 +1,119 @@
+package gesture
+
+import "math"
+
+// minSpotScale is how small, relative to the recorded template, a motion may be
+// performed and still be spotted. It keeps idle jitter of the hand, which
+// normalization would otherwise blow up to full size, from matching anything.
+const minSpotScale = 0.2
+
+// spot finds the window of a series of n elements that best matches the
+// whole of a template of m elements by subsequence DTW, where cost(i, j) is the
+// distance between the i-th template element and the j-th series element: the
+// template must be matched from its first element to its last, while the
+// window may begin and end anywhere in the series.
+// Returns the DTW cost of the best window and its bounds, series[start:end].
+// The cost is infinite if either sequence is empty.
+func (w *warper) spot(m, n int, cost func(i, j int) float64) (best float64, start, end int) {
+	if m == 0 || n == 0 {
+		return math.Inf(1), 0, 0
+	}
+
+	// Two rows of the (m x n) cost matrix, each cell paired with the series
+	// index its warping path started at
+	prev, curr := w.rows(n)
+	if cap(w.prevStart) < n {
+		w.prevStart = make([]int, n)
+		w.currStart = make([]int, n)
+	}
+	prevStart, currStart := w.prevStart[:n], w.currStart[:n]
+
+	// Any series element may begin the window at no cost for what came before
+	for j := 0; j < n; j++ {
+		prev[j] = cost(0, j)
+		prevStart[j] = j
+	}
+
+	for i := 1; i < m; i++ {
+		curr[0] = prev[0] + cost(i, 0)
+		currStart[0] = prevStart[0]
+
+		for j := 1; j < n; j++ {
+			least, from := prev[j-1], prevStart[j-1] // Both advance
+			if prev[j] < least {
+				least, from = prev[j], prevStart[j] // Template advances
+			}
+			if curr[j-1] < least {
+				least, from = curr[j-1], currStart[j-1] // Series advances
+			}
+			curr[j] = least + cost(i, j)
+			currStart[j] = from
+		}
+
+		prev, curr = curr, prev
+		prevStart, currStart = currStart, prevStart
+	}
+
+	// The window may end anywhere
+	best = math.Inf(1)
+	for j := 0; j < n; j++ {
+		if prev[j] < best {
+			best, start, end = prev[j], prevStart[j], j+1
+		}
+	}
+	return best, start, end
+}
+
+// locate finds the part of path performing t, given the whole path prepared
+// in input as t's locating options ask for. Windows are located on the whole
+// path, then measured on their own, so that motion before and after them does
+// not distort them.
+// Returns false if no window long and large enough was found.
+func (t *dynamicTemplate) locate(w *warper, path []PathPoint, input *motion) (start, end int, ok bool) {
+	// No window moves further than the whole path, idle as it mostly is
+	if t.Dynamic.extent(path) < minSpotScale*t.extent {
+		return 0, 0, false
+	}
+
+	m := len(t.locating.vectors)
+	cost, start, end := w.spot(m, len(input.vectors), func(i, j int) float64 {
+		return t.locating.cost(i, input, j)
+	})
+	if math.IsInf(cost, 1) || end-start < max(2, m/2) {
+		return 0, 0, false
+	}
+
+	if t.Dynamic.extent(path[start:end]) < minSpotScale*t.extent {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// Completed returns the matches of a path of n points whose window ended before
+// its last point. A window reaching the latest point may still grow as the
+// motion goes on, so acting on it could cut a gesture short and leave its
+// remainder to be taken for another.
+func Completed(matches []Match, n int) []Match {
+	var completed []Match
+	for _, m := range matches {
+		if m.End < n {
+			completed = append(completed, m)
+		}
+	}
+	return completed
+}
+
+// pathExtent returns the larger side of the bounding box of path.
+func pathExtent(path []PathPoint) float64 {
+	if len(path) == 0 {
+		return 0
+	}
+
+	minX, maxX := path[0].X, path[0].X
+	minY, maxY := path[0].Y, path[0].Y
+	for _, p := range path {
+		minX, maxX = math.Min(minX, p.X), math.Max(maxX, p.X)
+		minY, maxY = math.Min(minY, p.Y), math.Max(maxY, p.Y)
+	}
+	return math.Max(maxX-minX, maxY-minY)
+}
@@ -0,0 +1,205 @@
+package gesture
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+// frameMs is the time between frames recorded at the pipeline's active frame rate.
+const frameMs = 66
+
+// recorder simulates the fingertip path the pipeline buffers, one point per
+// frame in normalized image coordinates, with the jitter of a hand held in
+// front of a camera.
+type recorder struct {
+	rng  *rand.Rand
+	path []PathPoint
+	x, y float64
+}
+
+// newRecorder starts a recording with the fingertip at (x, y).
+func newRecorder(x, y float64) *recorder {
+	return &recorder{rng: rand.New(rand.NewPCG(1, 2)), x: x, y: y}
+}
+
+// add records a point near (x, y).
+func (r *recorder) add(x, y float64) {
+	const jitter = 0.004
+	r.path = append(r.path, PathPoint{
+		X:         x + (r.rng.Float64()*2-1)*jitter,
+		Y:         y + (r.rng.Float64()*2-1)*jitter,
+		Timestamp: int64(len(r.path) * frameMs),
+	})
+}
+
+// rest records the hand held still for frames.
+func (r *recorder) rest(frames int) *recorder {
+	for range frames {
+		r.add(r.x, r.y)
+	}
+	return r
+}
+
+// move records the fingertip moving in a straight line to (x, y) over frames.
+func (r *recorder) move(x, y float64, frames int) *recorder {
+	fromX, fromY := r.x, r.y
+	for i := 1; i <= frames; i++ {
+		t := float64(i) / float64(frames)
+		r.add(fromX+(x-fromX)*t, fromY+(y-fromY)*t)
+	}
+	r.x, r.y = x, y
+	return r
+}
+
+// circle records the fingertip circling counterclockwise once around
+// (cx, cy), starting and ending where it is, over frames.
+func (r *recorder) circle(cx, cy float64, frames int) *recorder {
+	radius := math.Hypot(r.x-cx, r.y-cy)
+	start := math.Atan2(r.y-cy, r.x-cx)
+	for i := 1; i <= frames; i++ {
+		angle := start + 2*math.Pi*float64(i)/float64(frames)
+		r.add(cx+radius*math.Cos(angle), cy+radius*math.Sin(angle))
+	}
+	return r
+}
+
+// swipeTemplates returns templates of swiping down to the left and back up to
+// the right, recorded on their own the way the recorder captures training
+// samples. The swipes are diagonal because normalizing each axis to the same
+// range would blow up the jitter across a straight swipe.
+func swipeTemplates() (left, right *Template) {
+	left = &Template{
+		ID:        "swipe-left",
+		Type:      TypeDynamic,
+		Path:      newRecorder(0.7, 0.4).move(0.3, 0.6, 12).path,
+		Tolerance: 0.15,
+	}
+	right = &Template{
+		ID:        "swipe-right",
+		Type:      TypeDynamic,
+		Path:      newRecorder(0.3, 0.6).move(0.7, 0.4, 12).path,
+		Tolerance: 0.15,
+	}
+	return left, right
+}
+
+func TestWarper_Spot(t *testing.T) {
+	template := []PathPoint{{X: 0, Y: 0}, {X: 0.5, Y: 0.5}, {X: 1, Y: 1}}
+	series := []PathPoint{
+		{X: 0, Y: 1}, {X: 0, Y: 1}, // Before the gesture
+		{X: 0, Y: 0}, {X: 0.5, Y: 0.5}, {X: 0.5, Y: 0.5}, {X: 1, Y: 1},
+		{X: 1, Y: 0}, // After the gesture
+	}
+
+	var w warper
+	cost, start, end := w.spot(len(template), len(series), func(i, j int) float64 {
+		return pointDistance(template[i], series[j])
+	})
+	if !floatEqual(cost, 0) || start != 2 || end != 6 {
+		t.Errorf("expected the gesture at [2, 6) at no cost, got [%d, %d) at %f", start, end, cost)
+	}
+
+	if cost, _, _ := w.spot(len(template), 0, nil); cost < 1e9 {
+		t.Errorf("expected an infinite cost for an empty series, got %f", cost)
+	}
+}
+
+func TestDynamicMatcher_SpotsGestureInBuffer(t *testing.T) {
+	left, right := swipeTemplates()
+
+	tests := []struct {
+		name      string
+		path      []PathPoint
+		want      string // Template expected to match, "" for none
+		wantStart int
+		wantEnd   int
+	}{
+		{
+			name: "swipe between idle jitter",
+			path: newRecorder(0.7, 0.4).rest(25).move(0.3, 0.6, 12).rest(23).path,
+			want: "swipe-left", wantStart: 25, wantEnd: 37,
+		},
+		{
+			name: "swipe that just ended",
+			path: newRecorder(0.3, 0.6).rest(48).move(0.7, 0.4, 12).path,
+			want: "swipe-right", wantStart: 48, wantEnd: 60,
+		},
+		{
+			name: "quick swipe at the start of the buffer",
+			path: newRecorder(0.7, 0.4).move(0.3, 0.6, 6).rest(54).path,
+			want: "swipe-left", wantStart: 0, wantEnd: 6,
+		},
+		{
+			name: "idle jitter alone",
+			path: newRecorder(0.5, 0.5).rest(60).path,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := NewDynamicMatcher()
+			matcher.AddTemplate(left)
+			matcher.AddTemplate(right)
+
+			matches := matcher.Match(tt.path)
+			if tt.want == "" {
+				if len(matches) != 0 {
+					t.Fatalf("expected no match, got %s", matches[0].Template.ID)
+				}
+				return
+			}
+			if len(matches) != 1 || matches[0].Template.ID != tt.want {
+				t.Fatalf("expected only %s to match, got %+v", tt.want, matches)
+			}
+
+			// The window is found to within a frame of where the motion was made
+			m := matches[0]
+			if abs(m.Start-tt.wantStart) > 1 || abs(m.End-tt.wantEnd) > 1 {
+				t.Errorf("expected window [%d, %d), got [%d, %d)", tt.wantStart, tt.wantEnd, m.Start, m.End)
+			}
+
+			// A motion that may still be going on is not complete yet
+			completed := Completed(matches, len(tt.path))
+			if want := m.End < len(tt.path); (len(completed) == 1) != want {
+				t.Errorf("expected the match to be complete %v, got %+v", want, completed)
+			}
+		})
+	}
+}
+
+func TestDynamicMatcher_SpotsEachTemplateInItsOwnWindow(t *testing.T) {
+	left, right := swipeTemplates()
+	matcher := NewDynamicMatcher()
+	matcher.AddTemplate(left)
+	matcher.AddTemplate(right)
+
+	// Swipe right, pause, then swipe back left
+	path := newRecorder(0.3, 0.6).rest(10).move(0.7, 0.4, 12).rest(15).move(0.3, 0.6, 12).rest(11).path
+
+	windows := make(map[string][2]int)
+	for _, m := range matcher.Match(path) {
+		windows[m.Template.ID] = [2]int{m.Start, m.End}
+	}
+
+	if w, ok := windows["swipe-right"]; !ok || w[0] < 9 || w[1] > 23 {
+		t.Errorf("expected swipe right within [9, 23), got %v (matched %v)", w, ok)
+	}
+	if w, ok := windows["swipe-left"]; !ok || w[0] < 36 || w[1] > 50 {
+		t.Errorf("expected swipe left within [36, 50), got %v (matched %v)", w, ok)
+	}
+
+	// Matched against the whole path, neither would have been recognized
+	for _, template := range []*Template{left, right} {
+		if d := DTWDistance(normalizePath(path), normalizePath(template.Path)); d <= template.Tolerance {
+			t.Errorf("expected the whole path to be out of tolerance of %s, got %f", template.ID, d)
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
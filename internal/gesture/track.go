@@ -0,0 +1,295 @@
+package gesture
+
+import (
+	"math"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// Tracked is something a dynamic gesture follows along its path besides the
+// point recorded in PathPoint.X and Y: a point of the hand, the distance of the
+// hand from the camera, or the pose of the hand.
+type Tracked string
+
+const (
+	TrackWrist     Tracked = "wrist"
+	TrackPalm      Tracked = "palm" // Centroid of the wrist and the four finger knuckles
+	TrackThumbTip  Tracked = "thumb_tip"
+	TrackIndexTip  Tracked = "index_tip"
+	TrackMiddleTip Tracked = "middle_tip"
+	TrackRingTip   Tracked = "ring_tip"
+	TrackPinkyTip  Tracked = "pinky_tip"
+	// TrackDepth follows how far the hand moves toward or away from the camera,
+	// estimated from its apparent size relative to the start of the path.
+	TrackDepth Tracked = "depth"
+	// TrackPose follows the pose of the hand: how curled each finger is, how
+	// far apart the thumb and index fingertips are, which way the hand points
+	// in the image and which way the palm faces.
+	TrackPose Tracked = "pose"
+)
+
+// trackedLandmarks maps the points of the hand that can be followed to their landmarks.
+var trackedLandmarks = map[Tracked]int{
+	TrackWrist:     detector.Wrist,
+	TrackThumbTip:  detector.ThumbTip,
+	TrackIndexTip:  detector.IndexTip,
+	TrackMiddleTip: detector.MiddleTip,
+	TrackRingTip:   detector.RingTip,
+	TrackPinkyTip:  detector.PinkyTip,
+}
+
+// palmLandmarks are the landmarks TrackPalm is the centroid of.
+var palmLandmarks = []int{detector.Wrist, detector.IndexMCP, detector.MiddleMCP, detector.RingMCP, detector.PinkyMCP}
+
+// isPoint reports whether t is a point of the hand rather than a measurement of it.
+func (t Tracked) isPoint() bool {
+	_, ok := trackedLandmarks[t]
+	return ok || t == TrackPalm
+}
+
+// position returns where the point t lies in hand.
+func (t Tracked) position(hand []detector.Point3D) PathPoint {
+	if t == TrackPalm {
+		var p PathPoint
+		for _, i := range palmLandmarks {
+			p.X += hand[i].X
+			p.Y += hand[i].Y
+		}
+		p.X /= float64(len(palmLandmarks))
+		p.Y /= float64(len(palmLandmarks))
+		return p
+	}
+	l := hand[trackedLandmarks[t]]
+	return PathPoint{X: l.X, Y: l.Y}
+}
+
+// motion holds the feature vectors compared along a dynamic path, one per
+// point of the path. Each vector is split into groups, such as the position
+// of one followed point or the pose of the hand, and the distance between two
+// vectors is the sum of the Euclidean distances between their groups.
+type motion struct {
+	vectors [][]float64
+	groups  []int // Length of each group, in the order they appear in every vector
+}
+
+// cost returns the distance between the i-th vector of m and the j-th of other.
+// Both must have been built with the same PathOptions.
+func (m *motion) cost(i int, other *motion, j int) float64 {
+	a, b := m.vectors[i], other.vectors[j]
+	var total float64
+	k := 0
+	for _, size := range m.groups {
+		var sum float64
+		for end := k + size; k < end; k++ {
+			d := a[k] - b[k]
+			sum += d * d
+		}
+		total += math.Sqrt(sum)
+	}
+	return total
+}
+
+// add appends a group of values to the vector of every point, taking the
+// values of point i from group(i).
+func (m *motion) add(size int, group func(i int) []float64) {
+	for i := range m.vectors {
+		m.vectors[i] = append(m.vectors[i], group(i)...)
+	}
+	m.groups = append(m.groups, size)
+}
+
+// points returns the points of the hand o follows, in the order they were listed.
+func (o PathOptions) points() []Tracked {
+	var points []Tracked
+	for _, t := range o.Track {
+		if t.isPoint() {
+			points = append(points, t)
+		}
+	}
+	return points
+}
+
+// follows reports whether o follows t.
+func (o PathOptions) follows(t Tracked) bool {
+	for _, tracked := range o.Track {
+		if tracked == t {
+			return true
+		}
+	}
+	return false
+}
+
+// needsHand reports whether comparing paths as o selects needs the landmarks
+// of the hand at every point rather than only PathPoint.X and Y.
+func (o PathOptions) needsHand() bool {
+	return len(o.Track) > 0
+}
+
+// CanCompare reports whether path records everything comparing paths as o
+// selects needs, such as the landmarks of the hand at every point.
+// Paths recorded before a gesture followed more than X and Y do not.
+func (o PathOptions) CanCompare(path []PathPoint) bool {
+	_, ok := o.motion(path)
+	return ok
+}
+
+// tracks returns the path of every point o follows, or path itself if o
+// follows no point of the hand.
+// Returns false if a point of path lacks the landmarks of the hand.
+func (o PathOptions) tracks(path []PathPoint) ([][]PathPoint, bool) {
+	points := o.points()
+	if len(points) == 0 {
+		return [][]PathPoint{path}, true
+	}
+
+	tracks := make([][]PathPoint, len(points))
+	for k, t := range points {
+		tracks[k] = make([]PathPoint, len(path))
+		for i, p := range path {
+			if len(p.Hand) != detector.NumLandmarks {
+				return nil, false
+			}
+			tracks[k][i] = t.position(p.Hand)
+			tracks[k][i].Timestamp = p.Timestamp
+		}
+	}
+	return tracks, true
+}
+
+// motion builds the feature vectors compared along path as selected by o.
+// The points followed are normalized together so that they keep their places
+// relative to one another, and each counts for an equal share of the distance.
+// Returns false if o needs hand landmarks path does not have.
+func (o PathOptions) motion(path []PathPoint) (*motion, bool) {
+	if len(path) == 0 {
+		return &motion{}, true
+	}
+
+	tracks, ok := o.tracks(path)
+	if !ok {
+		return nil, false
+	}
+	if o.needsHand() {
+		for _, p := range path {
+			if len(p.Hand) != detector.NumLandmarks {
+				return nil, false
+			}
+		}
+	}
+
+	var joined []PathPoint
+	for _, track := range tracks {
+		joined = append(joined, track...)
+	}
+	normalized := o.normalize(joined)
+
+	m := &motion{vectors: make([][]float64, len(path))}
+	share := 1 / float64(len(tracks))
+
+	for k, track := range tracks {
+		positions := normalized[k*len(path) : (k+1)*len(path)]
+		m.add(2, func(i int) []float64 {
+			return []float64{positions[i].X * share, positions[i].Y * share}
+		})
+
+		var features []PathPoint
+		switch o.Features {
+		case PathDisplacement:
+			features = displacements(track)
+		case PathVelocity:
+			features = velocities(track)
+		default:
+			continue
+		}
+		m.add(2, func(i int) []float64 {
+			return []float64{features[i].X * share, features[i].Y * share}
+		})
+	}
+
+	if o.follows(TrackDepth) {
+		reference := handSize(path[0].Hand)
+		m.add(1, func(i int) []float64 {
+			return []float64{relativeDepth(handSize(path[i].Hand), reference)}
+		})
+	}
+
+	if o.follows(TrackPose) {
+		m.add(poseSize, func(i int) []float64 {
+			return poseVector(path[i].Hand)
+		})
+	}
+
+	return m, true
+}
+
+// extent returns how far the points o follows move along path: the larger
+// side of the bounding box of the point that moves most, or the range of its
+// depth if the hand moves further toward or away from the camera.
+// Returns 0 if o needs hand landmarks path does not have.
+func (o PathOptions) extent(path []PathPoint) float64 {
+	tracks, ok := o.tracks(path)
+	if !ok {
+		return 0
+	}
+
+	var extent float64
+	for _, track := range tracks {
+		extent = math.Max(extent, pathExtent(track))
+	}
+
+	if o.follows(TrackDepth) && len(path) > 0 && len(path[0].Hand) == detector.NumLandmarks {
+		reference := handSize(path[0].Hand)
+		minDepth, maxDepth := 0.0, 0.0
+		for _, p := range path {
+			if len(p.Hand) != detector.NumLandmarks {
+				return 0
+			}
+			d := relativeDepth(handSize(p.Hand), reference)
+			minDepth, maxDepth = math.Min(minDepth, d), math.Max(maxDepth, d)
+		}
+		extent = math.Max(extent, maxDepth-minDepth)
+	}
+	return extent
+}
+
+// handSize returns the distance from the wrist to the middle finger knuckle.
+func handSize(hand []detector.Point3D) float64 {
+	return hand[detector.MiddleMCP].Sub(hand[detector.Wrist]).Length()
+}
+
+// relativeDepth returns how much closer to the camera a hand of apparent size
+// is than one of size reference, as the logarithm of their ratio: positive
+// when closer, 0 if either size is unknown.
+func relativeDepth(size, reference float64) float64 {
+	if size <= 0 || reference <= 0 {
+		return 0
+	}
+	return math.Log(size / reference)
+}
+
+// poseSize is the length of the vectors returned by poseVector.
+const poseSize = NumFingers + 6
+
+// poseVector describes the pose of hand for comparison along a path: the curl
+// of each finger as a fraction of half a turn, the distance between the thumb
+// and index fingertips in hand sizes, the direction from the wrist to the
+// middle finger knuckle in the image, and the direction the palm faces.
+func poseVector(hand []detector.Point3D) []float64 {
+	v := make([]float64, 0, poseSize)
+
+	features, _ := ExtractFeatures(hand)
+	for _, curl := range features.Curl {
+		v = append(v, curl/math.Pi)
+	}
+
+	var pinch float64
+	if size := handSize(hand); size > 0 {
+		pinch = hand[detector.ThumbTip].Sub(hand[detector.IndexTip]).Length() / size
+	}
+
+	up := hand[detector.MiddleMCP].Sub(hand[detector.Wrist])
+	up.Z = 0
+	up, _ = up.Unit()
+
+	return append(v, pinch, up.X, up.Y, features.PalmNormal.X, features.PalmNormal.Y, features.PalmNormal.Z)
+}
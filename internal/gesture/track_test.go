@@ -0,0 +1,225 @@
+package gesture
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// pinchHand returns an open palm with the thumb tip brought to the index fingertip.
+func pinchHand() detector.HandLandmarks {
+	h := detector.OpenPalmLandmarks()
+	h.Points[detector.ThumbTip] = h.Points[detector.IndexTip]
+	mcp := h.Points[detector.ThumbMCP]
+	h.Points[detector.ThumbIP] = mcp.Add(h.Points[detector.IndexTip].Sub(mcp).Scale(0.5))
+	return h
+}
+
+// blendHands returns the pose a fraction t of the way from a to b.
+func blendHands(a, b detector.HandLandmarks, t float64) detector.HandLandmarks {
+	for i := range a.Points {
+		a.Points[i] = a.Points[i].Add(b.Points[i].Sub(a.Points[i]).Scale(t))
+	}
+	return a
+}
+
+// placeHand rotates h by angle in the image plane and scales it by scale
+// around its wrist, then moves its wrist to (x, y).
+func placeHand(h detector.HandLandmarks, x, y, scale, angle float64) detector.HandLandmarks {
+	h = tiltHand(h, angle)
+	wrist := h.Points[detector.Wrist]
+	for i, p := range h.Points {
+		h.Points[i] = detector.Point3D{X: x, Y: y}.Add(p.Sub(wrist).Scale(scale))
+	}
+	return h
+}
+
+// handPath records a hand over frames, one point per frame, with the index
+// fingertip as X and Y the way the pipeline buffers it. The hand at each frame
+// is given by at, from t = 0 at the first frame to 1 at the last.
+func handPath(frames int, at func(t float64) detector.HandLandmarks) []PathPoint {
+	path := make([]PathPoint, frames)
+	for i := range path {
+		h := at(float64(i) / float64(frames-1))
+		path[i] = PathPoint{
+			X:         h.Points[detector.IndexTip].X,
+			Y:         h.Points[detector.IndexTip].Y,
+			Timestamp: int64(i * frameMs),
+			Hand:      h.Points[:],
+		}
+	}
+	return path
+}
+
+// drag records hand moving from (0.3, 0.5) to (0.6, 0.4) over frames.
+func drag(hand detector.HandLandmarks, frames int) []PathPoint {
+	return handPath(frames, func(t float64) detector.HandLandmarks {
+		return placeHand(hand, 0.3+0.3*t, 0.5-0.1*t, 1, 0)
+	})
+}
+
+// grabThenMove records an open hand closing into a fist over the first half
+// of frames, then scaled from 1 to scale over the second half as if moved
+// toward or away from the camera.
+func grabThenMove(scale float64, frames int) []PathPoint {
+	open := detector.OpenPalmLandmarks()
+	fist := withFingers(open)
+	return handPath(frames, func(t float64) detector.HandLandmarks {
+		if t < 0.5 {
+			return placeHand(blendHands(open, fist, 2*t), 0.5, 0.7, 1, 0)
+		}
+		return placeHand(fist, 0.5, 0.7, 1+(scale-1)*(2*t-1), 0)
+	})
+}
+
+// rotateWrist records an open hand rolling by angle around its wrist over frames.
+func rotateWrist(angle float64, frames int) []PathPoint {
+	return handPath(frames, func(t float64) detector.HandLandmarks {
+		return placeHand(detector.OpenPalmLandmarks(), 0.5, 0.7, 1, angle*t)
+	})
+}
+
+func TestPathOptions_Track(t *testing.T) {
+	pinch := pinchHand()
+	open := detector.OpenPalmLandmarks()
+
+	tests := []struct {
+		name  string
+		opts  PathOptions
+		a, b  []PathPoint
+		apart bool
+	}{
+		{
+			name: "the index fingertip alone does not tell a pinch from an open hand",
+			opts: PathOptions{Normalization: NormalizeUniform},
+			a:    drag(pinch, 12), b: drag(open, 12),
+		},
+		{
+			name: "following the thumb too tells a pinch-and-drag from a drag",
+			opts: PathOptions{Normalization: NormalizeUniform, Track: []Tracked{TrackIndexTip, TrackThumbTip}},
+			a:    drag(pinch, 12), b: drag(open, 12), apart: true,
+		},
+		{
+			name: "a pinch-and-drag matches itself at another speed",
+			opts: PathOptions{Normalization: NormalizeUniform, Track: []Tracked{TrackIndexTip, TrackThumbTip}},
+			a:    drag(pinch, 12), b: drag(pinch, 20),
+		},
+		{
+			name: "depth tells pulling toward the camera from pushing away",
+			opts: PathOptions{Normalization: NormalizeUniform, Track: []Tracked{TrackPalm, TrackDepth, TrackPose}},
+			a:    grabThenMove(1.6, 20), b: grabThenMove(0.6, 20), apart: true,
+		},
+		{
+			name: "a grab then pull matches itself",
+			opts: PathOptions{Normalization: NormalizeUniform, Track: []Tracked{TrackPalm, TrackDepth, TrackPose}},
+			a:    grabThenMove(1.6, 20), b: grabThenMove(1.5, 16),
+		},
+		{
+			name: "pose tells the way the wrist rotates",
+			opts: PathOptions{Normalization: NormalizeCentered, Track: []Tracked{TrackWrist, TrackPose}},
+			a:    rotateWrist(math.Pi/2, 15), b: rotateWrist(-math.Pi/2, 15), apart: true,
+		},
+		{
+			name: "a wrist rotation matches itself",
+			opts: PathOptions{Normalization: NormalizeCentered, Track: []Tracked{TrackWrist, TrackPose}},
+			a:    rotateWrist(math.Pi/2, 15), b: rotateWrist(math.Pi/2*0.9, 12),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := tt.opts.distance(tt.a, tt.b)
+			if apart := d > 0.15; apart != tt.apart {
+				t.Errorf("expected apart %v, got distance %f", tt.apart, d)
+			}
+		})
+	}
+}
+
+func TestPathOptions_TrackNeedsHand(t *testing.T) {
+	withHand := drag(pinchHand(), 12)
+	withoutHand := newRecorder(0.3, 0.5).move(0.6, 0.4, 12).path
+
+	opts := PathOptions{Track: []Tracked{TrackPose}}
+	if d := opts.distance(withoutHand, withHand); !math.IsInf(d, 1) {
+		t.Errorf("expected an infinite distance for a path without hand landmarks, got %f", d)
+	}
+	if d := (PathOptions{}).distance(withoutHand, withHand); math.IsInf(d, 1) {
+		t.Error("expected X and Y to be compared without hand landmarks")
+	}
+}
+
+func TestDynamicMatcher_SpotsTrackedGesture(t *testing.T) {
+	pinch := pinchHand()
+	open := detector.OpenPalmLandmarks()
+	opts := PathOptions{Normalization: NormalizeUniform, Track: []Tracked{TrackIndexTip, TrackThumbTip}}
+
+	matcher := NewDynamicMatcher()
+	matcher.AddTemplate(&Template{ID: "pinch-drag", Type: TypeDynamic, Path: drag(pinch, 12), Dynamic: opts, Tolerance: 0.1})
+	// A template recorded without hand landmarks cannot be compared this way
+	matcher.AddTemplate(&Template{ID: "untracked", Type: TypeDynamic, Path: newRecorder(0.3, 0.5).move(0.6, 0.4, 12).path, Dynamic: opts, Tolerance: 1})
+
+	// Held still, then dragged pinching, then held again
+	rest := func(hand detector.HandLandmarks, x, y float64, frames int) []PathPoint {
+		return handPath(frames, func(float64) detector.HandLandmarks { return placeHand(hand, x, y, 1, 0) })
+	}
+	var path []PathPoint
+	path = append(path, rest(pinch, 0.3, 0.5, 10)...)
+	path = append(path, drag(pinch, 12)...)
+	path = append(path, rest(pinch, 0.6, 0.4, 10)...)
+	for i := range path {
+		path[i].Timestamp = int64(i * frameMs)
+	}
+
+	matches := matcher.Match(path)
+	if len(matches) != 1 || matches[0].Template.ID != "pinch-drag" {
+		t.Fatalf("expected only pinch-drag to match, got %+v", matches)
+	}
+	if m := matches[0]; m.Start < 8 || m.End > 24 {
+		t.Errorf("expected the drag within [8, 24), got [%d, %d)", m.Start, m.End)
+	}
+
+	// The same drag with an open hand is not a pinch-and-drag
+	if matches := matcher.Match(append(rest(open, 0.3, 0.5, 10), drag(open, 12)...)); len(matches) != 0 {
+		t.Errorf("expected an open-hand drag not to match, got %+v", matches)
+	}
+}
+
+func TestTrainer_DynamicHands(t *testing.T) {
+	sample := func(path []PathPoint) json.RawMessage {
+		data, err := json.Marshal(DynamicSample{Type: "dynamic", Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return data
+	}
+	samples := []json.RawMessage{sample(grabThenMove(1.6, 20)), sample(grabThenMove(1.4, 16))}
+
+	trainer := NewTrainer()
+	template, err := trainer.TrainDynamic(samples)
+	if err != nil {
+		t.Fatalf("TrainDynamic() error = %v", err)
+	}
+	for i, p := range template {
+		if len(p.Hand) != detector.NumLandmarks {
+			t.Fatalf("expected point %d of the template to average the hand landmarks, got %d", i, len(p.Hand))
+		}
+	}
+
+	opts := PathOptions{Normalization: NormalizeUniform, Track: []Tracked{TrackPalm, TrackDepth, TrackPose}}
+	diag, err := trainer.DiagnoseDynamicWith(samples, template, opts)
+	if err != nil {
+		t.Fatalf("DiagnoseDynamicWith() error = %v", err)
+	}
+	if diag.MaxDeviation > 0.15 {
+		t.Errorf("expected the samples close to their template, got %v", diag.Deviations)
+	}
+
+	// Samples recorded without the hand cannot be compared by it
+	untracked := append(samples, sample(newRecorder(0.5, 0.7).rest(10).path))
+	if _, err := trainer.DiagnoseDynamicWith(untracked, template, opts); err == nil {
+		t.Error("expected an error for a sample without hand landmarks")
+	}
+}
@@ -3,6 +3,7 @@ package gesture
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 
 	"github.com/ayusman/kuchipudi/internal/detector"
 )
@@ -17,9 +18,10 @@ func NewTrainer() *Trainer {
 
 // StaticSample represents a recorded static gesture sample.
 type StaticSample struct {
-	Type      string             `json:"type"`
-	Landmarks []detector.Point3D `json:"landmarks"`
-	Timestamp int64              `json:"timestamp"`
+	Type       string             `json:"type"`
+	Handedness string             `json:"handedness,omitempty"` // "Left" or "Right", empty if unknown
+	Landmarks  []detector.Point3D `json:"landmarks"`
+	Timestamp  int64              `json:"timestamp"`
 }
 
 // DynamicSample represents a recorded dynamic gesture sample.
@@ -29,77 +31,261 @@ type DynamicSample struct {
 	Timestamp int64       `json:"timestamp"`
 }
 
-// TrainStatic averages multiple static landmark samples into a single template.
-// Returns the averaged landmarks suitable for gesture matching.
+// TwoHandSample represents a recorded sample of a gesture made with both hands.
+type TwoHandSample struct {
+	Type      string       `json:"type"`
+	Hands     []SampleHand `json:"hands"`
+	Timestamp int64        `json:"timestamp"`
+}
+
+// SampleHand is one hand of a TwoHandSample.
+type SampleHand struct {
+	Handedness string             `json:"handedness"` // "Left" or "Right"
+	Landmarks  []detector.Point3D `json:"landmarks"`
+}
+
+// Diagnostics summarizes how closely the training samples agree with the trained template.
+// Deviations holds one entry per sample, in the order the samples were given.
+type Diagnostics struct {
+	SampleCount   int       `json:"sample_count"`
+	Deviations    []float64 `json:"deviations"`
+	MeanDeviation float64   `json:"mean_deviation"`
+	MaxDeviation  float64   `json:"max_deviation"`
+}
+
+// TrainStatic averages multiple static landmark samples into a single template
+// using the default StaticOptions.
 func (t *Trainer) TrainStatic(samples []json.RawMessage) ([]detector.Point3D, error) {
+	return t.TrainStaticWith(samples, StaticOptions{})
+}
+
+// TrainStaticWith averages multiple static landmark samples into a single template.
+// Samples containing a full hand are normalized first so that the template
+// lives in the same coordinate space as StaticMatcher input, and rotated into
+// canonical orientation if opts ask for rotation invariance.
+// Samples recorded with the other hand than most are mirrored before averaging;
+// StaticHandedness reports which hand the template then represents.
+// Returns the averaged landmarks suitable for gesture matching.
+func (t *Trainer) TrainStaticWith(samples []json.RawMessage, opts StaticOptions) ([]detector.Point3D, error) {
+	allLandmarks, _, err := parseStaticSamples(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.RotationInvariant {
+		for i, landmarks := range allLandmarks {
+			allLandmarks[i] = detector.CanonicalOrientation(landmarks)
+		}
+	}
+
+	// Average landmarks across all samples
+	numPoints := len(allLandmarks[0])
+	averaged := make([]detector.Point3D, numPoints)
+	n := float64(len(allLandmarks))
+
+	for i := 0; i < numPoints; i++ {
+		var sumX, sumY, sumZ float64
+		for _, landmarks := range allLandmarks {
+			sumX += landmarks[i].X
+			sumY += landmarks[i].Y
+			sumZ += landmarks[i].Z
+		}
+		averaged[i] = detector.Point3D{
+			X: sumX / n,
+			Y: sumY / n,
+			Z: sumZ / n,
+		}
+	}
+
+	return averaged, nil
+}
+
+// StaticHandedness returns the handedness label of the hand a template trained
+// from samples represents: the hand most samples were recorded with.
+// Returns "" if the samples do not record their handedness.
+func (t *Trainer) StaticHandedness(samples []json.RawMessage) (string, error) {
+	_, handedness, err := parseStaticSamples(samples)
+	return handedness, err
+}
+
+// DiagnoseStatic measures the distance of every static sample from the trained template
+// using the default StaticOptions.
+func (t *Trainer) DiagnoseStatic(samples []json.RawMessage, template []detector.Point3D) (*Diagnostics, error) {
+	return t.DiagnoseStaticWith(samples, template, StaticOptions{})
+}
+
+// DiagnoseStaticWith measures the distance of every static sample from the
+// trained template, the same way StaticMatcher compares them under opts.
+func (t *Trainer) DiagnoseStaticWith(samples []json.RawMessage, template []detector.Point3D, opts StaticOptions) (*Diagnostics, error) {
+	allLandmarks, _, err := parseStaticSamples(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	deviations := make([]float64, len(allLandmarks))
+	for i, landmarks := range allLandmarks {
+		deviations[i] = opts.distance(landmarks, template)
+	}
+
+	return newDiagnostics(deviations), nil
+}
+
+// parseStaticSamples decodes static samples and checks they share the same shape.
+// Full 21-point hands are normalized relative to the wrist and hand size, and
+// mirrored if they were recorded with the other hand than most samples.
+// Also returns the handedness label of the majority, "" if unknown.
+func parseStaticSamples(samples []json.RawMessage) ([][]detector.Point3D, string, error) {
 	if len(samples) == 0 {
-		return nil, fmt.Errorf("no samples provided")
+		return nil, "", fmt.Errorf("no samples provided")
 	}
 
-	// Parse all samples
 	var allLandmarks [][]detector.Point3D
+	labels := make([]string, len(samples))
 	for i, raw := range samples {
 		var sample StaticSample
 		if err := json.Unmarshal(raw, &sample); err != nil {
-			return nil, fmt.Errorf("failed to parse sample %d: %w", i, err)
+			return nil, "", fmt.Errorf("failed to parse sample %d: %w", i, err)
 		}
 
 		if len(sample.Landmarks) == 0 {
-			return nil, fmt.Errorf("sample %d has no landmarks", i)
+			return nil, "", fmt.Errorf("sample %d has no landmarks", i)
 		}
 
-		allLandmarks = append(allLandmarks, sample.Landmarks)
+		allLandmarks = append(allLandmarks, normalizeSampleLandmarks(sample.Landmarks))
+		labels[i] = sample.Handedness
 	}
 
 	// Verify all samples have the same number of landmarks
 	numPoints := len(allLandmarks[0])
 	for i, landmarks := range allLandmarks {
 		if len(landmarks) != numPoints {
-			return nil, fmt.Errorf("sample %d has %d landmarks, expected %d", i, len(landmarks), numPoints)
+			return nil, "", fmt.Errorf("sample %d has %d landmarks, expected %d", i, len(landmarks), numPoints)
 		}
 	}
 
-	// Average landmarks across all samples
-	averaged := make([]detector.Point3D, numPoints)
-	n := float64(len(allLandmarks))
+	// Bring samples of the other hand into the majority's frame. Only full hands
+	// are normalized around the wrist, so only those can be mirrored.
+	handedness := majorityHandedness(labels)
+	if numPoints == detector.NumLandmarks {
+		for i, landmarks := range allLandmarks {
+			if needsMirror(handedness, labels[i]) {
+				allLandmarks[i] = mirrorX(landmarks)
+			}
+		}
+	}
 
-	for i := 0; i < numPoints; i++ {
-		var sumX, sumY, sumZ float64
-		for _, landmarks := range allLandmarks {
-			sumX += landmarks[i].X
-			sumY += landmarks[i].Y
-			sumZ += landmarks[i].Z
+	return allLandmarks, handedness, nil
+}
+
+// normalizeSampleLandmarks applies HandLandmarks.Normalize to a full hand.
+// Partial landmark sets are returned unchanged.
+func normalizeSampleLandmarks(landmarks []detector.Point3D) []detector.Point3D {
+	if len(landmarks) != detector.NumLandmarks {
+		return landmarks
+	}
+
+	var hand detector.HandLandmarks
+	copy(hand.Points[:], landmarks)
+	normalized := hand.Normalize()
+	return normalized.Points[:]
+}
+
+// TrainTwoHand averages multiple two-hand samples into a single template.
+// The hands of each sample are paired the same way TwoHandMatcher pairs detected hands.
+func (t *Trainer) TrainTwoHand(samples []json.RawMessage) (*HandPair, error) {
+	pairs, err := parseTwoHandSamples(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	n := float64(len(pairs))
+	averaged := &HandPair{
+		Left:  make([]detector.Point3D, detector.NumLandmarks),
+		Right: make([]detector.Point3D, detector.NumLandmarks),
+	}
+
+	var logScale float64
+	for _, pair := range pairs {
+		for i := 0; i < detector.NumLandmarks; i++ {
+			averaged.Left[i] = addPoint(averaged.Left[i], pair.Left[i], 1/n)
+			averaged.Right[i] = addPoint(averaged.Right[i], pair.Right[i], 1/n)
 		}
-		averaged[i] = detector.Point3D{
-			X: sumX / n,
-			Y: sumY / n,
-			Z: sumZ / n,
+		averaged.Offset = addPoint(averaged.Offset, pair.Offset, 1/n)
+		if pair.Scale > 0 {
+			logScale += math.Log(pair.Scale) / n
 		}
 	}
 
+	// Scales are ratios, so they are averaged geometrically
+	averaged.Scale = math.Exp(logScale)
+
 	return averaged, nil
 }
 
-// TrainDynamic averages multiple dynamic path samples into a single template path.
-// Uses resampling to align paths of different lengths before averaging.
-func (t *Trainer) TrainDynamic(samples []json.RawMessage) ([]PathPoint, error) {
+// DiagnoseTwoHand measures the distance of every two-hand sample from the trained template.
+func (t *Trainer) DiagnoseTwoHand(samples []json.RawMessage, template *HandPair) (*Diagnostics, error) {
+	pairs, err := parseTwoHandSamples(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	deviations := make([]float64, len(pairs))
+	for i, pair := range pairs {
+		deviations[i] = pairDistance(pair, template)
+	}
+
+	return newDiagnostics(deviations), nil
+}
+
+// parseTwoHandSamples decodes two-hand samples into hand pairs.
+// Every sample must contain at least two full 21-point hands.
+func parseTwoHandSamples(samples []json.RawMessage) ([]*HandPair, error) {
 	if len(samples) == 0 {
 		return nil, fmt.Errorf("no samples provided")
 	}
 
-	// Parse all samples
-	var allPaths [][]PathPoint
+	var pairs []*HandPair
 	for i, raw := range samples {
-		var sample DynamicSample
+		var sample TwoHandSample
 		if err := json.Unmarshal(raw, &sample); err != nil {
 			return nil, fmt.Errorf("failed to parse sample %d: %w", i, err)
 		}
 
-		if len(sample.Path) < 2 {
-			return nil, fmt.Errorf("sample %d has insufficient path points", i)
+		if len(sample.Hands) < 2 {
+			return nil, fmt.Errorf("sample %d has %d hands, expected 2", i, len(sample.Hands))
 		}
 
-		allPaths = append(allPaths, sample.Path)
+		hands := make([]detector.HandLandmarks, len(sample.Hands))
+		for j, h := range sample.Hands {
+			if len(h.Landmarks) != detector.NumLandmarks {
+				return nil, fmt.Errorf("sample %d hand %d has %d landmarks, expected %d",
+					i, j, len(h.Landmarks), detector.NumLandmarks)
+			}
+			hands[j].Handedness = h.Handedness
+			copy(hands[j].Points[:], h.Landmarks)
+		}
+
+		pairs = append(pairs, NewHandPair(PairHands(hands)))
+	}
+
+	return pairs, nil
+}
+
+// addPoint returns p plus q scaled by weight.
+func addPoint(p, q detector.Point3D, weight float64) detector.Point3D {
+	return detector.Point3D{
+		X: p.X + q.X*weight,
+		Y: p.Y + q.Y*weight,
+		Z: p.Z + q.Z*weight,
+	}
+}
+
+// TrainDynamic averages multiple dynamic path samples into a single template path.
+// Uses resampling to align paths of different lengths before averaging.
+func (t *Trainer) TrainDynamic(samples []json.RawMessage) ([]PathPoint, error) {
+	allPaths, err := parseDynamicSamples(samples)
+	if err != nil {
+		return nil, err
 	}
 
 	// Use the first path as reference length
@@ -108,34 +294,128 @@ func (t *Trainer) TrainDynamic(samples []json.RawMessage) ([]PathPoint, error) {
 	// Resample all paths to the same length and average
 	averaged := make([]PathPoint, targetLength)
 
+	// Resample all paths to match target length
+	resampledPaths := make([][]PathPoint, len(allPaths))
+	for pathIdx, path := range allPaths {
+		resampledPaths[pathIdx] = resamplePath(path, targetLength)
+	}
+
 	for i := 0; i < targetLength; i++ {
 		var sumX, sumY float64
-		var refTimestamp int64
-
-		for pathIdx, path := range allPaths {
-			// Resample path to match target length
-			resampled := resamplePath(path, targetLength)
+		var hands [][]detector.Point3D
 
+		for _, resampled := range resampledPaths {
 			sumX += resampled[i].X
 			sumY += resampled[i].Y
-
-			// Use timestamp from first path as reference
-			if pathIdx == 0 {
-				refTimestamp = resampled[i].Timestamp
-			}
+			hands = append(hands, resampled[i].Hand)
 		}
 
 		n := float64(len(allPaths))
 		averaged[i] = PathPoint{
 			X:         sumX / n,
 			Y:         sumY / n,
-			Timestamp: refTimestamp,
+			Timestamp: resampledPaths[0][i].Timestamp, // Use timestamp from first path as reference
+			Hand:      averageHands(hands),
 		}
 	}
 
 	return averaged, nil
 }
 
+// averageHands averages the landmarks of hands, or returns nil unless every
+// hand has all NumLandmarks landmarks.
+func averageHands(hands [][]detector.Point3D) []detector.Point3D {
+	for _, hand := range hands {
+		if len(hand) != detector.NumLandmarks {
+			return nil
+		}
+	}
+
+	averaged := make([]detector.Point3D, detector.NumLandmarks)
+	weight := 1 / float64(len(hands))
+	for _, hand := range hands {
+		for j, p := range hand {
+			averaged[j] = addPoint(averaged[j], p, weight)
+		}
+	}
+	return averaged
+}
+
+// DiagnoseDynamic measures the DTW distance of every dynamic sample from the trained template.
+// Paths are normalized the same way DynamicMatcher normalizes them by default.
+func (t *Trainer) DiagnoseDynamic(samples []json.RawMessage, template []PathPoint) (*Diagnostics, error) {
+	return t.DiagnoseDynamicWith(samples, template, PathOptions{})
+}
+
+// DiagnoseDynamicWith measures the DTW distance of every dynamic sample from
+// the trained template, comparing paths as selected by opts.
+func (t *Trainer) DiagnoseDynamicWith(samples []json.RawMessage, template []PathPoint, opts PathOptions) (*Diagnostics, error) {
+	allPaths, err := parseDynamicSamples(samples)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := opts.motion(template); !ok {
+		return nil, fmt.Errorf("template does not record the hand landmarks the gesture follows")
+	}
+
+	deviations := make([]float64, len(allPaths))
+	for i, path := range allPaths {
+		if _, ok := opts.motion(path); !ok {
+			return nil, fmt.Errorf("sample %d does not record the hand landmarks the gesture follows", i)
+		}
+		deviations[i] = opts.distance(path, template)
+	}
+
+	return newDiagnostics(deviations), nil
+}
+
+// parseDynamicSamples decodes dynamic samples and checks each has a usable path.
+func parseDynamicSamples(samples []json.RawMessage) ([][]PathPoint, error) {
+	if len(samples) == 0 {
+		return nil, fmt.Errorf("no samples provided")
+	}
+
+	var allPaths [][]PathPoint
+	for i, raw := range samples {
+		var sample DynamicSample
+		if err := json.Unmarshal(raw, &sample); err != nil {
+			return nil, fmt.Errorf("failed to parse sample %d: %w", i, err)
+		}
+
+		if len(sample.Path) < 2 {
+			return nil, fmt.Errorf("sample %d has insufficient path points", i)
+		}
+
+		allPaths = append(allPaths, sample.Path)
+	}
+
+	return allPaths, nil
+}
+
+// newDiagnostics builds a Diagnostics summary from per-sample deviations.
+func newDiagnostics(deviations []float64) *Diagnostics {
+	d := &Diagnostics{
+		SampleCount: len(deviations),
+		Deviations:  deviations,
+	}
+
+	if len(deviations) == 0 {
+		return d
+	}
+
+	var sum float64
+	for _, dev := range deviations {
+		sum += dev
+		if dev > d.MaxDeviation {
+			d.MaxDeviation = dev
+		}
+	}
+	d.MeanDeviation = sum / float64(len(deviations))
+
+	return d
+}
+
 // resamplePath resamples a path to have exactly targetLength points.
 // Uses linear interpolation for smooth resampling.
 func resamplePath(path []PathPoint, targetLength int) []PathPoint {
@@ -172,6 +452,12 @@ func resamplePath(path []PathPoint, targetLength int) []PathPoint {
 			Y:         p1.Y + frac*(p2.Y-p1.Y),
 			Timestamp: p1.Timestamp + int64(frac*float64(p2.Timestamp-p1.Timestamp)),
 		}
+		if len(p1.Hand) == detector.NumLandmarks && len(p2.Hand) == detector.NumLandmarks {
+			result[i].Hand = make([]detector.Point3D, detector.NumLandmarks)
+			for j := range result[i].Hand {
+				result[i].Hand[j] = addPoint(p1.Hand[j], p2.Hand[j].Sub(p1.Hand[j]), frac)
+			}
+		}
 	}
 
 	return result
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"math"
 	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
 )
 
 func TestTrainer_TrainStatic(t *testing.T) {
@@ -230,3 +232,145 @@ func TestResamplePath_SinglePoint(t *testing.T) {
 func floatEqual(a, b float64) bool {
 	return math.Abs(a-b) < 1e-9
 }
+
+func TestTrainer_TrainStatic_NormalizesFullHand(t *testing.T) {
+	trainer := NewTrainer()
+
+	hand := detector.ThumbsUpLandmarks()
+	raw, _ := json.Marshal(StaticSample{Type: "static", Landmarks: hand.Points[:]})
+
+	result, err := trainer.TrainStatic([]json.RawMessage{raw})
+	if err != nil {
+		t.Fatalf("TrainStatic() error = %v", err)
+	}
+
+	// A full hand should be stored in matcher space (wrist at origin)
+	if !floatEqual(result[detector.Wrist].X, 0) || !floatEqual(result[detector.Wrist].Y, 0) {
+		t.Errorf("wrist not at origin: %+v", result[detector.Wrist])
+	}
+
+	matcher := NewStaticMatcher()
+	matcher.AddTemplate(&Template{ID: "t", Type: TypeStatic, Landmarks: result, Tolerance: 0.1})
+	if matches := matcher.Match(&hand); len(matches) != 1 {
+		t.Errorf("trained template should match its own sample, got %d matches", len(matches))
+	}
+}
+
+func TestTrainer_TrainStatic_MirrorsOtherHand(t *testing.T) {
+	trainer := NewTrainer()
+
+	right := detector.ThumbsUpLandmarks()
+	left := mirrorHand(right, HandednessLeft)
+
+	var samples []json.RawMessage
+	for _, hand := range []detector.HandLandmarks{right, left, right} {
+		raw, _ := json.Marshal(StaticSample{Type: "static", Handedness: hand.Handedness, Landmarks: hand.Points[:]})
+		samples = append(samples, raw)
+	}
+
+	handedness, err := trainer.StaticHandedness(samples)
+	if err != nil {
+		t.Fatalf("StaticHandedness() error = %v", err)
+	}
+	if handedness != HandednessRight {
+		t.Errorf("expected the majority hand %q, got %q", HandednessRight, handedness)
+	}
+
+	// The left-hand sample is mirrored, so all samples agree with the template
+	result, err := trainer.TrainStatic(samples)
+	if err != nil {
+		t.Fatalf("TrainStatic() error = %v", err)
+	}
+	diag, err := trainer.DiagnoseStatic(samples, result)
+	if err != nil {
+		t.Fatalf("DiagnoseStatic() error = %v", err)
+	}
+	if diag.MaxDeviation > 1e-9 {
+		t.Errorf("expected mirrored samples to agree, got max deviation %f", diag.MaxDeviation)
+	}
+}
+
+func TestTrainer_TrainStaticWith_RotationInvariant(t *testing.T) {
+	trainer := NewTrainer()
+
+	hand := detector.ThumbsUpLandmarks()
+	var samples []json.RawMessage
+	for _, degrees := range []float64{-15, 0, 15} {
+		tilted := tiltHand(hand, degrees*math.Pi/180)
+		raw, _ := json.Marshal(StaticSample{Type: "static", Landmarks: tilted.Points[:]})
+		samples = append(samples, raw)
+	}
+
+	opts := StaticOptions{RotationInvariant: true}
+	result, err := trainer.TrainStaticWith(samples, opts)
+	if err != nil {
+		t.Fatalf("TrainStaticWith() error = %v", err)
+	}
+
+	// Tilted samples agree once rotation is ignored, but not otherwise
+	diag, err := trainer.DiagnoseStaticWith(samples, result, opts)
+	if err != nil {
+		t.Fatalf("DiagnoseStaticWith() error = %v", err)
+	}
+	if diag.MaxDeviation > 1e-9 {
+		t.Errorf("expected tilted samples to agree, got max deviation %f", diag.MaxDeviation)
+	}
+
+	plain, err := trainer.DiagnoseStatic(samples, result)
+	if err != nil {
+		t.Fatalf("DiagnoseStatic() error = %v", err)
+	}
+	if plain.MaxDeviation < 0.1 {
+		t.Errorf("expected tilted samples to deviate without rotation invariance, got %f", plain.MaxDeviation)
+	}
+}
+
+func TestTrainer_DiagnoseStatic(t *testing.T) {
+	trainer := NewTrainer()
+
+	samples := []json.RawMessage{
+		json.RawMessage(`{"type": "static", "landmarks": [{"x": 0.5, "y": 0.5, "z": 0}], "timestamp": 1000}`),
+		json.RawMessage(`{"type": "static", "landmarks": [{"x": 0.7, "y": 0.5, "z": 0}], "timestamp": 2000}`),
+	}
+
+	template, err := trainer.TrainStatic(samples)
+	if err != nil {
+		t.Fatalf("TrainStatic() error = %v", err)
+	}
+
+	diag, err := trainer.DiagnoseStatic(samples, template)
+	if err != nil {
+		t.Fatalf("DiagnoseStatic() error = %v", err)
+	}
+
+	if diag.SampleCount != 2 || len(diag.Deviations) != 2 {
+		t.Fatalf("expected 2 deviations, got %+v", diag)
+	}
+	// Each sample is 0.1 away from the mean (0.6, 0.5)
+	if !floatEqual(diag.Deviations[0], 0.1) || !floatEqual(diag.MeanDeviation, 0.1) || !floatEqual(diag.MaxDeviation, 0.1) {
+		t.Errorf("unexpected diagnostics: %+v", diag)
+	}
+}
+
+func TestTrainer_DiagnoseDynamic(t *testing.T) {
+	trainer := NewTrainer()
+
+	samples := []json.RawMessage{
+		json.RawMessage(`{"type": "dynamic", "path": [{"x": 0, "y": 0, "timestamp": 0}, {"x": 1, "y": 1, "timestamp": 100}], "timestamp": 1000}`),
+		json.RawMessage(`{"type": "dynamic", "path": [{"x": 0, "y": 0, "timestamp": 0}, {"x": 1, "y": 1, "timestamp": 100}], "timestamp": 2000}`),
+	}
+
+	template, err := trainer.TrainDynamic(samples)
+	if err != nil {
+		t.Fatalf("TrainDynamic() error = %v", err)
+	}
+
+	diag, err := trainer.DiagnoseDynamic(samples, template)
+	if err != nil {
+		t.Fatalf("DiagnoseDynamic() error = %v", err)
+	}
+
+	if diag.MaxDeviation != 0 {
+		t.Errorf("identical samples should have zero deviation, got %+v", diag)
+	}
+}
@@ -0,0 +1,183 @@
+package gesture
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// Handedness labels reported by the detector.
+const (
+	HandednessLeft  = "Left"
+	HandednessRight = "Right"
+)
+
+// pairGeometryWeight scales the difference in hand placement when comparing two
+// hand pairs. Moving one of the two hands by d changes the averaged landmark
+// distance by NumLandmarks*d/2, so placement counts as much as the pose itself.
+const pairGeometryWeight = detector.NumLandmarks / 2.0
+
+// HandPair describes a pose made with both hands: each hand's normalized
+// landmarks, and where the right hand sits relative to the left.
+type HandPair struct {
+	Left   []detector.Point3D // Normalized landmarks of the left hand
+	Right  []detector.Point3D // Normalized landmarks of the right hand
+	Offset detector.Point3D   // Right wrist minus left wrist, in left hand sizes
+	Scale  float64            // Right hand size divided by left hand size
+}
+
+// PairHands picks the left and right hand from the detected hands.
+// Hands are paired by their handedness label. When the labels do not tell the
+// hands apart, the hand further to the left of the image is taken as the right
+// hand, as seen by a camera facing the user.
+// Returns nil, nil if fewer than two hands were detected.
+func PairHands(hands []detector.HandLandmarks) (left, right *detector.HandLandmarks) {
+	if len(hands) < 2 {
+		return nil, nil
+	}
+
+	for i := range hands {
+		switch hands[i].Handedness {
+		case HandednessLeft:
+			if left == nil {
+				left = &hands[i]
+			}
+		case HandednessRight:
+			if right == nil {
+				right = &hands[i]
+			}
+		}
+	}
+	if left != nil && right != nil {
+		return left, right
+	}
+
+	// Fall back to the image position of the first two hands
+	a, b := &hands[0], &hands[1]
+	if a.Points[detector.Wrist].X < b.Points[detector.Wrist].X {
+		return b, a
+	}
+	return a, b
+}
+
+// NewHandPair builds the two-hand features of a left and right hand.
+// Returns nil if either hand is missing.
+func NewHandPair(left, right *detector.HandLandmarks) *HandPair {
+	if left == nil || right == nil {
+		return nil
+	}
+
+	pair := &HandPair{
+		Left:  left.Normalize().Points[:],
+		Right: right.Normalize().Points[:],
+		Scale: 1,
+	}
+
+	leftSize := left.Size()
+	if leftSize < 1e-10 {
+		return pair
+	}
+
+	lw, rw := left.Points[detector.Wrist], right.Points[detector.Wrist]
+	pair.Offset = detector.Point3D{
+		X: (rw.X - lw.X) / leftSize,
+		Y: (rw.Y - lw.Y) / leftSize,
+		Z: (rw.Z - lw.Z) / leftSize,
+	}
+	pair.Scale = right.Size() / leftSize
+
+	return pair
+}
+
+// pairDistance compares two hand pairs. It is the mean landmark distance of the
+// two hands plus the weighted difference in their placement and relative size.
+func pairDistance(a, b *HandPair) float64 {
+	poses := (euclideanDistance(a.Left, b.Left) + euclideanDistance(a.Right, b.Right)) / 2
+
+	dx := a.Offset.X - b.Offset.X
+	dy := a.Offset.Y - b.Offset.Y
+	dz := a.Offset.Z - b.Offset.Z
+	offset := math.Sqrt(dx*dx + dy*dy + dz*dz)
+
+	var scale float64
+	if a.Scale > 0 && b.Scale > 0 {
+		scale = math.Abs(math.Log(a.Scale / b.Scale))
+	}
+
+	return poses + pairGeometryWeight*(offset+scale)
+}
+
+// TwoHandMatcher matches poses made with both hands against registered templates.
+// Templates may be added or removed while Match is running on another goroutine.
+type TwoHandMatcher struct {
+	mu        sync.RWMutex
+	templates []*Template
+}
+
+// NewTwoHandMatcher creates a new TwoHandMatcher instance.
+func NewTwoHandMatcher() *TwoHandMatcher {
+	return &TwoHandMatcher{
+		templates: make([]*Template, 0),
+	}
+}
+
+// AddTemplate adds a gesture template to the matcher.
+// A template with the same ID is replaced.
+func (m *TwoHandMatcher) AddTemplate(t *Template) {
+	if t == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = upsertTemplate(m.templates, t)
+}
+
+// RemoveTemplate removes a template by its ID.
+func (m *TwoHandMatcher) RemoveTemplate(id string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.templates = removeTemplate(m.templates, id)
+}
+
+// snapshot returns the current template list.
+// The returned slice is never modified, so it can be read without holding the lock.
+func (m *TwoHandMatcher) snapshot() []*Template {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.templates
+}
+
+// Match finds two-hand templates matching the given left and right hands.
+// Returns matches sorted by score in descending order (best matches first).
+func (m *TwoHandMatcher) Match(left, right *detector.HandLandmarks) []Match {
+	input := NewHandPair(left, right)
+	if input == nil {
+		return nil
+	}
+
+	var matches []Match
+	for _, template := range m.snapshot() {
+		// Skip templates that have not been trained yet
+		if template.Type != TypeTwoHand || template.Hands == nil {
+			continue
+		}
+
+		distance := pairDistance(input, template.Hands)
+		if distance <= template.Tolerance {
+			matches = append(matches, Match{
+				Template: template,
+				Score:    1.0 / (1.0 + distance),
+				Distance: distance,
+			})
+		}
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return matches[i].Score > matches[j].Score
+	})
+
+	return matches
+}
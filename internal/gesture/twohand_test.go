@@ -0,0 +1,137 @@
+package gesture
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+)
+
+// shiftHand returns h with every landmark moved by dx, dy and labelled with handedness.
+func shiftHand(h detector.HandLandmarks, handedness string, dx, dy float64) detector.HandLandmarks {
+	h.Handedness = handedness
+	for i := range h.Points {
+		h.Points[i].X += dx
+		h.Points[i].Y += dy
+	}
+	return h
+}
+
+func TestPairHands_ByHandedness(t *testing.T) {
+	right := shiftHand(detector.OpenPalmLandmarks(), HandednessRight, -0.2, 0)
+	left := shiftHand(detector.ThumbsUpLandmarks(), HandednessLeft, 0.2, 0)
+
+	// Labels win over image position, whatever order the hands were detected in
+	hands := []detector.HandLandmarks{right, left}
+	gotLeft, gotRight := PairHands(hands)
+	if gotLeft != &hands[1] || gotRight != &hands[0] {
+		t.Errorf("expected hands to be paired by their handedness label")
+	}
+}
+
+func TestPairHands_FallbackToPosition(t *testing.T) {
+	// Both hands labelled alike, so the hand on the image's left is the right hand
+	a := shiftHand(detector.OpenPalmLandmarks(), HandednessRight, 0.2, 0)
+	b := shiftHand(detector.OpenPalmLandmarks(), HandednessRight, -0.2, 0)
+
+	hands := []detector.HandLandmarks{a, b}
+	gotLeft, gotRight := PairHands(hands)
+	if gotLeft != &hands[0] || gotRight != &hands[1] {
+		t.Errorf("expected hands to be paired by wrist position")
+	}
+
+	if l, r := PairHands(hands[:1]); l != nil || r != nil {
+		t.Errorf("expected no pair from a single hand")
+	}
+}
+
+func TestTwoHandMatcher_Match(t *testing.T) {
+	left := shiftHand(detector.OpenPalmLandmarks(), HandednessLeft, 0.2, 0)
+	right := shiftHand(detector.ThumbsUpLandmarks(), HandednessRight, -0.2, 0)
+
+	matcher := NewTwoHandMatcher()
+	matcher.AddTemplate(&Template{
+		ID:        "pair",
+		Name:      "Pair",
+		Type:      TypeTwoHand,
+		Hands:     NewHandPair(&left, &right),
+		Tolerance: 0.5,
+	})
+	// Untrained templates are skipped
+	matcher.AddTemplate(&Template{ID: "untrained", Type: TypeTwoHand, Tolerance: 100})
+
+	matches := matcher.Match(&left, &right)
+	if len(matches) != 1 || matches[0].Template.ID != "pair" {
+		t.Fatalf("expected the pair template to match, got %+v", matches)
+	}
+	if matches[0].Distance > 1e-9 {
+		t.Errorf("expected zero distance for identical hands, got %f", matches[0].Distance)
+	}
+
+	// The same two poses held further apart are a different gesture
+	apart := shiftHand(right, HandednessRight, -0.3, 0)
+	if matches := matcher.Match(&left, &apart); len(matches) != 0 {
+		t.Errorf("expected no match when the hands move apart, got %+v", matches)
+	}
+
+	// Swapping the poses between hands does not match either
+	swappedLeft := shiftHand(detector.ThumbsUpLandmarks(), HandednessLeft, 0.2, 0)
+	swappedRight := shiftHand(detector.OpenPalmLandmarks(), HandednessRight, -0.2, 0)
+	if matches := matcher.Match(&swappedLeft, &swappedRight); len(matches) != 0 {
+		t.Errorf("expected no match with the poses swapped, got %+v", matches)
+	}
+
+	if matches := matcher.Match(&left, nil); matches != nil {
+		t.Errorf("expected no matches without a right hand, got %+v", matches)
+	}
+
+	matcher.RemoveTemplate("pair")
+	if matches := matcher.Match(&left, &right); len(matches) != 0 {
+		t.Errorf("expected no matches after removing the template, got %+v", matches)
+	}
+}
+
+func TestTrainer_TrainTwoHand(t *testing.T) {
+	left := shiftHand(detector.OpenPalmLandmarks(), HandednessLeft, 0.2, 0)
+	right := shiftHand(detector.ThumbsUpLandmarks(), HandednessRight, -0.2, 0)
+	want := NewHandPair(&left, &right)
+
+	sample := TwoHandSample{
+		Type: "two_hand",
+		Hands: []SampleHand{
+			{Handedness: HandednessRight, Landmarks: right.Points[:]},
+			{Handedness: HandednessLeft, Landmarks: left.Points[:]},
+		},
+	}
+	data, err := json.Marshal(sample)
+	if err != nil {
+		t.Fatalf("failed to marshal sample: %v", err)
+	}
+
+	trainer := NewTrainer()
+	samples := []json.RawMessage{data, data}
+	pair, err := trainer.TrainTwoHand(samples)
+	if err != nil {
+		t.Fatalf("TrainTwoHand() error = %v", err)
+	}
+	if d := pairDistance(pair, want); d > 1e-9 {
+		t.Errorf("expected the template to equal the samples, distance %f", d)
+	}
+	if !floatEqual(pair.Scale, want.Scale) || !floatEqual(pair.Offset.X, want.Offset.X) {
+		t.Errorf("expected offset %+v and scale %f, got %+v and %f", want.Offset, want.Scale, pair.Offset, pair.Scale)
+	}
+
+	diag, err := trainer.DiagnoseTwoHand(samples, pair)
+	if err != nil {
+		t.Fatalf("DiagnoseTwoHand() error = %v", err)
+	}
+	if diag.SampleCount != 2 || diag.MaxDeviation > 1e-9 {
+		t.Errorf("expected two samples without deviation, got %+v", diag)
+	}
+
+	// A sample with a single hand is rejected
+	oneHand := json.RawMessage(`{"type": "two_hand", "hands": [{"handedness": "Left", "landmarks": []}]}`)
+	if _, err := trainer.TrainTwoHand([]json.RawMessage{oneHand}); err == nil {
+		t.Error("expected an error for a sample with one hand")
+	}
+}
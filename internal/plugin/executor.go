@@ -4,29 +4,69 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os/exec"
+	"sync"
 	"time"
 )
 
 // Executor handles the execution of plugins with timeout support.
+// Persistent plugins are started on first use and kept running until Close.
 type Executor struct {
-	timeoutMs int
+	timing persistentTiming
+
+	mu          sync.Mutex
+	timeoutMs   int
+	supervisors map[string]*supervisor // Persistent plugins by name
 }
 
 // NewExecutor creates a new Executor with the specified timeout in milliseconds.
 func NewExecutor(timeoutMs int) *Executor {
 	return &Executor{
 		timeoutMs: timeoutMs,
+		timing: persistentTiming{
+			pingInterval:  DefaultPingInterval,
+			pingTimeout:   DefaultPingTimeout,
+			minBackoff:    DefaultMinBackoff,
+			maxBackoff:    DefaultMaxBackoff,
+			shutdownGrace: DefaultShutdownGrace,
+		},
+		supervisors: make(map[string]*supervisor),
 	}
 }
 
+// SetTimeout changes how long a plugin may take to handle a request.
+// Requests already in progress keep their original timeout.
+func (e *Executor) SetTimeout(timeoutMs int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.timeoutMs = timeoutMs
+}
+
+// Timeout returns how long a plugin may take to handle a request, in milliseconds.
+func (e *Executor) Timeout() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.timeoutMs
+}
+
 // Execute runs a plugin with the given request and returns the response.
-// It creates a context with the configured timeout, marshals the request to JSON,
-// sends it to the plugin via stdin, and parses the stdout as a Response.
+// Persistent plugins receive the request as an "execute" JSON-RPC call on their
+// running process; other plugins are started once per request.
 func (e *Executor) Execute(plugin *Plugin, req *Request) (*Response, error) {
+	if plugin.Manifest.Persistent() {
+		return e.executePersistent(plugin, req)
+	}
+	return e.executeOnce(plugin, req)
+}
+
+// executeOnce creates a context with the configured timeout, marshals the request to JSON,
+// sends it to the plugin via stdin, and parses the stdout as a Response.
+func (e *Executor) executeOnce(plugin *Plugin, req *Request) (*Response, error) {
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(e.timeoutMs)*time.Millisecond)
+	timeoutMs := e.Timeout()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
 	defer cancel()
 
 	// Create command with context
@@ -54,7 +94,7 @@ func (e *Executor) Execute(plugin *Plugin, req *Request) (*Response, error) {
 
 	// Check for context deadline exceeded (timeout)
 	if ctx.Err() == context.DeadlineExceeded {
-		return nil, fmt.Errorf("plugin execution timeout after %dms", e.timeoutMs)
+		return nil, fmt.Errorf("plugin execution timeout after %dms", timeoutMs)
 	}
 
 	// Check for execution error
@@ -74,3 +114,67 @@ func (e *Executor) Execute(plugin *Plugin, req *Request) (*Response, error) {
 
 	return &response, nil
 }
+
+// executePersistent sends the request to the plugin's running process,
+// starting it first if needed.
+func (e *Executor) executePersistent(plugin *Plugin, req *Request) (*Response, error) {
+	timeoutMs := e.Timeout()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timeoutMs)*time.Millisecond)
+	defer cancel()
+
+	result, err := e.supervisor(plugin).call(ctx, "execute", req)
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, fmt.Errorf("plugin execution timeout after %dms", timeoutMs)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("plugin execution failed: %w", err)
+	}
+
+	var response Response
+	if err := json.Unmarshal(result, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse plugin response: %w, result: %s", err, result)
+	}
+
+	return &response, nil
+}
+
+// supervisor returns the supervisor of a persistent plugin, starting one if the
+// plugin is not running yet. A plugin whose executable changed, for example
+// after rediscovery, is restarted.
+func (e *Executor) supervisor(plugin *Plugin) *supervisor {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	name := plugin.Manifest.Name
+	if s, ok := e.supervisors[name]; ok {
+		if s.plugin.Executable == plugin.Executable {
+			return s
+		}
+		go s.stop()
+	}
+
+	s := newSupervisor(plugin, e.timing)
+	e.supervisors[name] = s
+	go s.run()
+
+	return s
+}
+
+// Close gracefully shuts down all persistent plugin processes and waits for them to exit.
+// Plugins are started again if the executor is used afterwards.
+func (e *Executor) Close() {
+	e.mu.Lock()
+	supervisors := e.supervisors
+	e.supervisors = make(map[string]*supervisor)
+	e.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, s := range supervisors {
+		wg.Add(1)
+		go func(s *supervisor) {
+			defer wg.Done()
+			s.stop()
+		}(s)
+	}
+	wg.Wait()
+}
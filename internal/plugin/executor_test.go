@@ -366,3 +366,11 @@ func TestNewExecutor(t *testing.T) {
 		t.Errorf("expected timeoutMs=3000, got %d", executor.timeoutMs)
 	}
 }
+
+func TestExecutor_SetTimeout(t *testing.T) {
+	executor := NewExecutor(3000)
+	executor.SetTimeout(750)
+	if got := executor.Timeout(); got != 750 {
+		t.Errorf("expected timeout 750, got %d", got)
+	}
+}
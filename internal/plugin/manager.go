@@ -3,14 +3,19 @@ package plugin
 import (
 	"encoding/json"
 	"errors"
+	"log"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 )
 
 // ErrPluginNotFound is returned when a requested plugin cannot be found.
 var ErrPluginNotFound = errors.New("plugin not found")
 
+// ErrActionNotFound is returned when a plugin does not provide a requested action.
+var ErrActionNotFound = errors.New("action not found")
+
 // Manager manages plugin discovery and access.
 type Manager struct {
 	pluginDir string
@@ -69,14 +74,23 @@ func (m *Manager) Discover() error {
 		// Read and parse the manifest
 		manifestData, err := os.ReadFile(manifestPath)
 		if err != nil {
+			log.Printf("plugin: skipping %s: %v", manifestPath, err)
 			continue // Skip plugins we can't read
 		}
 
 		var manifest Manifest
 		if err := json.Unmarshal(manifestData, &manifest); err != nil {
+			log.Printf("plugin: skipping %s: %v", manifestPath, err)
 			continue // Skip plugins with invalid JSON
 		}
 
+		// A schema we cannot use leaves its action unchecked rather than hiding the plugin
+		for _, action := range manifest.Actions {
+			if _, err := manifest.decodeActionSchema(action); err != nil {
+				log.Printf("plugin %s: ignoring configSchema of action %s: %v", manifest.Name, action, err)
+			}
+		}
+
 		// Determine the executable path
 		executablePath := filepath.Join(pluginPath, manifest.Executable)
 
@@ -106,7 +120,7 @@ func (m *Manager) Get(name string) (*Plugin, error) {
 	return plugin, nil
 }
 
-// List returns a slice of all discovered plugins.
+// List returns a slice of all discovered plugins, sorted by name.
 func (m *Manager) List() []*Plugin {
 	m.mu.RLock()
 	defer m.mu.RUnlock()
@@ -116,6 +130,10 @@ func (m *Manager) List() []*Plugin {
 		plugins = append(plugins, plugin)
 	}
 
+	sort.Slice(plugins, func(i, j int) bool {
+		return plugins[i].Manifest.Name < plugins[j].Manifest.Name
+	})
+
 	return plugins
 }
 
@@ -123,3 +141,27 @@ func (m *Manager) List() []*Plugin {
 func (m *Manager) PluginDir() string {
 	return m.pluginDir
 }
+
+// Health reports whether a plugin can be run.
+type Health struct {
+	OK    bool
+	Error string // Why the plugin cannot be run, empty if OK
+}
+
+// Health checks that the plugin's executable exists and can be run.
+func (p *Plugin) Health() Health {
+	info, err := os.Stat(p.Executable)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Health{Error: "executable not found"}
+		}
+		return Health{Error: err.Error()}
+	}
+	if info.IsDir() {
+		return Health{Error: "executable is a directory"}
+	}
+	if info.Mode().Perm()&0111 == 0 {
+		return Health{Error: "executable is not executable"}
+	}
+	return Health{OK: true}
+}
@@ -250,6 +250,61 @@ func TestManager_Discover_InvalidJSON(t *testing.T) {
 	}
 }
 
+func TestManager_Discover_LegacySchemas(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	// Manifests written when configSchema was free-form
+	manifests := map[string]string{
+		"whole-schema": `{"name": "whole-schema", "executable": "run", "actions": ["run"],
+			"configSchema": {"type": "object", "properties": {"command": {"type": "string"}}}}`,
+		"bare-type": `{"name": "bare-type", "executable": "run", "actions": ["x"],
+			"configSchema": {"x": "string"}}`,
+		"type-list": `{"name": "type-list", "executable": "run", "actions": ["notify", "beep"],
+			"configSchema": {
+				"notify": {"type": "object", "properties": {"title": {"type": ["string", "null"]}}},
+				"beep": {"type": "object", "properties": {"times": {"type": "integer"}}}
+			}}`,
+	}
+	for name, manifest := range manifests {
+		pluginDir := filepath.Join(tmpDir, name)
+		if err := os.MkdirAll(pluginDir, 0755); err != nil {
+			t.Fatalf("failed to create plugin dir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(pluginDir, "plugin.json"), []byte(manifest), 0644); err != nil {
+			t.Fatalf("failed to write manifest: %v", err)
+		}
+	}
+
+	manager := NewManager(tmpDir)
+	if err := manager.Discover(); err != nil {
+		t.Fatalf("Discover() failed: %v", err)
+	}
+	if plugins := manager.List(); len(plugins) != len(manifests) {
+		t.Fatalf("expected every legacy plugin to be discovered, got %d of %d", len(plugins), len(manifests))
+	}
+
+	// A schema that cannot be decoded leaves its action accepting any params
+	for _, tc := range []struct{ plugin, action string }{
+		{"whole-schema", "run"},
+		{"bare-type", "x"},
+		{"type-list", "notify"},
+	} {
+		p, err := manager.Get(tc.plugin)
+		if err != nil {
+			t.Fatalf("Get(%q) failed: %v", tc.plugin, err)
+		}
+		if err := p.Manifest.ValidateParams(tc.action, json.RawMessage(`{"title": 1, "extra": true}`)); err != nil {
+			t.Errorf("%s/%s: expected any params to be accepted, got %v", tc.plugin, tc.action, err)
+		}
+	}
+
+	// Other actions of the same manifest keep their schemas
+	p, _ := manager.Get("type-list")
+	if err := p.Manifest.ValidateParams("beep", json.RawMessage(`{"times": "twice"}`)); err == nil {
+		t.Error("expected the decodable schema of beep to still be enforced")
+	}
+}
+
 func TestManager_Discover_NonExistentDir(t *testing.T) {
 	// Create a manager with non-existent directory
 	manager := NewManager("/path/that/does/not/exist")
@@ -264,3 +319,40 @@ func TestManager_Discover_NonExistentDir(t *testing.T) {
 		t.Fatalf("expected 0 plugins, got %d", len(plugins))
 	}
 }
+
+func TestPlugin_Health(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	runnable := filepath.Join(tmpDir, "runnable")
+	if err := os.WriteFile(runnable, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("failed to write executable: %v", err)
+	}
+	notRunnable := filepath.Join(tmpDir, "not-runnable")
+	if err := os.WriteFile(notRunnable, []byte("#!/bin/sh\n"), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		executable string
+		wantOK     bool
+	}{
+		{"runnable", runnable, true},
+		{"not executable", notRunnable, false},
+		{"missing", filepath.Join(tmpDir, "missing"), false},
+		{"directory", tmpDir, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &Plugin{Path: tmpDir, Executable: tt.executable}
+			health := p.Health()
+			if health.OK != tt.wantOK {
+				t.Errorf("expected OK=%v, got %+v", tt.wantOK, health)
+			}
+			if !health.OK && health.Error == "" {
+				t.Error("expected an error message for an unhealthy plugin")
+			}
+		})
+	}
+}
@@ -0,0 +1,428 @@
+package plugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// ModeEnv is the environment variable set to ModePersistent when a plugin is
+// started as a long-lived process, so one executable can support both modes.
+const ModeEnv = "KUCHIPUDI_PLUGIN_MODE"
+
+// Default timings for persistent plugins.
+const (
+	DefaultPingInterval  = 10 * time.Second
+	DefaultPingTimeout   = 2 * time.Second
+	DefaultMinBackoff    = 500 * time.Millisecond
+	DefaultMaxBackoff    = 30 * time.Second
+	DefaultShutdownGrace = 2 * time.Second
+)
+
+// stableUptime is how long a process must run before its restart backoff is reset.
+const stableUptime = time.Minute
+
+// errProcessExited is returned for calls still pending when a plugin process exits.
+var errProcessExited = errors.New("plugin process exited")
+
+// errNotRunning is returned for calls made after a plugin process has exited.
+// The request was not sent, so it can be retried on the restarted process.
+var errNotRunning = errors.New("plugin process not running")
+
+// rpcRequest is a JSON-RPC 2.0 request sent to a persistent plugin.
+type rpcRequest struct {
+	JSONRPC string `json:"jsonrpc"`
+	ID      uint64 `json:"id"`
+	Method  string `json:"method"`
+	Params  any    `json:"params,omitempty"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response read from a persistent plugin.
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// rpcError is the error object of a JSON-RPC 2.0 response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return fmt.Sprintf("plugin error %d: %s", e.Code, e.Message)
+}
+
+// process is one running instance of a persistent plugin.
+type process struct {
+	name    string
+	cmd     *exec.Cmd
+	stdin   io.WriteCloser
+	writeMu sync.Mutex // Serializes writes to stdin
+
+	mu      sync.Mutex // Guards nextID, pending, exited and err
+	nextID  uint64
+	pending map[uint64]chan *rpcResponse
+	exited  bool
+	err     error         // Why the process exited, set before done is closed
+	done    chan struct{} // Closed once the process has exited
+	onExit  func(*process)
+}
+
+// startProcess launches a persistent plugin and starts reading its responses.
+// onExit is called once the process has exited, before pending calls fail.
+func startProcess(p *Plugin, onExit func(*process)) (*process, error) {
+	cmd := exec.Command(p.Executable)
+	cmd.Dir = p.Path
+	cmd.Env = append(os.Environ(), ModeEnv+"="+ModePersistent)
+	cmd.Stderr = &logWriter{prefix: "plugin " + p.Manifest.Name + ": "}
+	cmd.WaitDelay = time.Second // Don't hang on children that keep stderr open
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start plugin: %w", err)
+	}
+
+	proc := &process{
+		name:    p.Manifest.Name,
+		cmd:     cmd,
+		stdin:   stdin,
+		pending: make(map[uint64]chan *rpcResponse),
+		done:    make(chan struct{}),
+		onExit:  onExit,
+	}
+	go proc.readLoop(stdout)
+
+	return proc, nil
+}
+
+// readLoop dispatches responses to their callers until stdout is closed,
+// then reaps the process and fails any calls still waiting.
+func (p *process) readLoop(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var resp rpcResponse
+		if err := json.Unmarshal(line, &resp); err != nil {
+			log.Printf("plugin %s: ignoring invalid response: %s", p.name, line)
+			continue
+		}
+
+		p.mu.Lock()
+		ch, ok := p.pending[resp.ID]
+		delete(p.pending, resp.ID)
+		p.mu.Unlock()
+
+		if ok {
+			ch <- &resp
+		}
+	}
+
+	err := p.cmd.Wait()
+	if err == nil {
+		err = errProcessExited
+	} else {
+		err = fmt.Errorf("%w: %v", errProcessExited, err)
+	}
+
+	p.mu.Lock()
+	p.exited = true
+	p.err = err
+	p.mu.Unlock()
+
+	p.onExit(p)
+
+	p.mu.Lock()
+	for id, ch := range p.pending {
+		close(ch)
+		delete(p.pending, id)
+	}
+	p.mu.Unlock()
+
+	close(p.done)
+}
+
+// call sends a request and waits for its response or for ctx to end.
+func (p *process) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	ch := make(chan *rpcResponse, 1)
+
+	p.mu.Lock()
+	if p.exited {
+		p.mu.Unlock()
+		return nil, errNotRunning
+	}
+	p.nextID++
+	id := p.nextID
+	p.pending[id] = ch
+	p.mu.Unlock()
+
+	data, err := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		p.forget(id)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	p.writeMu.Lock()
+	_, err = p.stdin.Write(append(data, '\n'))
+	p.writeMu.Unlock()
+	if err != nil {
+		p.forget(id)
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+
+	select {
+	case resp, ok := <-ch:
+		if !ok {
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			return nil, p.err
+		}
+		if resp.Error != nil {
+			return nil, resp.Error
+		}
+		return resp.Result, nil
+	case <-ctx.Done():
+		p.forget(id)
+		return nil, ctx.Err()
+	}
+}
+
+// forget stops waiting for the response to a request.
+func (p *process) forget(id uint64) {
+	p.mu.Lock()
+	delete(p.pending, id)
+	p.mu.Unlock()
+}
+
+// shutdown closes the plugin's stdin so it can exit on its own, and kills it
+// if it is still running after grace.
+func (p *process) shutdown(grace time.Duration) {
+	p.writeMu.Lock()
+	p.stdin.Close()
+	p.writeMu.Unlock()
+
+	select {
+	case <-p.done:
+	case <-time.After(grace):
+		p.kill()
+	}
+}
+
+// kill terminates the process and waits for it to be reaped.
+func (p *process) kill() {
+	if p.cmd.Process != nil {
+		p.cmd.Process.Kill()
+	}
+	<-p.done
+}
+
+// supervisor keeps one persistent plugin running. It restarts the process with
+// exponential backoff when it exits and kills it when it stops answering pings.
+type supervisor struct {
+	plugin *Plugin
+	timing persistentTiming
+
+	ctx    context.Context // Cancelled to stop the supervisor
+	cancel context.CancelFunc
+	exited chan struct{} // Closed when run returns
+
+	mu    sync.Mutex
+	proc  *process      // Running process, nil while (re)starting
+	ready chan struct{} // Closed when proc is set
+}
+
+// persistentTiming holds the intervals used to supervise persistent plugins.
+type persistentTiming struct {
+	pingInterval  time.Duration
+	pingTimeout   time.Duration
+	minBackoff    time.Duration
+	maxBackoff    time.Duration
+	shutdownGrace time.Duration
+}
+
+// newSupervisor creates a supervisor for p. Call run to start the plugin.
+func newSupervisor(p *Plugin, timing persistentTiming) *supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &supervisor{
+		plugin: p,
+		timing: timing,
+		ctx:    ctx,
+		cancel: cancel,
+		exited: make(chan struct{}),
+		ready:  make(chan struct{}),
+	}
+}
+
+// run starts the plugin and restarts it whenever it exits, until stop is called.
+func (s *supervisor) run() {
+	defer close(s.exited)
+
+	backoff := s.timing.minBackoff
+	for {
+		proc, err := startProcess(s.plugin, s.clearProcess)
+		if err != nil {
+			log.Printf("plugin %s: %v", s.plugin.Manifest.Name, err)
+		} else {
+			started := time.Now()
+			s.setProcess(proc)
+			s.watch(proc)
+			s.clearProcess(proc)
+
+			if time.Since(started) >= stableUptime {
+				backoff = s.timing.minBackoff
+			}
+		}
+
+		if s.ctx.Err() != nil {
+			return
+		}
+
+		log.Printf("plugin %s: restarting in %v", s.plugin.Manifest.Name, backoff)
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > s.timing.maxBackoff {
+			backoff = s.timing.maxBackoff
+		}
+	}
+}
+
+// watch pings proc until it exits, stops answering or the supervisor is stopped.
+func (s *supervisor) watch(proc *process) {
+	ticker := time.NewTicker(s.timing.pingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-proc.done:
+			log.Printf("plugin %s: %v", s.plugin.Manifest.Name, proc.err)
+			return
+
+		case <-s.ctx.Done():
+			proc.shutdown(s.timing.shutdownGrace)
+			return
+
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(s.ctx, s.timing.pingTimeout)
+			_, err := proc.call(ctx, "ping", nil)
+			cancel()
+
+			if err != nil && s.ctx.Err() == nil {
+				log.Printf("plugin %s: health ping failed, killing process: %v", s.plugin.Manifest.Name, err)
+				proc.kill()
+				return
+			}
+		}
+	}
+}
+
+// setProcess publishes a newly started process to callers, unless it has already exited.
+func (s *supervisor) setProcess(proc *process) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	proc.mu.Lock()
+	exited := proc.exited
+	proc.mu.Unlock()
+
+	if exited || s.proc != nil {
+		return
+	}
+	s.proc = proc
+	close(s.ready)
+}
+
+// clearProcess marks the plugin as restarting if proc is the published process.
+func (s *supervisor) clearProcess(proc *process) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.proc != proc {
+		return
+	}
+	s.proc = nil
+	s.ready = make(chan struct{})
+}
+
+// call sends a request to the running process, waiting for the plugin to
+// (re)start if necessary.
+func (s *supervisor) call(ctx context.Context, method string, params any) (json.RawMessage, error) {
+	for {
+		s.mu.Lock()
+		proc, ready := s.proc, s.ready
+		s.mu.Unlock()
+
+		if proc != nil {
+			result, err := proc.call(ctx, method, params)
+			if !errors.Is(err, errNotRunning) {
+				return result, err
+			}
+			// The process died before the request was sent; wait until it
+			// is unpublished, then retry on its replacement
+			<-proc.done
+			continue
+		}
+
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-s.ctx.Done():
+			return nil, errors.New("plugin is shutting down")
+		}
+	}
+}
+
+// stop shuts the plugin down gracefully and waits for the supervisor to finish.
+func (s *supervisor) stop() {
+	s.cancel()
+	<-s.exited
+}
+
+// logWriter logs each line a plugin writes to stderr.
+type logWriter struct {
+	prefix string
+	buf    []byte
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		log.Printf("%s%s", w.prefix, w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
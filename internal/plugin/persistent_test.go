@@ -0,0 +1,204 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// persistentScript answers JSON-RPC requests with its process ID. The "crash"
+// action makes it exit, "hang" is never answered and "fail" returns an RPC error.
+// Pings are answered unless ANSWER_PINGS is replaced with 0.
+const persistentScript = `#!/bin/sh
+answer_pings=ANSWER_PINGS
+[ "$KUCHIPUDI_PLUGIN_MODE" = "persistent" ] || exit 2
+while IFS= read -r line; do
+	id=$(printf '%s' "$line" | sed 's/.*"id":\([0-9]*\).*/\1/')
+	case "$line" in
+	*'"method":"ping"'*)
+		[ "$answer_pings" = 1 ] && printf '{"jsonrpc":"2.0","id":%s,"result":"pong"}\n' "$id" ;;
+	*'"action":"crash"'*)
+		exit 1 ;;
+	*'"action":"hang"'*)
+		;;
+	*'"action":"fail"'*)
+		printf '{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"boom"}}\n' "$id" ;;
+	*)
+		printf '{"jsonrpc":"2.0","id":%s,"result":{"success":true,"data":{"pid":%s}}}\n' "$id" "$$" ;;
+	esac
+done
+`
+
+// newPersistentPlugin writes the test script and returns a persistent plugin running it.
+func newPersistentPlugin(t *testing.T, answerPings bool) *Plugin {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	dir := t.TempDir()
+	script := strings.Replace(persistentScript, "ANSWER_PINGS", "0", 1)
+	if answerPings {
+		script = strings.Replace(persistentScript, "ANSWER_PINGS", "1", 1)
+	}
+
+	scriptPath := filepath.Join(dir, "persistent.sh")
+	if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write script: %v", err)
+	}
+
+	return &Plugin{
+		Manifest: Manifest{
+			Name:       "persistent",
+			Version:    "1.0.0",
+			Executable: "persistent.sh",
+			Actions:    []string{"run", "crash", "hang", "fail"},
+			Mode:       ModePersistent,
+		},
+		Path:       dir,
+		Executable: scriptPath,
+	}
+}
+
+// newPersistentExecutor returns an executor with short supervision intervals.
+func newPersistentExecutor(t *testing.T, timeoutMs int) *Executor {
+	t.Helper()
+
+	e := NewExecutor(timeoutMs)
+	e.timing = persistentTiming{
+		pingInterval:  time.Hour,
+		pingTimeout:   100 * time.Millisecond,
+		minBackoff:    10 * time.Millisecond,
+		maxBackoff:    50 * time.Millisecond,
+		shutdownGrace: time.Second,
+	}
+	t.Cleanup(e.Close)
+	return e
+}
+
+// executePID runs the "run" action and returns the process ID that answered.
+func executePID(t *testing.T, e *Executor, p *Plugin) int {
+	t.Helper()
+
+	resp, err := e.Execute(p, &Request{Action: "run", Gesture: "wave"})
+	if err != nil {
+		t.Fatalf("Execute() failed: %v", err)
+	}
+	if !resp.Success {
+		t.Fatalf("expected success, got %+v", resp)
+	}
+
+	var data struct {
+		PID int `json:"pid"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("failed to parse data: %v", err)
+	}
+	return data.PID
+}
+
+func TestExecutor_Persistent_ReusesProcess(t *testing.T) {
+	p := newPersistentPlugin(t, true)
+	e := newPersistentExecutor(t, 5000)
+
+	first := executePID(t, e, p)
+	for i := 0; i < 5; i++ {
+		if pid := executePID(t, e, p); pid != first {
+			t.Fatalf("request %d: expected process %d to be reused, got %d", i, first, pid)
+		}
+	}
+}
+
+func TestExecutor_Persistent_RPCError(t *testing.T) {
+	p := newPersistentPlugin(t, true)
+	e := newPersistentExecutor(t, 5000)
+
+	_, err := e.Execute(p, &Request{Action: "fail"})
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("expected the plugin's error, got %v", err)
+	}
+}
+
+func TestExecutor_Persistent_Timeout(t *testing.T) {
+	p := newPersistentPlugin(t, true)
+	e := newPersistentExecutor(t, 200)
+
+	_, err := e.Execute(p, &Request{Action: "hang"})
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Errorf("expected timeout error, got %v", err)
+	}
+
+	// The process keeps serving other requests
+	executePID(t, e, p)
+}
+
+func TestExecutor_Persistent_RestartsAfterCrash(t *testing.T) {
+	p := newPersistentPlugin(t, true)
+	e := newPersistentExecutor(t, 5000)
+
+	first := executePID(t, e, p)
+
+	_, err := e.Execute(p, &Request{Action: "crash"})
+	if !errors.Is(err, errProcessExited) {
+		t.Fatalf("expected errProcessExited, got %v", err)
+	}
+
+	if pid := executePID(t, e, p); pid == first {
+		t.Errorf("expected a new process after the crash, got the same pid %d", pid)
+	}
+}
+
+func TestExecutor_Persistent_KillsUnresponsive(t *testing.T) {
+	p := newPersistentPlugin(t, false)
+	e := newPersistentExecutor(t, 5000)
+	e.timing.pingInterval = 50 * time.Millisecond
+
+	first := executePID(t, e, p)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if executePID(t, e, p) != first {
+			return
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Error("expected the process to be restarted after failing health pings")
+}
+
+func TestExecutor_Close(t *testing.T) {
+	p := newPersistentPlugin(t, true)
+	e := newPersistentExecutor(t, 5000)
+
+	pid := executePID(t, e, p)
+	e.Close()
+
+	if proc, err := os.FindProcess(pid); err == nil && proc.Signal(syscall.Signal(0)) == nil {
+		t.Errorf("expected process %d to have exited after Close", pid)
+	}
+
+	// The executor starts the plugin again on demand
+	if again := executePID(t, e, p); again == pid {
+		t.Errorf("expected a new process after Close, got the same pid %d", again)
+	}
+}
+
+func TestManifest_Persistent(t *testing.T) {
+	var m Manifest
+	if err := json.Unmarshal([]byte(`{"name": "p", "mode": "persistent"}`), &m); err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	if !m.Persistent() {
+		t.Error("expected manifest to be persistent")
+	}
+
+	if (&Manifest{Name: "p"}).Persistent() {
+		t.Error("expected manifests without a mode to run once per request")
+	}
+}
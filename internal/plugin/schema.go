@@ -0,0 +1,376 @@
+package plugin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Schema is the subset of JSON Schema that plugin manifests use to describe
+// the params of an action. Keywords outside this subset are ignored.
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Default              json.RawMessage    `json:"default,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	UniqueItems          bool               `json:"uniqueItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// ValidationError describes a params field that does not match the action's schema.
+type ValidationError struct {
+	Field   string // Path of the field, such as "modifiers[0]"; empty for the params object itself
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// ValidationErrors is the list of problems found while validating params.
+type ValidationErrors []*ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, err := range e {
+		msgs[i] = err.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// HasAction reports whether the manifest declares the named action.
+func (m *Manifest) HasAction(action string) bool {
+	for _, a := range m.Actions {
+		if a == action {
+			return true
+		}
+	}
+	return false
+}
+
+// ActionSchema returns the params schema of an action.
+// Actions without a declared schema accept any params object, as do actions
+// whose schema is not one this package understands, such as the free-form
+// schemas of older manifests.
+func (m *Manifest) ActionSchema(action string) *Schema {
+	if s, err := m.decodeActionSchema(action); err == nil && s != nil {
+		return s
+	}
+	return &Schema{Type: "object"}
+}
+
+// decodeActionSchema decodes the declared params schema of an action.
+// Returns nil if the action declares none.
+func (m *Manifest) decodeActionSchema(action string) (*Schema, error) {
+	raw, ok := m.ConfigSchema[action]
+	if !ok {
+		return nil, nil
+	}
+	var s *Schema
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// NumberPlaceholders are the placeholders of action params that are filled in
+// with a number when the gesture fires. A string made of one of them alone is
+// replaced by the number itself, so it may stand wherever a number is expected.
+var NumberPlaceholders = []string{"{{score}}", "{{x}}", "{{y}}"}
+
+// validateOptions relaxes schema validation for values that are not sent to
+// the plugin as they are.
+type validateOptions struct {
+	numberPlaceholders bool // Accept a string of one of NumberPlaceholders as a number
+	partial            bool // Do not enforce the properties the top-level object requires
+}
+
+// ValidateParams checks params for an action against the manifest.
+// Returns ErrActionNotFound if the plugin does not declare the action,
+// or ValidationErrors listing every field that does not match the schema.
+// Empty and null params are treated as an empty object.
+func (m *Manifest) ValidateParams(action string, params json.RawMessage) error {
+	return m.validate(action, "params", params, validateOptions{})
+}
+
+// ValidateBindingParams checks the params a gesture binding stores for an
+// action, as ValidateParams does, except that any of NumberPlaceholders may
+// stand for a number or integer.
+func (m *Manifest) ValidateBindingParams(action string, params json.RawMessage) error {
+	return m.validate(action, "params", params, validateOptions{numberPlaceholders: true})
+}
+
+// ValidateConfig checks the config a gesture binding stores for an action
+// against the action's schema. Config may set any subset of the properties of
+// the params, so the properties the schema requires are not enforced.
+func (m *Manifest) ValidateConfig(action string, config json.RawMessage) error {
+	return m.validate(action, "config", config, validateOptions{partial: true})
+}
+
+// validate decodes raw, named name in error messages, and checks it against
+// the schema of action.
+func (m *Manifest) validate(action, name string, raw json.RawMessage, opts validateOptions) error {
+	if !m.HasAction(action) {
+		return ErrActionNotFound
+	}
+
+	var value any = map[string]any{}
+	if len(raw) > 0 && string(raw) != "null" {
+		dec := json.NewDecoder(bytes.NewReader(raw))
+		dec.UseNumber()
+		if err := dec.Decode(&value); err != nil {
+			return ValidationErrors{{Message: name + " must be valid JSON"}}
+		}
+	}
+
+	if _, ok := value.(map[string]any); !ok {
+		return ValidationErrors{{Message: name + " must be a JSON object"}}
+	}
+
+	schema := m.ActionSchema(action)
+	if opts.partial {
+		relaxed := *schema
+		relaxed.Required = nil
+		schema = &relaxed
+	}
+
+	var errs ValidationErrors
+	schema.validate("", value, opts, &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Defaults returns a params object built from the default values of the schema's
+// properties. Properties without a default are left out.
+func (s *Schema) Defaults() json.RawMessage {
+	values := make(map[string]json.RawMessage)
+	for name, prop := range s.Properties {
+		if prop != nil && len(prop.Default) > 0 {
+			values[name] = prop.Default
+		}
+	}
+
+	data, err := json.Marshal(values)
+	if err != nil {
+		return json.RawMessage("{}")
+	}
+	return data
+}
+
+// Validate checks a decoded JSON value against the schema and returns every problem found.
+// Numbers may be float64 or json.Number.
+func (s *Schema) Validate(value any) ValidationErrors {
+	var errs ValidationErrors
+	s.validate("", value, validateOptions{}, &errs)
+	return errs
+}
+
+// validate checks value at path and appends problems to errs.
+func (s *Schema) validate(path string, value any, opts validateOptions, errs *ValidationErrors) {
+	fail := func(format string, args ...any) {
+		*errs = append(*errs, &ValidationError{Field: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// The number a placeholder stands for is only known when the gesture fires
+	if opts.numberPlaceholders && (s.Type == "number" || s.Type == "integer") && isNumberPlaceholder(value) {
+		return
+	}
+
+	if s.Type != "" && !hasType(value, s.Type) {
+		fail("must be of type %s", s.Type)
+		return
+	}
+
+	if len(s.Enum) > 0 && !inEnum(s.Enum, value) {
+		fail("must be one of %s", formatEnum(s.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := len([]rune(v))
+		if s.MinLength != nil && n < *s.MinLength {
+			fail("must be at least %d characters", *s.MinLength)
+		}
+		if s.MaxLength != nil && n > *s.MaxLength {
+			fail("must be at most %d characters", *s.MaxLength)
+		}
+		if s.Pattern != "" {
+			re, err := regexp.Compile(s.Pattern)
+			if err == nil && !re.MatchString(v) {
+				fail("must match pattern %s", s.Pattern)
+			}
+		}
+
+	case json.Number, float64:
+		f, _ := toFloat(v)
+		if s.Minimum != nil && f < *s.Minimum {
+			fail("must be at least %v", *s.Minimum)
+		}
+		if s.Maximum != nil && f > *s.Maximum {
+			fail("must be at most %v", *s.Maximum)
+		}
+
+	case []any:
+		if s.MinItems != nil && len(v) < *s.MinItems {
+			fail("must have at least %d items", *s.MinItems)
+		}
+		if s.MaxItems != nil && len(v) > *s.MaxItems {
+			fail("must have at most %d items", *s.MaxItems)
+		}
+		if s.UniqueItems && hasDuplicates(v) {
+			fail("must not contain duplicates")
+		}
+		if s.Items != nil {
+			for i, item := range v {
+				s.Items.validate(path+"["+strconv.Itoa(i)+"]", item, opts, errs)
+			}
+		}
+
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*errs = append(*errs, &ValidationError{Field: joinPath(path, name), Message: "is required"})
+			}
+		}
+
+		// Visit fields in a stable order so errors are reported deterministically
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					*errs = append(*errs, &ValidationError{Field: joinPath(path, name), Message: "is not allowed"})
+				}
+				continue
+			}
+			prop.validate(joinPath(path, name), v[name], opts, errs)
+		}
+	}
+}
+
+// isNumberPlaceholder reports whether value is a string of one of NumberPlaceholders alone.
+func isNumberPlaceholder(value any) bool {
+	str, ok := value.(string)
+	return ok && slices.Contains(NumberPlaceholders, str)
+}
+
+// joinPath appends a property name to a field path.
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// hasType reports whether a decoded JSON value is of the named JSON Schema type.
+// Unknown type names accept any value.
+func hasType(value any, typeName string) bool {
+	switch typeName {
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := toFloat(value)
+		return ok
+	case "integer":
+		f, ok := toFloat(value)
+		return ok && f == math.Trunc(f)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+// toFloat converts a decoded JSON number to float64.
+func toFloat(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// inEnum reports whether value equals one of the allowed values.
+func inEnum(allowed []any, value any) bool {
+	for _, a := range allowed {
+		if jsonEqual(a, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDuplicates reports whether any two items of an array are equal.
+func hasDuplicates(items []any) bool {
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			if jsonEqual(items[i], items[j]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// jsonEqual compares two decoded JSON values, treating numbers by value.
+func jsonEqual(a, b any) bool {
+	if fa, ok := toFloat(a); ok {
+		fb, ok := toFloat(b)
+		return ok && fa == fb
+	}
+
+	ja, errA := json.Marshal(a)
+	jb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ja, jb)
+}
+
+// formatEnum renders allowed values for an error message.
+func formatEnum(allowed []any) string {
+	parts := make([]string, len(allowed))
+	for i, a := range allowed {
+		b, _ := json.Marshal(a)
+		parts[i] = string(b)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,185 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// keyboardManifest returns a manifest like the bundled keyboard plugin's.
+func keyboardManifest(t *testing.T) *Manifest {
+	t.Helper()
+
+	data := `{
+		"name": "keyboard",
+		"actions": ["keystroke", "shortcut"],
+		"configSchema": {
+			"keystroke": {
+				"type": "object",
+				"properties": {
+					"key": {"type": "string", "minLength": 1},
+					"modifiers": {
+						"type": "array",
+						"items": {"type": "string", "enum": ["command", "option", "control", "shift"]},
+						"uniqueItems": true
+					},
+					"repeat": {"type": "integer", "minimum": 1, "maximum": 10}
+				},
+				"required": ["key"],
+				"additionalProperties": false
+			}
+		}
+	}`
+
+	var m Manifest
+	if err := json.Unmarshal([]byte(data), &m); err != nil {
+		t.Fatalf("failed to parse manifest: %v", err)
+	}
+	return &m
+}
+
+func TestManifest_ValidateParams(t *testing.T) {
+	m := keyboardManifest(t)
+
+	tests := []struct {
+		name       string
+		action     string
+		params     string
+		wantFields []string // Fields expected in the ValidationErrors, nil for no error
+	}{
+		{"valid", "keystroke", `{"key": "c", "modifiers": ["command", "shift"]}`, nil},
+		{"integer", "keystroke", `{"key": "c", "repeat": 3}`, nil},
+		{"no schema for action", "shortcut", `{"anything": 1}`, nil},
+		{"missing required", "keystroke", `{"modifiers": ["command"]}`, []string{"key"}},
+		{"empty params", "keystroke", ``, []string{"key"}},
+		{"wrong type", "keystroke", `{"key": 5}`, []string{"key"}},
+		{"too short", "keystroke", `{"key": ""}`, []string{"key"}},
+		{"not in enum", "keystroke", `{"key": "c", "modifiers": ["command", "hyper"]}`, []string{"modifiers[1]"}},
+		{"duplicates", "keystroke", `{"key": "c", "modifiers": ["shift", "shift"]}`, []string{"modifiers"}},
+		{"not an integer", "keystroke", `{"key": "c", "repeat": 1.5}`, []string{"repeat"}},
+		{"out of range", "keystroke", `{"key": "c", "repeat": 11}`, []string{"repeat"}},
+		{"additional property", "keystroke", `{"key": "c", "keycode": 12}`, []string{"keycode"}},
+		{"several problems", "keystroke", `{"modifiers": "shift", "keycode": 12}`, []string{"key", "keycode", "modifiers"}},
+		{"not an object", "keystroke", `["c"]`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectFields(t, m.ValidateParams(tt.action, json.RawMessage(tt.params)), tt.wantFields)
+		})
+	}
+}
+
+// expectFields checks that err lists validation errors for exactly wantFields,
+// in order, or is nil if wantFields is nil.
+func expectFields(t *testing.T, err error, wantFields []string) {
+	t.Helper()
+
+	if wantFields == nil {
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		return
+	}
+
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		t.Fatalf("expected ValidationErrors, got %v", err)
+	}
+	if len(verrs) != len(wantFields) {
+		t.Fatalf("expected %d errors, got %v", len(wantFields), verrs)
+	}
+	for i, field := range wantFields {
+		if verrs[i].Field != field {
+			t.Errorf("error %d: expected field %q, got %q (%v)", i, field, verrs[i].Field, verrs[i])
+		}
+	}
+}
+
+func TestManifest_ValidateBindingParams(t *testing.T) {
+	m := keyboardManifest(t)
+
+	tests := []struct {
+		name       string
+		params     string
+		wantFields []string
+	}{
+		{"number placeholder", `{"key": "c", "repeat": "{{score}}"}`, nil},
+		{"placeholder in a string", `{"key": "{{gesture}}"}`, nil},
+		{"text placeholder for a number", `{"key": "c", "repeat": "{{gesture}}"}`, []string{"repeat"}},
+		{"placeholder within text", `{"key": "c", "repeat": "{{x}}0"}`, []string{"repeat"}},
+		{"still checked otherwise", `{"key": "c", "repeat": 11}`, []string{"repeat"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectFields(t, m.ValidateBindingParams("keystroke", json.RawMessage(tt.params)), tt.wantFields)
+		})
+	}
+
+	// Params run as they are, such as from the test endpoint, get no placeholder filled in
+	expectFields(t, m.ValidateParams("keystroke", json.RawMessage(`{"key": "c", "repeat": "{{score}}"}`)), []string{"repeat"})
+}
+
+func TestManifest_ValidateConfig(t *testing.T) {
+	m := keyboardManifest(t)
+
+	tests := []struct {
+		name       string
+		config     string
+		wantFields []string
+	}{
+		{"empty", `{}`, nil},
+		{"null", `null`, nil},
+		{"some properties", `{"modifiers": ["command"]}`, nil},
+		{"wrong type", `{"repeat": "often"}`, []string{"repeat"}},
+		{"not in enum", `{"modifiers": ["hyper"]}`, []string{"modifiers[0]"}},
+		{"additional property", `{"keycode": 12}`, []string{"keycode"}},
+		{"no placeholders", `{"repeat": "{{score}}"}`, []string{"repeat"}},
+		{"not an object", `"c"`, []string{""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expectFields(t, m.ValidateConfig("keystroke", json.RawMessage(tt.config)), tt.wantFields)
+		})
+	}
+}
+
+func TestManifest_ValidateParams_UnknownAction(t *testing.T) {
+	m := keyboardManifest(t)
+
+	err := m.ValidateParams("type-text", json.RawMessage(`{}`))
+	if !errors.Is(err, ErrActionNotFound) {
+		t.Errorf("expected ErrActionNotFound, got %v", err)
+	}
+}
+
+func TestManifest_ActionSchema_Default(t *testing.T) {
+	m := keyboardManifest(t)
+
+	s := m.ActionSchema("shortcut")
+	if s == nil || s.Type != "object" {
+		t.Errorf("expected a plain object schema for an action without one, got %+v", s)
+	}
+}
+
+func TestSchema_Defaults(t *testing.T) {
+	var s Schema
+	data := `{
+		"type": "object",
+		"properties": {
+			"key": {"type": "string", "default": "c"},
+			"modifiers": {"type": "array", "default": ["command"]},
+			"delay": {"type": "integer"}
+		}
+	}`
+	if err := json.Unmarshal([]byte(data), &s); err != nil {
+		t.Fatalf("failed to parse schema: %v", err)
+	}
+
+	got := s.Defaults()
+	if string(got) != `{"key":"c","modifiers":["command"]}` {
+		t.Errorf("unexpected defaults: %s", got)
+	}
+}
@@ -5,12 +5,27 @@ import "encoding/json"
 
 // Manifest describes a plugin's metadata and capabilities.
 type Manifest struct {
-	Name         string          `json:"name"`
-	Version      string          `json:"version"`
-	Description  string          `json:"description"`
-	Executable   string          `json:"executable"`
-	Actions      []string        `json:"actions"`
-	ConfigSchema json.RawMessage `json:"configSchema,omitempty"`
+	Name         string                     `json:"name"`
+	Version      string                     `json:"version"`
+	Description  string                     `json:"description"`
+	Executable   string                     `json:"executable"`
+	Actions      []string                   `json:"actions"`
+	Mode         string                     `json:"mode,omitempty"`         // ModeOneShot (default) or ModePersistent
+	ConfigSchema map[string]json.RawMessage `json:"configSchema,omitempty"` // Params schema per action name, decoded by ActionSchema
+}
+
+// Plugin execution modes declared in a manifest.
+const (
+	// ModeOneShot starts the plugin for every request and reads a single Response from stdout.
+	ModeOneShot = "oneshot"
+	// ModePersistent keeps the plugin running and exchanges newline-delimited
+	// JSON-RPC 2.0 messages with it over stdin and stdout.
+	ModePersistent = "persistent"
+)
+
+// Persistent reports whether the plugin stays running between requests.
+func (m *Manifest) Persistent() bool {
+	return m.Mode == ModePersistent
 }
 
 // Request represents a request sent to a plugin for execution.
@@ -8,17 +8,21 @@ import (
 
 	"github.com/google/uuid"
 
+	"github.com/ayusman/kuchipudi/internal/plugin"
 	"github.com/ayusman/kuchipudi/internal/store"
 )
 
 // ActionHandler handles HTTP requests for action resources.
 type ActionHandler struct {
-	store *store.Store
+	store   *store.Store
+	plugins *plugin.Manager
 }
 
 // NewActionHandler creates a new ActionHandler with the given store.
-func NewActionHandler(s *store.Store) *ActionHandler {
-	return &ActionHandler{store: s}
+// Action params are validated against the plugin manifests known to plugins;
+// plugins may be nil to skip validation.
+func NewActionHandler(s *store.Store, plugins *plugin.Manager) *ActionHandler {
+	return &ActionHandler{store: s, plugins: plugins}
 }
 
 // ServeHTTP implements the http.Handler interface and routes requests to appropriate methods.
@@ -62,6 +66,8 @@ type createActionRequest struct {
 	PluginName string          `json:"plugin_name"`
 	ActionName string          `json:"action_name"`
 	Config     json.RawMessage `json:"config"`
+	Params     json.RawMessage `json:"params"`
+	repeatRequest
 }
 
 type updateActionRequest struct {
@@ -69,19 +75,36 @@ type updateActionRequest struct {
 	PluginName string          `json:"plugin_name"`
 	ActionName string          `json:"action_name"`
 	Config     json.RawMessage `json:"config"`
+	Params     json.RawMessage `json:"params"`
 	Enabled    *bool           `json:"enabled"`
+	repeatRequest
+}
+
+// repeatRequest holds the optional repeat settings of an action request.
+// Empty or nil fields leave the current (or default) value unchanged.
+type repeatRequest struct {
+	RepeatMode          string `json:"repeat_mode"`
+	RepeatIntervalMs    *int   `json:"repeat_interval_ms"`
+	RepeatMinIntervalMs *int   `json:"repeat_min_interval_ms"`
 }
 
 type actionResponse struct {
-	ID         string          `json:"id"`
-	GestureID  string          `json:"gesture_id"`
-	PluginName string          `json:"plugin_name"`
-	ActionName string          `json:"action_name"`
-	Config     json.RawMessage `json:"config"`
-	Enabled    bool            `json:"enabled"`
-	CreatedAt  string          `json:"created_at"`
+	ID                  string          `json:"id"`
+	GestureID           string          `json:"gesture_id"`
+	PluginName          string          `json:"plugin_name"`
+	ActionName          string          `json:"action_name"`
+	Config              json.RawMessage `json:"config"`
+	Params              json.RawMessage `json:"params"`
+	Enabled             bool            `json:"enabled"`
+	RepeatMode          string          `json:"repeat_mode"`
+	RepeatIntervalMs    int             `json:"repeat_interval_ms"`
+	RepeatMinIntervalMs int             `json:"repeat_min_interval_ms"`
+	CreatedAt           string          `json:"created_at"`
 }
 
+// minRepeatIntervalMs is the shortest repeat interval accepted, to keep plugins from being flooded.
+const minRepeatIntervalMs = 50
+
 type listActionsResponse struct {
 	Actions []actionResponse `json:"actions"`
 }
@@ -92,15 +115,58 @@ func toActionResponse(a *store.Action) actionResponse {
 	if config == nil {
 		config = json.RawMessage("{}")
 	}
+	params := a.Params
+	if params == nil {
+		params = json.RawMessage("{}")
+	}
 	return actionResponse{
-		ID:         a.ID,
-		GestureID:  a.GestureID,
-		PluginName: a.PluginName,
-		ActionName: a.ActionName,
-		Config:     config,
-		Enabled:    a.Enabled,
-		CreatedAt:  a.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:                  a.ID,
+		GestureID:           a.GestureID,
+		PluginName:          a.PluginName,
+		ActionName:          a.ActionName,
+		Config:              config,
+		Params:              params,
+		Enabled:             a.Enabled,
+		RepeatMode:          string(a.RepeatMode),
+		RepeatIntervalMs:    a.RepeatIntervalMs,
+		RepeatMinIntervalMs: a.RepeatMinIntervalMs,
+		CreatedAt:           a.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// apply validates the repeat settings and copies the provided ones onto a.
+// Returns an error message suitable for the client, or "" on success.
+func (rr repeatRequest) apply(a *store.Action) string {
+	if rr.RepeatMode != "" {
+		switch mode := store.RepeatMode(rr.RepeatMode); mode {
+		case store.RepeatOnce, store.RepeatInterval, store.RepeatAccelerate:
+			a.RepeatMode = mode
+		default:
+			return "Invalid repeat_mode"
+		}
 	}
+	if rr.RepeatIntervalMs != nil {
+		a.RepeatIntervalMs = *rr.RepeatIntervalMs
+	}
+	if rr.RepeatMinIntervalMs != nil {
+		a.RepeatMinIntervalMs = *rr.RepeatMinIntervalMs
+	}
+
+	if a.RepeatMode == store.RepeatOnce {
+		return ""
+	}
+	if a.RepeatIntervalMs < minRepeatIntervalMs {
+		return "repeat_interval_ms must be at least 50"
+	}
+	if a.RepeatMode == store.RepeatAccelerate {
+		if a.RepeatMinIntervalMs < minRepeatIntervalMs {
+			return "repeat_min_interval_ms must be at least 50"
+		}
+		if a.RepeatMinIntervalMs > a.RepeatIntervalMs {
+			return "repeat_min_interval_ms must not exceed repeat_interval_ms"
+		}
+	}
+	return ""
 }
 
 // list handles GET /api/actions and returns all actions.
@@ -186,13 +252,32 @@ func (h *ActionHandler) create(w http.ResponseWriter, r *http.Request) {
 		config = json.RawMessage("{}")
 	}
 
+	params := req.Params
+	if params == nil {
+		params = json.RawMessage("{}")
+	}
+
 	action := &store.Action{
-		ID:         uuid.New().String(),
-		GestureID:  req.GestureID,
-		PluginName: req.PluginName,
-		ActionName: req.ActionName,
-		Config:     config,
-		Enabled:    true,
+		ID:                  uuid.New().String(),
+		GestureID:           req.GestureID,
+		PluginName:          req.PluginName,
+		ActionName:          req.ActionName,
+		Config:              config,
+		Params:              params,
+		Enabled:             true,
+		RepeatMode:          store.RepeatOnce,
+		RepeatIntervalMs:    store.DefaultRepeatIntervalMs,
+		RepeatMinIntervalMs: store.DefaultRepeatMinIntervalMs,
+	}
+
+	if msg := req.repeatRequest.apply(action); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	if fields := h.validateBinding(action); len(fields) > 0 {
+		writeFieldErrors(w, http.StatusBadRequest, "Invalid action", fields)
+		return
 	}
 
 	if err := h.store.Actions().Create(action); err != nil {
@@ -245,9 +330,25 @@ func (h *ActionHandler) update(w http.ResponseWriter, r *http.Request, id string
 	if req.Config != nil {
 		action.Config = req.Config
 	}
+	if req.Params != nil {
+		action.Params = req.Params
+	}
 	if req.Enabled != nil {
 		action.Enabled = *req.Enabled
 	}
+	if msg := req.repeatRequest.apply(action); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	// Only re-validate the binding when it changes, so that an action whose
+	// plugin was removed can still be disabled or have its repeat mode edited
+	if req.PluginName != "" || req.ActionName != "" || req.Params != nil {
+		if fields := h.validateBinding(action); len(fields) > 0 {
+			writeFieldErrors(w, http.StatusBadRequest, "Invalid action", fields)
+			return
+		}
+	}
 
 	if err := h.store.Actions().Update(action); err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to update action")
@@ -257,6 +358,57 @@ func (h *ActionHandler) update(w http.ResponseWriter, r *http.Request, id string
 	writeJSON(w, http.StatusOK, toActionResponse(action))
 }
 
+// validateBinding checks that the action's plugin and action exist and that
+// its config and params match the action's schema. Placeholders that are
+// filled in with a number when the gesture fires may stand for a number in
+// params. Returns the offending fields, if any.
+func (h *ActionHandler) validateBinding(a *store.Action) []fieldError {
+	if h.plugins == nil {
+		return nil
+	}
+
+	p, err := h.plugins.Get(a.PluginName)
+	if err != nil {
+		return []fieldError{{Field: "plugin_name", Message: "unknown plugin"}}
+	}
+	if !p.Manifest.HasAction(a.ActionName) {
+		return []fieldError{{Field: "action_name", Message: "unknown action"}}
+	}
+
+	var fields []fieldError
+	for _, check := range []struct {
+		name string
+		err  error
+	}{
+		{"config", p.Manifest.ValidateConfig(a.ActionName, a.Config)},
+		{"params", p.Manifest.ValidateBindingParams(a.ActionName, a.Params)},
+	} {
+		if check.err == nil {
+			continue
+		}
+		var verrs plugin.ValidationErrors
+		if !errors.As(check.err, &verrs) {
+			fields = append(fields, fieldError{Field: check.name, Message: check.err.Error()})
+			continue
+		}
+		fields = append(fields, schemaFieldErrors(check.name, verrs)...)
+	}
+	return fields
+}
+
+// schemaFieldErrors converts schema validation errors to field errors under name.
+func schemaFieldErrors(name string, verrs plugin.ValidationErrors) []fieldError {
+	fields := make([]fieldError, 0, len(verrs))
+	for _, v := range verrs {
+		field := name
+		if v.Field != "" {
+			field += "." + v.Field
+		}
+		fields = append(fields, fieldError{Field: field, Message: v.Message})
+	}
+	return fields
+}
+
 // delete handles DELETE /api/actions/{id} and removes an action.
 func (h *ActionHandler) delete(w http.ResponseWriter, r *http.Request, id string) {
 	err := h.store.Actions().Delete(id)
@@ -0,0 +1,340 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/plugin"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// newTestPlugins creates a plugin manager that knows the keyboard plugin's manifest.
+func newTestPlugins(t *testing.T) *plugin.Manager {
+	t.Helper()
+
+	dir := t.TempDir()
+	pluginDir := filepath.Join(dir, "keyboard")
+	if err := os.MkdirAll(pluginDir, 0755); err != nil {
+		t.Fatalf("failed to create plugin dir: %v", err)
+	}
+
+	manifest := `{
+		"name": "keyboard",
+		"version": "1.0.0",
+		"executable": "keyboard",
+		"actions": ["keystroke", "shortcut", "scroll"],
+		"configSchema": {
+			"scroll": {
+				"type": "object",
+				"properties": {
+					"amount": {"type": "number"},
+					"smooth": {"type": "boolean"}
+				},
+				"required": ["amount"]
+			},
+			"keystroke": {
+				"type": "object",
+				"properties": {
+					"key": {"type": "string", "minLength": 1},
+					"modifiers": {
+						"type": "array",
+						"items": {"type": "string", "enum": ["command", "option", "control", "shift"]}
+					}
+				},
+				"required": ["key"],
+				"additionalProperties": false
+			}
+		}
+	}`
+	if err := os.WriteFile(filepath.Join(pluginDir, "plugin.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	m := plugin.NewManager(dir)
+	if err := m.Discover(); err != nil {
+		t.Fatalf("failed to discover plugins: %v", err)
+	}
+	return m
+}
+
+func TestActionHandler_Create_RepeatMode(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewActionHandler(s, nil)
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	body := []byte(`{"gesture_id": "g1", "plugin_name": "system-control", "action_name": "volume-up",
+		"repeat_mode": "accelerate", "repeat_interval_ms": 400, "repeat_min_interval_ms": 100}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/actions", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response actionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.RepeatMode != "accelerate" || response.RepeatIntervalMs != 400 || response.RepeatMinIntervalMs != 100 {
+		t.Errorf("unexpected repeat settings: %+v", response)
+	}
+}
+
+func TestActionHandler_Create_DefaultRepeatMode(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewActionHandler(s, nil)
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	body := []byte(`{"gesture_id": "g1", "plugin_name": "system-control", "action_name": "media-play-pause"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/actions", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response actionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.RepeatMode != string(store.RepeatOnce) {
+		t.Errorf("expected repeat mode %q, got %q", store.RepeatOnce, response.RepeatMode)
+	}
+}
+
+func TestActionHandler_Update_InvalidRepeat(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewActionHandler(s, nil)
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	action := &store.Action{
+		ID:                  "a1",
+		GestureID:           "g1",
+		PluginName:          "system-control",
+		ActionName:          "volume-up",
+		Enabled:             true,
+		RepeatIntervalMs:    store.DefaultRepeatIntervalMs,
+		RepeatMinIntervalMs: store.DefaultRepeatMinIntervalMs,
+	}
+	if err := s.Actions().Create(action); err != nil {
+		t.Fatalf("failed to create action: %v", err)
+	}
+
+	tests := []string{
+		`{"repeat_mode": "sometimes"}`,
+		`{"repeat_mode": "repeat", "repeat_interval_ms": 10}`,
+		`{"repeat_mode": "accelerate", "repeat_interval_ms": 200, "repeat_min_interval_ms": 300}`,
+	}
+
+	for _, body := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/api/actions/a1", bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func TestActionHandler_Create_Params(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewActionHandler(s, newTestPlugins(t))
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	body := []byte(`{"gesture_id": "g1", "plugin_name": "keyboard", "action_name": "keystroke",
+		"params": {"key": "c", "modifiers": ["command"]}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/actions", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response actionResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	stored, err := s.Actions().GetByID(response.ID)
+	if err != nil {
+		t.Fatalf("failed to get action: %v", err)
+	}
+
+	var params struct {
+		Key       string   `json:"key"`
+		Modifiers []string `json:"modifiers"`
+	}
+	if err := json.Unmarshal(stored.Params, &params); err != nil {
+		t.Fatalf("failed to decode stored params: %v", err)
+	}
+	if params.Key != "c" || len(params.Modifiers) != 1 || params.Modifiers[0] != "command" {
+		t.Errorf("unexpected stored params: %s", stored.Params)
+	}
+}
+
+func TestActionHandler_InvalidParams(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewActionHandler(s, newTestPlugins(t))
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	body := []byte(`{"gesture_id": "g1", "plugin_name": "keyboard", "action_name": "keystroke",
+		"params": {"modifiers": ["hyper"]}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/actions", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+
+	var response errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := []string{"params.key", "params.modifiers[0]"}
+	if len(response.Fields) != len(want) {
+		t.Fatalf("expected fields %v, got %+v", want, response.Fields)
+	}
+	for i, field := range want {
+		if response.Fields[i].Field != field {
+			t.Errorf("expected field %q, got %q", field, response.Fields[i].Field)
+		}
+	}
+
+	// A valid binding cannot be updated to invalid params either
+	action := &store.Action{ID: "a1", GestureID: "g1", PluginName: "keyboard", ActionName: "keystroke", Enabled: true}
+	if err := s.Actions().Create(action); err != nil {
+		t.Fatalf("failed to create action: %v", err)
+	}
+
+	body = []byte(`{"params": {"key": 42}}`)
+	req = httptest.NewRequest(http.MethodPut, "/api/actions/a1", bytes.NewReader(body))
+	rec = httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+}
+
+func TestActionHandler_PlaceholdersAndConfig(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewActionHandler(s, newTestPlugins(t))
+
+	for _, id := range []string{"g1", "g2", "g3"} {
+		if err := s.Gestures().Create(&store.Gesture{ID: id, Name: id, Type: store.GestureTypeStatic}); err != nil {
+			t.Fatalf("failed to create gesture: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string // nil if the binding is accepted
+	}{
+		{"number placeholder", `{"gesture_id": "g1", "plugin_name": "keyboard", "action_name": "scroll",
+			"params": {"amount": "{{y}}"}, "config": {"smooth": true}}`, nil},
+		{"text placeholder for a number", `{"gesture_id": "g2", "plugin_name": "keyboard", "action_name": "scroll",
+			"params": {"amount": "{{gesture}}"}}`, []string{"params.amount"}},
+		{"invalid config", `{"gesture_id": "g2", "plugin_name": "keyboard", "action_name": "keystroke",
+			"params": {"key": "c"}, "config": {"modifiers": ["hyper"], "keycode": 12}}`, []string{"config.keycode", "config.modifiers[0]"}},
+		{"invalid config and params", `{"gesture_id": "g3", "plugin_name": "keyboard", "action_name": "scroll",
+			"params": {}, "config": {"smooth": "yes"}}`, []string{"config.smooth", "params.amount"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/api/actions", bytes.NewReader([]byte(tt.body)))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if tt.wantFields == nil {
+				if rec.Code != http.StatusCreated {
+					t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+				}
+				return
+			}
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+			}
+			var response errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(response.Fields) != len(tt.wantFields) {
+				t.Fatalf("expected fields %v, got %+v", tt.wantFields, response.Fields)
+			}
+			for i, field := range tt.wantFields {
+				if response.Fields[i].Field != field {
+					t.Errorf("expected field %q, got %q", field, response.Fields[i].Field)
+				}
+			}
+		})
+	}
+}
+
+func TestActionHandler_UnknownPluginOrAction(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewActionHandler(s, newTestPlugins(t))
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	tests := []struct {
+		body  string
+		field string
+	}{
+		{`{"gesture_id": "g1", "plugin_name": "mouse", "action_name": "click"}`, "plugin_name"},
+		{`{"gesture_id": "g1", "plugin_name": "keyboard", "action_name": "type-text"}`, "action_name"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/actions", bytes.NewReader([]byte(tt.body)))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", tt.body, http.StatusBadRequest, rec.Code)
+			continue
+		}
+
+		var response errorResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		if len(response.Fields) != 1 || response.Fields[0].Field != tt.field {
+			t.Errorf("%s: expected error on %q, got %+v", tt.body, tt.field, response.Fields)
+		}
+	}
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// bundleFileName is the name of the bundle inside a zip archive.
+const bundleFileName = "gestures.json"
+
+// maxBundleSize is the largest bundle accepted by POST /api/import.
+const maxBundleSize = 64 << 20
+
+// BundleHandler handles HTTP requests that export and import gesture bundles.
+type BundleHandler struct {
+	store   *store.Store
+	trainer *gesture.Trainer
+}
+
+// NewBundleHandler creates a new BundleHandler with the given store.
+func NewBundleHandler(s *store.Store) *BundleHandler {
+	return &BundleHandler{
+		store:   s,
+		trainer: gesture.NewTrainer(),
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+// Expected paths: GET /api/export, POST /api/import
+//
+// Export query parameters, all optional:
+//
+//	ids      comma-separated gesture IDs (all gestures if omitted)
+//	include  comma-separated parts to include: templates, samples, actions (all if omitted)
+//	format   "json" (default) or "zip"
+//
+// Import query parameters:
+//
+//	strategy  what to do when a gesture name is taken: skip (default), rename or overwrite
+func (h *BundleHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/api/export":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.export(w, r)
+	case "/api/import":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.importBundle(w, r)
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// Response types
+
+type importResultResponse struct {
+	Name       string `json:"name"`
+	GestureID  string `json:"gesture_id"`
+	ImportedAs string `json:"imported_as,omitempty"`
+	Status     string `json:"status"`
+	Trained    bool   `json:"trained"`
+	Error      string `json:"error,omitempty"` // Why training from the imported samples failed
+}
+
+type importResponse struct {
+	Gestures []importResultResponse `json:"gestures"`
+}
+
+// parseExportOptions reads the export selection from the query string.
+func parseExportOptions(r *http.Request) (store.ExportOptions, string, []fieldError) {
+	q := r.URL.Query()
+	var opts store.ExportOptions
+	var fields []fieldError
+
+	for _, id := range strings.Split(q.Get("ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			opts.GestureIDs = append(opts.GestureIDs, id)
+		}
+	}
+
+	if include := q.Get("include"); include != "" {
+		opts.ExcludeTemplates, opts.ExcludeSamples, opts.ExcludeActions = true, true, true
+		for _, part := range strings.Split(include, ",") {
+			switch strings.TrimSpace(part) {
+			case "templates":
+				opts.ExcludeTemplates = false
+			case "samples":
+				opts.ExcludeSamples = false
+			case "actions":
+				opts.ExcludeActions = false
+			default:
+				fields = append(fields, fieldError{Field: "include", Message: fmt.Sprintf("unknown part %q", part)})
+			}
+		}
+	}
+
+	format := q.Get("format")
+	if format == "" {
+		format = "json"
+	}
+	if format != "json" && format != "zip" {
+		fields = append(fields, fieldError{Field: "format", Message: `must be "json" or "zip"`})
+	}
+
+	return opts, format, fields
+}
+
+// export handles GET /api/export and writes the bundle as a download.
+func (h *BundleHandler) export(w http.ResponseWriter, r *http.Request) {
+	opts, format, fields := parseExportOptions(r)
+	if len(fields) > 0 {
+		writeFieldErrors(w, http.StatusBadRequest, "Invalid query", fields)
+		return
+	}
+
+	bundle, err := h.store.Bundles().Export(opts)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "Gesture not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to export gestures")
+		return
+	}
+
+	data, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to encode bundle")
+		return
+	}
+
+	name := "kuchipudi-gestures-" + bundle.ExportedAt.Format("20060102-150405")
+
+	if format == "zip" {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: bundleFileName, Method: zip.Deflate, Modified: bundle.ExportedAt})
+		if err == nil {
+			_, err = f.Write(data)
+		}
+		if err == nil {
+			err = zw.Close()
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "Failed to encode bundle")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/zip")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.zip"`)
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.json"`)
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// readBundle decodes a bundle sent as JSON or as a zip archive containing gestures.json.
+func readBundle(w http.ResponseWriter, r *http.Request) (*store.Bundle, error) {
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBundleSize))
+	if err != nil {
+		return nil, err
+	}
+
+	// Zip archives start with a local file header
+	if bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid zip archive: %w", err)
+		}
+		f, err := zr.Open(bundleFileName)
+		if err != nil {
+			return nil, fmt.Errorf("zip archive has no %s", bundleFileName)
+		}
+		defer f.Close()
+
+		data, err = io.ReadAll(io.LimitReader(f, maxBundleSize))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var bundle store.Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	return &bundle, nil
+}
+
+// importBundle handles POST /api/import. Gestures imported with samples but
+// without a template are trained from those samples afterwards.
+func (h *BundleHandler) importBundle(w http.ResponseWriter, r *http.Request) {
+	strategy := store.ConflictStrategy(r.URL.Query().Get("strategy"))
+	if strategy == "" {
+		strategy = store.ConflictSkip
+	}
+	switch strategy {
+	case store.ConflictSkip, store.ConflictRename, store.ConflictOverwrite:
+	default:
+		writeFieldErrors(w, http.StatusBadRequest, "Invalid query", []fieldError{
+			{Field: "strategy", Message: `must be "skip", "rename" or "overwrite"`},
+		})
+		return
+	}
+
+	bundle, err := readBundle(w, r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid bundle: "+err.Error())
+		return
+	}
+
+	results, err := h.store.Bundles().Import(bundle, strategy)
+	if err != nil {
+		if errors.Is(err, store.ErrInvalidBundle) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to import gestures")
+		return
+	}
+
+	response := importResponse{Gestures: make([]importResultResponse, 0, len(results))}
+	for _, result := range results {
+		item := importResultResponse{
+			Name:       result.Name,
+			GestureID:  result.GestureID,
+			ImportedAs: result.ImportedAs,
+			Status:     string(result.Status),
+		}
+		if result.NeedsTraining {
+			if err := h.train(result.GestureID); err != nil {
+				item.Error = err.Error()
+			} else {
+				item.Trained = true
+			}
+		}
+		response.Gestures = append(response.Gestures, item)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// train builds the template of an imported gesture from its samples.
+func (h *BundleHandler) train(gestureID string) error {
+	g, err := h.store.Gestures().GetByID(gestureID)
+	if err != nil {
+		return err
+	}
+
+	samples, err := storedSampleData(h.store, gestureID)
+	if err != nil {
+		return err
+	}
+
+	_, err = trainGesture(h.store, h.trainer, g, samples)
+	return err
+}
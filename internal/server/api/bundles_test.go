@@ -0,0 +1,197 @@
+package api
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// staticSamples holds two recorded samples of a static gesture.
+var staticSamples = []json.RawMessage{
+	json.RawMessage(`{"type": "static", "landmarks": [{"x": 0.5, "y": 0.5, "z": 0}, {"x": 0.6, "y": 0.4, "z": 0}]}`),
+	json.RawMessage(`{"type": "static", "landmarks": [{"x": 0.7, "y": 0.5, "z": 0}, {"x": 0.8, "y": 0.4, "z": 0}]}`),
+}
+
+// postImport sends body to POST /api/import with the given query and returns the recorder.
+func postImport(t *testing.T, handler http.Handler, query string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPost, "/api/import"+query, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeImport decodes a successful import response.
+func decodeImport(t *testing.T, rec *httptest.ResponseRecorder) importResponse {
+	t.Helper()
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response importResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestBundleHandler_ExportImport(t *testing.T) {
+	src := newTestStore(t)
+	if err := src.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic, Tolerance: 0.2}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	if err := src.Samples().Create("g1", staticSamples); err != nil {
+		t.Fatalf("failed to create samples: %v", err)
+	}
+
+	for _, format := range []string{"json", "zip"} {
+		t.Run(format, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/export?include=samples&format="+format, nil)
+			rec := httptest.NewRecorder()
+			NewBundleHandler(src).ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+			}
+			if cd := rec.Header().Get("Content-Disposition"); !strings.HasSuffix(cd, "."+format+`"`) {
+				t.Errorf("expected a .%s attachment, got %q", format, cd)
+			}
+
+			// Only samples were exported, so importing them trains a new template
+			dst := newTestStore(t)
+			response := decodeImport(t, postImport(t, NewBundleHandler(dst), "", rec.Body.Bytes()))
+
+			if len(response.Gestures) != 1 {
+				t.Fatalf("expected one imported gesture, got %+v", response.Gestures)
+			}
+			result := response.Gestures[0]
+			if result.Status != "created" || !result.Trained || result.Error != "" {
+				t.Errorf("expected the gesture to be created and trained, got %+v", result)
+			}
+
+			landmarks, err := dst.Gestures().GetLandmarks(result.GestureID)
+			if err != nil {
+				t.Fatalf("failed to get landmarks: %v", err)
+			}
+			if len(landmarks) != 2 {
+				t.Errorf("expected a trained template with 2 landmarks, got %d", len(landmarks))
+			}
+		})
+	}
+}
+
+func TestBundleHandler_ExportUnknownGesture(t *testing.T) {
+	s := newTestStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export?ids=missing", nil)
+	rec := httptest.NewRecorder()
+	NewBundleHandler(s).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestBundleHandler_ExportInvalidQuery(t *testing.T) {
+	s := newTestStore(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/export?include=everything&format=tar", nil)
+	rec := httptest.NewRecorder()
+	NewBundleHandler(s).ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	var response errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Fields) != 2 {
+		t.Errorf("expected errors for include and format, got %+v", response.Fields)
+	}
+}
+
+func TestBundleHandler_ImportStrategy(t *testing.T) {
+	s := newTestStore(t)
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	handler := NewBundleHandler(s)
+
+	body := []byte(`{"format": "kuchipudi-gestures", "version": 1, "gestures": [{"name": "palm", "type": "static"}]}`)
+
+	response := decodeImport(t, postImport(t, handler, "", body))
+	if response.Gestures[0].Status != "skipped" || response.Gestures[0].GestureID != "g1" {
+		t.Errorf("expected the default strategy to skip, got %+v", response.Gestures[0])
+	}
+
+	response = decodeImport(t, postImport(t, handler, "?strategy=rename", body))
+	if response.Gestures[0].Status != "renamed" || response.Gestures[0].ImportedAs != "palm (2)" {
+		t.Errorf("expected the gesture to be renamed, got %+v", response.Gestures[0])
+	}
+
+	rec := postImport(t, handler, "?strategy=merge", body)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d for an unknown strategy, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
+
+func TestBundleHandler_ImportInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+	}{
+		{"not json", []byte(`{not json`)},
+		{"future version", []byte(`{"format": "kuchipudi-gestures", "version": 99, "gestures": []}`)},
+		{"zip without bundle", emptyZip(t)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			rec := postImport(t, NewBundleHandler(s), "", tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestBundleHandler_ImportTrainingFailure(t *testing.T) {
+	s := newTestStore(t)
+
+	body := []byte(`{"format": "kuchipudi-gestures", "version": 1, "gestures": [
+		{"name": "broken", "type": "static", "samples": [{"type": "static", "landmarks": []}]}
+	]}`)
+
+	response := decodeImport(t, postImport(t, NewBundleHandler(s), "", body))
+	result := response.Gestures[0]
+	if result.Status != "created" || result.Trained || result.Error == "" {
+		t.Errorf("expected the gesture to be imported with a training error, got %+v", result)
+	}
+}
+
+// emptyZip returns a zip archive that does not contain a bundle.
+func emptyZip(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	if _, err := zw.Create("readme.txt"); err != nil {
+		t.Fatalf("failed to create zip entry: %v", err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to close zip: %v", err)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// calibrate recommends a tolerance for a freshly computed template and reports
+// the other trained gestures of the same type it may be confused with.
+// Each comparison measures the samples of g against the other gesture's
+// template the way g's own template measures them, so that all distances are
+// on the same scale as the template's diagnostics. Gestures the samples cannot
+// be compared with are skipped.
+func calibrate(s *store.Store, trainer *gesture.Trainer, g *store.Gesture, samples []json.RawMessage, t *template) error {
+	gestures, err := s.Gestures().List()
+	if err != nil {
+		return err
+	}
+
+	var others []gesture.Comparison
+	for _, other := range gestures {
+		if other.ID == g.ID || other.Type != g.Type {
+			continue
+		}
+
+		diag, err := compareWithTemplate(s, trainer, g, other, samples)
+		if err != nil {
+			// A template the samples cannot be measured against says nothing
+			// about this gesture, so it is left out rather than failing training
+			var trainErr *trainingError
+			if errors.As(err, &trainErr) {
+				continue
+			}
+			return err
+		}
+		if diag != nil {
+			others = append(others, gesture.Comparison{ID: other.ID, Name: other.Name, Diagnostics: diag})
+		}
+	}
+
+	t.calibration = gesture.Calibrate(t.diag, others, g.Tolerance)
+	return nil
+}
+
+// compareWithTemplate measures the samples of g against the template of other.
+// Returns nil if other has not been trained, or if its template cannot be
+// compared the way g compares paths: other follows different points, or was
+// trained before it followed any and so lacks the landmarks g needs.
+func compareWithTemplate(s *store.Store, trainer *gesture.Trainer, g, other *store.Gesture, samples []json.RawMessage) (*gesture.Diagnostics, error) {
+	var diag *gesture.Diagnostics
+
+	switch g.Type {
+	case store.GestureTypeDynamic:
+		if !slices.Equal(g.PathTrack, other.PathTrack) {
+			return nil, nil
+		}
+		path, err := s.Gestures().GetPath(other.ID)
+		if err != nil || len(path) == 0 {
+			return nil, err
+		}
+		template, opts := gesturePath(path), pathOptions(g)
+		if !opts.CanCompare(template) {
+			return nil, nil
+		}
+		diag, err = trainer.DiagnoseDynamicWith(samples, template, opts)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+
+	case store.GestureTypeTwoHand:
+		pair, err := s.Gestures().GetHandPair(other.ID)
+		if err != nil || pair == nil {
+			return nil, err
+		}
+		landmarks, err := s.Gestures().GetLandmarks(other.ID)
+		if err != nil {
+			return nil, err
+		}
+		hands := &gesture.HandPair{
+			Offset: detector.Point3D{X: pair.OffsetX, Y: pair.OffsetY, Z: pair.OffsetZ},
+			Scale:  pair.Scale,
+		}
+		for _, l := range landmarks {
+			p := detector.Point3D{X: l.X, Y: l.Y, Z: l.Z}
+			if l.Hand == store.HandRight {
+				hands.Right = append(hands.Right, p)
+			} else {
+				hands.Left = append(hands.Left, p)
+			}
+		}
+		diag, err = trainer.DiagnoseTwoHand(samples, hands)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+
+	default:
+		landmarks, err := s.Gestures().GetLandmarks(other.ID)
+		if err != nil || len(landmarks) == 0 {
+			return nil, err
+		}
+		points := make([]detector.Point3D, len(landmarks))
+		for i, l := range landmarks {
+			points[i] = detector.Point3D{X: l.X, Y: l.Y, Z: l.Z}
+		}
+		diag, err = trainer.DiagnoseStaticWith(samples, points, staticOptions(g))
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+	}
+
+	return diag, nil
+}
@@ -59,25 +59,67 @@ func (h *GestureHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 // Request and response types
 
 type createGestureRequest struct {
-	Name      string  `json:"name"`
-	Type      string  `json:"type"`
-	Tolerance float64 `json:"tolerance"`
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	Tolerance         float64  `json:"tolerance"`
+	ToleranceMode     string   `json:"tolerance_mode"` // manual, or auto to use the tolerance training recommends
+	Handedness        string   `json:"handedness"`     // either, left or right
+	RotationInvariant *bool    `json:"rotation_invariant"`
+	MatchMode         string   `json:"match_mode"`         // landmarks or features
+	Neighbors         *int     `json:"neighbors"`          // 0 to match the averaged template
+	PathNormalization string   `json:"path_normalization"` // axes, uniform or centered
+	PathFeatures      string   `json:"path_features"`      // positions, displacement or velocity
+	PathTrack         []string `json:"path_track"`         // Points of the hand, depth and pose followed; nil leaves unchanged
+	activationRequest
 }
 
 type updateGestureRequest struct {
-	Name      string  `json:"name"`
-	Type      string  `json:"type"`
-	Tolerance float64 `json:"tolerance"`
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	Tolerance         float64  `json:"tolerance"`
+	ToleranceMode     string   `json:"tolerance_mode"` // manual, or auto to use the tolerance training recommends
+	Handedness        string   `json:"handedness"`     // either, left or right
+	RotationInvariant *bool    `json:"rotation_invariant"`
+	MatchMode         string   `json:"match_mode"`         // landmarks or features
+	Neighbors         *int     `json:"neighbors"`          // 0 to match the averaged template
+	PathNormalization string   `json:"path_normalization"` // axes, uniform or centered
+	PathFeatures      string   `json:"path_features"`      // positions, displacement or velocity
+	PathTrack         []string `json:"path_track"`         // Points of the hand, depth and pose followed; nil leaves unchanged
+	activationRequest
+}
+
+// activationRequest holds the optional activation settings of a gesture request.
+// Nil fields leave the current (or default) value unchanged.
+type activationRequest struct {
+	HoldMs        *int  `json:"hold_ms"`
+	MinFrames     *int  `json:"min_frames"`
+	CooldownMs    *int  `json:"cooldown_ms"`
+	FireOnRelease *bool `json:"fire_on_release"`
 }
 
 type gestureResponse struct {
-	ID        string  `json:"id"`
-	Name      string  `json:"name"`
-	Type      string  `json:"type"`
-	Tolerance float64 `json:"tolerance"`
-	Samples   int     `json:"samples"`
-	CreatedAt string  `json:"created_at"`
-	UpdatedAt string  `json:"updated_at"`
+	ID                 string  `json:"id"`
+	Name               string  `json:"name"`
+	Type               string  `json:"type"`
+	Tolerance          float64 `json:"tolerance"`
+	Samples            int     `json:"samples"`
+	HoldMs             int     `json:"hold_ms"`
+	MinFrames          int     `json:"min_frames"`
+	CooldownMs         int     `json:"cooldown_ms"`
+	FireOnRelease      bool    `json:"fire_on_release"`
+	Handedness         string  `json:"handedness"`
+	TemplateHandedness string  `json:"template_handedness,omitempty"` // "Left" or "Right", omitted if unknown
+	RotationInvariant  bool    `json:"rotation_invariant"`
+	MatchMode          string  `json:"match_mode"`
+	Neighbors          int     `json:"neighbors"`
+	ToleranceMode      string  `json:"tolerance_mode"`
+	// Tolerance recommended by the last training, omitted if never trained
+	RecommendedTolerance float64  `json:"recommended_tolerance,omitempty"`
+	PathNormalization    string   `json:"path_normalization"`
+	PathFeatures         string   `json:"path_features"`
+	PathTrack            []string `json:"path_track"`
+	CreatedAt            string   `json:"created_at"`
+	UpdatedAt            string   `json:"updated_at"`
 }
 
 type listGesturesResponse struct {
@@ -85,22 +127,157 @@ type listGesturesResponse struct {
 }
 
 type errorResponse struct {
-	Error string `json:"error"`
+	Error  string       `json:"error"`
+	Fields []fieldError `json:"fields,omitempty"`
+}
+
+// fieldError describes a problem with a single request field.
+type fieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
 }
 
 // toResponse converts a store.Gesture to a gestureResponse.
 func toResponse(g *store.Gesture) gestureResponse {
 	return gestureResponse{
-		ID:        g.ID,
-		Name:      g.Name,
-		Type:      string(g.Type),
-		Tolerance: g.Tolerance,
-		Samples:   g.Samples,
-		CreatedAt: g.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
-		UpdatedAt: g.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		ID:                   g.ID,
+		Name:                 g.Name,
+		Type:                 string(g.Type),
+		Tolerance:            g.Tolerance,
+		Samples:              g.Samples,
+		HoldMs:               g.HoldMs,
+		MinFrames:            g.MinFrames,
+		CooldownMs:           g.CooldownMs,
+		FireOnRelease:        g.FireOnRelease,
+		Handedness:           string(g.Handedness),
+		TemplateHandedness:   g.TemplateHandedness,
+		RotationInvariant:    g.RotationInvariant,
+		MatchMode:            string(g.MatchMode),
+		Neighbors:            g.Neighbors,
+		ToleranceMode:        toleranceMode(g),
+		RecommendedTolerance: g.RecommendedTolerance,
+		PathNormalization:    string(g.PathNormalization),
+		PathFeatures:         string(g.PathFeatures),
+		PathTrack:            trackNames(g.PathTrack),
+		CreatedAt:            g.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:            g.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
 }
 
+// apply validates the activation settings and copies the provided ones onto g.
+// Returns an error message suitable for the client, or "" on success.
+func (a activationRequest) apply(g *store.Gesture) string {
+	if a.HoldMs != nil {
+		if *a.HoldMs < 0 {
+			return "hold_ms must not be negative"
+		}
+		g.HoldMs = *a.HoldMs
+	}
+	if a.MinFrames != nil {
+		if *a.MinFrames < 1 {
+			return "min_frames must be at least 1"
+		}
+		g.MinFrames = *a.MinFrames
+	}
+	if a.CooldownMs != nil {
+		if *a.CooldownMs < 0 {
+			return "cooldown_ms must not be negative"
+		}
+		g.CooldownMs = *a.CooldownMs
+	}
+	if a.FireOnRelease != nil {
+		g.FireOnRelease = *a.FireOnRelease
+	}
+	return ""
+}
+
+// Tolerance modes accepted in gesture requests.
+const (
+	toleranceManual = "manual"
+	toleranceAuto   = "auto"
+)
+
+// toleranceMode returns the tolerance mode of g as reported in responses.
+func toleranceMode(g *store.Gesture) string {
+	if g.AutoTolerance {
+		return toleranceAuto
+	}
+	return toleranceManual
+}
+
+// applyTolerance sets the tolerance of g from a request. A tolerance given
+// without a mode switches the gesture to manual. Switching to auto applies the
+// recommended tolerance right away if the gesture has been trained.
+// Returns an error message for an unknown mode, or "" on success.
+func applyTolerance(g *store.Gesture, tolerance float64, mode string) string {
+	switch mode {
+	case toleranceAuto:
+		g.AutoTolerance = true
+		if g.RecommendedTolerance > 0 {
+			g.Tolerance = g.RecommendedTolerance
+		}
+	case toleranceManual, "":
+		if tolerance != 0 {
+			g.Tolerance = tolerance
+		}
+		if tolerance != 0 || mode == toleranceManual {
+			g.AutoTolerance = false
+		}
+	default:
+		return "Invalid tolerance mode"
+	}
+	return ""
+}
+
+// applyPathOptions sets how the paths of dynamic gesture g are compared from
+// a request. Empty values and a nil track leave the current (or default) value
+// unchanged. Returns an error message for an unknown value, or "" on success.
+func applyPathOptions(g *store.Gesture, normalization, features string, track []string) string {
+	if normalization != "" {
+		n := store.PathNormalization(normalization)
+		if !n.Valid() {
+			return "Invalid path normalization"
+		}
+		g.PathNormalization = n
+	}
+	if features != "" {
+		f := store.PathFeatures(features)
+		if !f.Valid() {
+			return "Invalid path features"
+		}
+		g.PathFeatures = f
+	}
+	if track != nil {
+		g.PathTrack = make([]store.Tracked, len(track))
+		for i, name := range track {
+			t := store.Tracked(name)
+			if !t.Valid() {
+				return "Invalid path track"
+			}
+			g.PathTrack[i] = t
+		}
+	}
+	return ""
+}
+
+// trackNames lists what a dynamic gesture follows for a response, never nil.
+func trackNames(track []store.Tracked) []string {
+	names := make([]string, len(track))
+	for i, t := range track {
+		names[i] = string(t)
+	}
+	return names
+}
+
+// validGestureType reports whether t is a gesture type the matchers support.
+func validGestureType(t store.GestureType) bool {
+	switch t {
+	case store.GestureTypeStatic, store.GestureTypeDynamic, store.GestureTypeTwoHand:
+		return true
+	}
+	return false
+}
+
 // writeJSON writes a JSON response with the given status code.
 func writeJSON(w http.ResponseWriter, status int, data interface{}) {
 	w.Header().Set("Content-Type", "application/json")
@@ -115,6 +292,11 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, errorResponse{Error: message})
 }
 
+// writeFieldErrors writes a JSON error response listing the offending fields.
+func writeFieldErrors(w http.ResponseWriter, status int, message string, fields []fieldError) {
+	writeJSON(w, status, errorResponse{Error: message, Fields: fields})
+}
+
 // list handles GET /api/gestures and returns all gestures.
 func (h *GestureHandler) list(w http.ResponseWriter, r *http.Request) {
 	gestures, err := h.store.Gestures().List()
@@ -170,23 +352,62 @@ func (h *GestureHandler) create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate gesture type
-	if gestureType != store.GestureTypeStatic && gestureType != store.GestureTypeDynamic {
+	if !validGestureType(gestureType) {
 		writeError(w, http.StatusBadRequest, "Invalid gesture type")
 		return
 	}
 
-	// Set default tolerance if not provided
-	tolerance := req.Tolerance
-	if tolerance == 0 {
-		tolerance = 0.15
+	handedness := store.Handedness(req.Handedness)
+	if handedness == "" {
+		handedness = store.HandednessEither
+	}
+	if !handedness.Valid() {
+		writeError(w, http.StatusBadRequest, "Invalid handedness")
+		return
+	}
+
+	matchMode := store.MatchMode(req.MatchMode)
+	if matchMode == "" {
+		matchMode = store.MatchLandmarks
+	}
+	if !matchMode.Valid() {
+		writeError(w, http.StatusBadRequest, "Invalid match mode")
+		return
 	}
 
 	gesture := &store.Gesture{
-		ID:        uuid.New().String(),
-		Name:      req.Name,
-		Type:      gestureType,
-		Tolerance: tolerance,
-		Samples:   0,
+		ID:         uuid.New().String(),
+		Name:       req.Name,
+		Type:       gestureType,
+		Tolerance:  0.15,
+		Samples:    0,
+		MinFrames:  store.DefaultMinFrames,
+		CooldownMs: store.DefaultCooldownMs,
+		Handedness: handedness,
+		MatchMode:  matchMode,
+	}
+	if req.RotationInvariant != nil {
+		gesture.RotationInvariant = *req.RotationInvariant
+	}
+	if req.Neighbors != nil {
+		if *req.Neighbors < 0 {
+			writeError(w, http.StatusBadRequest, "neighbors must not be negative")
+			return
+		}
+		gesture.Neighbors = *req.Neighbors
+	}
+	if msg := applyTolerance(gesture, req.Tolerance, req.ToleranceMode); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := applyPathOptions(gesture, req.PathNormalization, req.PathFeatures, req.PathTrack); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	if msg := req.activationRequest.apply(gesture); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
 	}
 
 	if err := h.store.Gestures().Create(gesture); err != nil {
@@ -222,14 +443,49 @@ func (h *GestureHandler) update(w http.ResponseWriter, r *http.Request, id strin
 	}
 	if req.Type != "" {
 		gestureType := store.GestureType(req.Type)
-		if gestureType != store.GestureTypeStatic && gestureType != store.GestureTypeDynamic {
+		if !validGestureType(gestureType) {
 			writeError(w, http.StatusBadRequest, "Invalid gesture type")
 			return
 		}
 		gesture.Type = gestureType
 	}
-	if req.Tolerance != 0 {
-		gesture.Tolerance = req.Tolerance
+	if req.Handedness != "" {
+		handedness := store.Handedness(req.Handedness)
+		if !handedness.Valid() {
+			writeError(w, http.StatusBadRequest, "Invalid handedness")
+			return
+		}
+		gesture.Handedness = handedness
+	}
+	if req.RotationInvariant != nil {
+		gesture.RotationInvariant = *req.RotationInvariant
+	}
+	if req.MatchMode != "" {
+		matchMode := store.MatchMode(req.MatchMode)
+		if !matchMode.Valid() {
+			writeError(w, http.StatusBadRequest, "Invalid match mode")
+			return
+		}
+		gesture.MatchMode = matchMode
+	}
+	if req.Neighbors != nil {
+		if *req.Neighbors < 0 {
+			writeError(w, http.StatusBadRequest, "neighbors must not be negative")
+			return
+		}
+		gesture.Neighbors = *req.Neighbors
+	}
+	if msg := applyTolerance(gesture, req.Tolerance, req.ToleranceMode); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := applyPathOptions(gesture, req.PathNormalization, req.PathFeatures, req.PathTrack); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	if msg := req.activationRequest.apply(gesture); msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
 	}
 
 	if err := h.store.Gestures().Update(gesture); err != nil {
@@ -3,6 +3,7 @@ package api
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -188,6 +189,46 @@ func TestGestureHandler_Create_MissingName(t *testing.T) {
 	}
 }
 
+func TestGestureHandler_Create_Handedness(t *testing.T) {
+	tests := []struct {
+		handedness string
+		status     int
+		want       string
+	}{
+		{"", http.StatusCreated, "either"},
+		{"left", http.StatusCreated, "left"},
+		{"both", http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.handedness, func(t *testing.T) {
+			s := newTestStore(t)
+			handler := NewGestureHandler(s)
+
+			body, _ := json.Marshal(createGestureRequest{Name: "palm", Handedness: tt.handedness})
+			req := httptest.NewRequest(http.MethodPost, "/api/gestures", bytes.NewReader(body))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d, got %d: %s", tt.status, rec.Code, rec.Body.String())
+			}
+			if tt.status != http.StatusCreated {
+				return
+			}
+
+			var response gestureResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if response.Handedness != tt.want {
+				t.Errorf("expected handedness %q, got %q", tt.want, response.Handedness)
+			}
+		})
+	}
+}
+
 func TestGestureHandler_Get(t *testing.T) {
 	s := newTestStore(t)
 	handler := NewGestureHandler(s)
@@ -296,6 +337,166 @@ func TestGestureHandler_Update(t *testing.T) {
 	}
 }
 
+func TestGestureHandler_Update_RotationInvariant(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewGestureHandler(s)
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	for _, invariant := range []bool{true, false} {
+		body := []byte(fmt.Sprintf(`{"rotation_invariant": %t}`, invariant))
+		req := httptest.NewRequest(http.MethodPut, "/api/gestures/g1", bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+		updated, _ := s.Gestures().GetByID("g1")
+		if updated.RotationInvariant != invariant {
+			t.Errorf("expected rotation_invariant %v, got %v", invariant, updated.RotationInvariant)
+		}
+	}
+}
+
+func TestGestureHandler_MatchMode(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewGestureHandler(s)
+
+	body := []byte(`{"name": "peace", "type": "static", "match_mode": "features"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var created gestureResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.MatchMode != "features" {
+		t.Errorf("expected match_mode features, got %q", created.MatchMode)
+	}
+
+	for _, tc := range []struct {
+		method, path string
+	}{
+		{http.MethodPost, "/api/gestures"},
+		{http.MethodPut, "/api/gestures/" + created.ID},
+	} {
+		body := []byte(`{"name": "two", "match_mode": "joints"}`)
+		req := httptest.NewRequest(tc.method, tc.path, bytes.NewReader(body))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s %s: expected status %d for an unknown match mode, got %d", tc.method, tc.path, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func TestGestureHandler_PathOptions(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewGestureHandler(s)
+
+	body := []byte(`{"name": "circle", "type": "dynamic", "path_normalization": "centered"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var created gestureResponse
+	if err := json.NewDecoder(rec.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if created.PathNormalization != "centered" || created.PathFeatures != "positions" {
+		t.Errorf("expected centered positions, got %q and %q", created.PathNormalization, created.PathFeatures)
+	}
+
+	body = []byte(`{"path_features": "displacement", "path_track": ["index_tip", "thumb_tip", "pose"]}`)
+	req = httptest.NewRequest(http.MethodPut, "/api/gestures/"+created.ID, bytes.NewReader(body))
+	rec = httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+	g, err := s.Gestures().GetByID(created.ID)
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if g.PathNormalization != store.NormalizeCentered || g.PathFeatures != store.PathDisplacement {
+		t.Errorf("expected centered displacement, got %q and %q", g.PathNormalization, g.PathFeatures)
+	}
+	if len(g.PathTrack) != 3 || g.PathTrack[1] != store.TrackThumbTip {
+		t.Errorf("expected the index and thumb tips and pose to be followed, got %v", g.PathTrack)
+	}
+
+	for _, body := range []string{`{"path_normalization": "stretched"}`, `{"path_features": "acceleration"}`, `{"path_track": ["elbow"]}`} {
+		req := httptest.NewRequest(http.MethodPut, "/api/gestures/"+created.ID, bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
+func TestGestureHandler_ToleranceMode(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewGestureHandler(s)
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	if err := s.Gestures().SetRecommendedTolerance("g1", 0.6); err != nil {
+		t.Fatalf("failed to set recommended tolerance: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		body          string
+		wantStatus    int
+		wantMode      string
+		wantTolerance float64
+	}{
+		{"auto applies the recommended tolerance", `{"tolerance_mode": "auto"}`, http.StatusOK, "auto", 0.6},
+		{"other settings keep auto", `{"hold_ms": 100}`, http.StatusOK, "auto", 0.6},
+		{"a tolerance switches back to manual", `{"tolerance": 0.3}`, http.StatusOK, "manual", 0.3},
+		{"unknown mode", `{"tolerance_mode": "magic"}`, http.StatusBadRequest, "manual", 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/api/gestures/g1", bytes.NewReader([]byte(tt.body)))
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			got, _ := s.Gestures().GetByID("g1")
+			response := toResponse(got)
+			if response.ToleranceMode != tt.wantMode || response.Tolerance != tt.wantTolerance || response.RecommendedTolerance != 0.6 {
+				t.Errorf("expected %s tolerance %f, got %+v", tt.wantMode, tt.wantTolerance, response)
+			}
+		})
+	}
+}
+
 func TestGestureHandler_Update_NotFound(t *testing.T) {
 	s := newTestStore(t)
 	handler := NewGestureHandler(s)
@@ -318,6 +519,110 @@ func TestGestureHandler_Update_NotFound(t *testing.T) {
 	}
 }
 
+func TestGestureHandler_Create_ActivationDefaults(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewGestureHandler(s)
+
+	body := []byte(`{"name": "fist"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response gestureResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.HoldMs != 0 {
+		t.Errorf("expected hold_ms 0, got %d", response.HoldMs)
+	}
+	if response.MinFrames != store.DefaultMinFrames {
+		t.Errorf("expected min_frames %d, got %d", store.DefaultMinFrames, response.MinFrames)
+	}
+	if response.CooldownMs != store.DefaultCooldownMs {
+		t.Errorf("expected cooldown_ms %d, got %d", store.DefaultCooldownMs, response.CooldownMs)
+	}
+	if response.FireOnRelease {
+		t.Error("expected fire_on_release false")
+	}
+}
+
+func TestGestureHandler_Update_Activation(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewGestureHandler(s)
+
+	gesture := &store.Gesture{
+		ID:         "test-gesture-1",
+		Name:       "palm",
+		Type:       store.GestureTypeStatic,
+		Tolerance:  0.15,
+		MinFrames:  store.DefaultMinFrames,
+		CooldownMs: store.DefaultCooldownMs,
+	}
+	if err := s.Gestures().Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	body := []byte(`{"hold_ms": 500, "cooldown_ms": 0, "fire_on_release": true}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/gestures/test-gesture-1", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	updated, err := s.Gestures().GetByID("test-gesture-1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if updated.HoldMs != 500 {
+		t.Errorf("expected hold_ms 500, got %d", updated.HoldMs)
+	}
+	if updated.MinFrames != store.DefaultMinFrames {
+		t.Errorf("expected min_frames unchanged, got %d", updated.MinFrames)
+	}
+	if updated.CooldownMs != 0 {
+		t.Errorf("expected cooldown_ms 0, got %d", updated.CooldownMs)
+	}
+	if !updated.FireOnRelease {
+		t.Error("expected fire_on_release true")
+	}
+}
+
+func TestGestureHandler_Update_InvalidActivation(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewGestureHandler(s)
+
+	gesture := &store.Gesture{ID: "test-gesture-1", Name: "palm", Type: store.GestureTypeStatic}
+	if err := s.Gestures().Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	tests := []string{
+		`{"hold_ms": -1}`,
+		`{"min_frames": 0}`,
+		`{"cooldown_ms": -100}`,
+	}
+
+	for _, body := range tests {
+		req := httptest.NewRequest(http.MethodPut, "/api/gestures/test-gesture-1", bytes.NewReader([]byte(body)))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, rec.Code)
+		}
+	}
+}
+
 func TestGestureHandler_Delete(t *testing.T) {
 	s := newTestStore(t)
 	handler := NewGestureHandler(s)
@@ -0,0 +1,159 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// maxHistoryLimit is the largest page of action runs a client may request.
+const maxHistoryLimit = 500
+
+// HistoryHandler handles HTTP requests for the action run history.
+type HistoryHandler struct {
+	store *store.Store
+}
+
+// NewHistoryHandler creates a new HistoryHandler with the given store.
+func NewHistoryHandler(s *store.Store) *HistoryHandler {
+	return &HistoryHandler{store: s}
+}
+
+// ServeHTTP implements the http.Handler interface.
+// Expected path: GET /api/history
+//
+// Query parameters, all optional:
+//
+//	gesture_id, action_id, plugin  exact matches
+//	status                         "success" or "error"
+//	since, until                   RFC 3339 timestamps
+//	limit, offset                  pagination (limit defaults to 50, at most 500)
+func (h *HistoryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	filter, fields := parseHistoryFilter(r)
+	if len(fields) > 0 {
+		writeFieldErrors(w, http.StatusBadRequest, "Invalid query", fields)
+		return
+	}
+
+	runs, total, err := h.store.History().List(filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to list history")
+		return
+	}
+
+	response := historyResponse{
+		Runs:   make([]actionRunResponse, 0, len(runs)),
+		Total:  total,
+		Limit:  filter.Limit,
+		Offset: filter.Offset,
+	}
+	for _, run := range runs {
+		response.Runs = append(response.Runs, toActionRunResponse(run))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// Response types
+
+type actionRunResponse struct {
+	ID          int64           `json:"id"`
+	GestureID   string          `json:"gesture_id"`
+	GestureName string          `json:"gesture_name"`
+	ActionID    string          `json:"action_id"`
+	PluginName  string          `json:"plugin_name"`
+	ActionName  string          `json:"action_name"`
+	Request     json.RawMessage `json:"request,omitempty"`
+	Response    json.RawMessage `json:"response,omitempty"`
+	Success     bool            `json:"success"`
+	Error       string          `json:"error,omitempty"`
+	LatencyMs   int64           `json:"latency_ms"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+type historyResponse struct {
+	Runs   []actionRunResponse `json:"runs"`
+	Total  int                 `json:"total"`
+	Limit  int                 `json:"limit"`
+	Offset int                 `json:"offset"`
+}
+
+// toActionRunResponse converts a store.ActionRun to an actionRunResponse.
+func toActionRunResponse(run *store.ActionRun) actionRunResponse {
+	return actionRunResponse{
+		ID:          run.ID,
+		GestureID:   run.GestureID,
+		GestureName: run.GestureName,
+		ActionID:    run.ActionID,
+		PluginName:  run.PluginName,
+		ActionName:  run.ActionName,
+		Request:     run.Request,
+		Response:    run.Response,
+		Success:     run.Success,
+		Error:       run.Error,
+		LatencyMs:   run.LatencyMs,
+		CreatedAt:   run.CreatedAt.Format(time.RFC3339Nano),
+	}
+}
+
+// parseHistoryFilter reads the history filter from the query string.
+// Returns the offending parameters if any are invalid.
+func parseHistoryFilter(r *http.Request) (store.ActionRunFilter, []fieldError) {
+	q := r.URL.Query()
+	filter := store.ActionRunFilter{
+		GestureID:  q.Get("gesture_id"),
+		ActionID:   q.Get("action_id"),
+		PluginName: q.Get("plugin"),
+		Limit:      store.DefaultHistoryLimit,
+	}
+	var fields []fieldError
+
+	switch status := q.Get("status"); status {
+	case "":
+	case "success", "error":
+		success := status == "success"
+		filter.Success = &success
+	default:
+		fields = append(fields, fieldError{Field: "status", Message: "must be success or error"})
+	}
+
+	parseTime := func(name string, dst *time.Time) {
+		if v := q.Get(name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				fields = append(fields, fieldError{Field: name, Message: "must be an RFC 3339 timestamp"})
+				return
+			}
+			*dst = t
+		}
+	}
+	parseTime("since", &filter.Since)
+	parseTime("until", &filter.Until)
+
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxHistoryLimit {
+			fields = append(fields, fieldError{Field: "limit", Message: "must be between 1 and " + strconv.Itoa(maxHistoryLimit)})
+		} else {
+			filter.Limit = n
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			fields = append(fields, fieldError{Field: "offset", Message: "must be a non-negative integer"})
+		} else {
+			filter.Offset = n
+		}
+	}
+
+	return filter, fields
+}
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// getHistory requests /api/history with the given query and decodes the response.
+func getHistory(t *testing.T, handler http.Handler, query string) historyResponse {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/api/history"+query, nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response historyResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestHistoryHandler_List(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewHistoryHandler(s)
+
+	now := time.Now()
+	runs := []*store.ActionRun{
+		{GestureID: "g1", GestureName: "palm", ActionID: "a1", PluginName: "keyboard", ActionName: "keystroke",
+			Request: json.RawMessage(`{"action":"keystroke"}`), Response: json.RawMessage(`{"success":true}`),
+			Success: true, LatencyMs: 15, CreatedAt: now.Add(-2 * time.Hour)},
+		{GestureID: "g1", GestureName: "palm", ActionID: "a1", PluginName: "keyboard", ActionName: "keystroke",
+			Error: "plugin execution timeout after 5000ms", LatencyMs: 5000, CreatedAt: now.Add(-time.Hour)},
+		{GestureID: "g2", GestureName: "fist", ActionID: "a2", PluginName: "mouse", ActionName: "click",
+			Error: "plugin not found", CreatedAt: now},
+	}
+	for _, run := range runs {
+		if err := s.History().Create(run); err != nil {
+			t.Fatalf("failed to create run: %v", err)
+		}
+	}
+
+	response := getHistory(t, handler, "")
+	if response.Total != 3 || len(response.Runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d (total %d)", len(response.Runs), response.Total)
+	}
+	if response.Limit != store.DefaultHistoryLimit {
+		t.Errorf("expected default limit %d, got %d", store.DefaultHistoryLimit, response.Limit)
+	}
+	if response.Runs[0].Error != "plugin not found" || response.Runs[0].Request != nil {
+		t.Errorf("expected newest run first, got %+v", response.Runs[0])
+	}
+	if oldest := response.Runs[2]; !oldest.Success || string(oldest.Response) != `{"success":true}` || oldest.LatencyMs != 15 {
+		t.Errorf("unexpected oldest run: %+v", oldest)
+	}
+
+	since := now.Add(-90 * time.Minute).UTC().Format(time.RFC3339)
+	tests := []struct {
+		query     string
+		wantTotal int
+		wantLen   int
+	}{
+		{"?gesture_id=g1", 2, 2},
+		{"?action_id=a2", 1, 1},
+		{"?plugin=keyboard&status=error", 1, 1},
+		{"?status=success", 1, 1},
+		{"?since=" + since, 2, 2},
+		{"?until=" + since, 1, 1},
+		{"?limit=1&offset=1", 3, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			response := getHistory(t, handler, tt.query)
+			if response.Total != tt.wantTotal || len(response.Runs) != tt.wantLen {
+				t.Errorf("expected %d runs (total %d), got %d (total %d)",
+					tt.wantLen, tt.wantTotal, len(response.Runs), response.Total)
+			}
+		})
+	}
+}
+
+func TestHistoryHandler_InvalidQuery(t *testing.T) {
+	handler := NewHistoryHandler(newTestStore(t))
+
+	tests := []struct {
+		query string
+		field string
+	}{
+		{"?status=failed", "status"},
+		{"?since=yesterday", "since"},
+		{"?limit=0", "limit"},
+		{"?limit=1000", "limit"},
+		{"?offset=-1", "offset"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/history"+tt.query, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+			}
+
+			var response errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(response.Fields) != 1 || response.Fields[0].Field != tt.field {
+				t.Errorf("expected an error for %s, got %+v", tt.field, response.Fields)
+			}
+		})
+	}
+}
+
+func TestHistoryHandler_MethodNotAllowed(t *testing.T) {
+	handler := NewHistoryHandler(newTestStore(t))
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/history", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected status %d, got %d", http.StatusMethodNotAllowed, rec.Code)
+	}
+}
@@ -0,0 +1,266 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ayusman/kuchipudi/internal/plugin"
+)
+
+// PluginHandler handles HTTP requests for plugin resources.
+type PluginHandler struct {
+	plugins  *plugin.Manager
+	executor *plugin.Executor
+}
+
+// NewPluginHandler creates a new PluginHandler with the given plugin manager.
+// The executor runs test invocations; it may be nil to disable them.
+func NewPluginHandler(m *plugin.Manager, exec *plugin.Executor) *PluginHandler {
+	return &PluginHandler{plugins: m, executor: exec}
+}
+
+// ServeHTTP implements the http.Handler interface and routes requests to appropriate methods.
+// Expected paths:
+//
+//	/api/plugins
+//	/api/plugins/rescan
+//	/api/plugins/{name}
+//	/api/plugins/{name}/schema
+//	/api/plugins/{name}/actions/{action}/test
+func (h *PluginHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/plugins")
+	path = strings.TrimPrefix(path, "/")
+
+	if path == "" {
+		// Collection endpoint: /api/plugins
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.list(w, r)
+		return
+	}
+
+	parts := strings.Split(path, "/")
+
+	switch {
+	case len(parts) == 1 && parts[0] == "rescan":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.rescan(w, r)
+
+	case len(parts) == 1:
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.get(w, r, parts[0])
+
+	case len(parts) == 2 && parts[1] == "schema":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.schema(w, r, parts[0])
+
+	case len(parts) == 4 && parts[1] == "actions" && parts[3] == "test":
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.test(w, r, parts[0], parts[2])
+
+	default:
+		writeError(w, http.StatusNotFound, "Not found")
+	}
+}
+
+// Request and response types
+
+type pluginResponse struct {
+	Name        string   `json:"name"`
+	Version     string   `json:"version"`
+	Description string   `json:"description"`
+	Executable  string   `json:"executable"`
+	Actions     []string `json:"actions"`
+}
+
+type pluginDetailResponse struct {
+	pluginResponse
+	Path   string         `json:"path"`
+	Health healthResponse `json:"health"`
+}
+
+type healthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type listPluginsResponse struct {
+	Plugins []pluginResponse `json:"plugins"`
+}
+
+type pluginSchemaResponse struct {
+	Plugin  string                    `json:"plugin"`
+	Actions map[string]*plugin.Schema `json:"actions"`
+}
+
+type testActionRequest struct {
+	Gesture string          `json:"gesture"`
+	Params  json.RawMessage `json:"params"`
+}
+
+// toPluginResponse converts a plugin.Plugin to a pluginResponse.
+func toPluginResponse(p *plugin.Plugin) pluginResponse {
+	actions := p.Manifest.Actions
+	if actions == nil {
+		actions = []string{}
+	}
+	return pluginResponse{
+		Name:        p.Manifest.Name,
+		Version:     p.Manifest.Version,
+		Description: p.Manifest.Description,
+		Executable:  p.Manifest.Executable,
+		Actions:     actions,
+	}
+}
+
+// getPlugin looks up a plugin and writes an error response if it cannot be found.
+func (h *PluginHandler) getPlugin(w http.ResponseWriter, name string) (*plugin.Plugin, bool) {
+	p, err := h.plugins.Get(name)
+	if err != nil {
+		if errors.Is(err, plugin.ErrPluginNotFound) {
+			writeError(w, http.StatusNotFound, "Plugin not found")
+			return nil, false
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get plugin")
+		return nil, false
+	}
+	return p, true
+}
+
+// list handles GET /api/plugins and returns all discovered plugins.
+func (h *PluginHandler) list(w http.ResponseWriter, r *http.Request) {
+	plugins := h.plugins.List()
+
+	response := listPluginsResponse{
+		Plugins: make([]pluginResponse, 0, len(plugins)),
+	}
+	for _, p := range plugins {
+		response.Plugins = append(response.Plugins, toPluginResponse(p))
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// rescan handles POST /api/plugins/rescan, rediscovers plugins and returns the new list.
+func (h *PluginHandler) rescan(w http.ResponseWriter, r *http.Request) {
+	if err := h.plugins.Discover(); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to scan plugins")
+		return
+	}
+
+	h.list(w, r)
+}
+
+// get handles GET /api/plugins/{name} and returns a plugin with its health.
+func (h *PluginHandler) get(w http.ResponseWriter, r *http.Request, name string) {
+	p, ok := h.getPlugin(w, name)
+	if !ok {
+		return
+	}
+
+	health := healthResponse{Status: "ok"}
+	if ph := p.Health(); !ph.OK {
+		health = healthResponse{Status: "error", Error: ph.Error}
+	}
+
+	writeJSON(w, http.StatusOK, pluginDetailResponse{
+		pluginResponse: toPluginResponse(p),
+		Path:           p.Path,
+		Health:         health,
+	})
+}
+
+// schema handles GET /api/plugins/{name}/schema and returns the params schema of every action.
+func (h *PluginHandler) schema(w http.ResponseWriter, r *http.Request, name string) {
+	p, ok := h.getPlugin(w, name)
+	if !ok {
+		return
+	}
+
+	response := pluginSchemaResponse{
+		Plugin:  p.Manifest.Name,
+		Actions: make(map[string]*plugin.Schema, len(p.Manifest.Actions)),
+	}
+	for _, action := range p.Manifest.Actions {
+		response.Actions[action] = p.Manifest.ActionSchema(action)
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
+
+// test handles POST /api/plugins/{name}/actions/{action}/test.
+// It runs the action with the given params, or the schema defaults if none are given,
+// and returns the plugin's response.
+func (h *PluginHandler) test(w http.ResponseWriter, r *http.Request, name, action string) {
+	if h.executor == nil {
+		writeError(w, http.StatusServiceUnavailable, "Plugin execution not available")
+		return
+	}
+
+	p, ok := h.getPlugin(w, name)
+	if !ok {
+		return
+	}
+
+	if !p.Manifest.HasAction(action) {
+		writeError(w, http.StatusNotFound, "Action not found")
+		return
+	}
+
+	var req testActionRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid JSON")
+			return
+		}
+	}
+
+	params := req.Params
+	if params == nil {
+		params = p.Manifest.ActionSchema(action).Defaults()
+	}
+
+	if err := p.Manifest.ValidateParams(action, params); err != nil {
+		var verrs plugin.ValidationErrors
+		if errors.As(err, &verrs) {
+			writeFieldErrors(w, http.StatusBadRequest, "Invalid params", schemaFieldErrors("params", verrs))
+			return
+		}
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	gesture := req.Gesture
+	if gesture == "" {
+		gesture = "test"
+	}
+
+	resp, err := h.executor.Execute(p, &plugin.Request{
+		Action:  action,
+		Gesture: gesture,
+		Config:  json.RawMessage("{}"),
+		Params:  params,
+	})
+	if err != nil {
+		writeError(w, http.StatusBadGateway, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, resp)
+}
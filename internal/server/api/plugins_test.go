@@ -0,0 +1,301 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/plugin"
+)
+
+func TestPluginHandler_Schema(t *testing.T) {
+	handler := NewPluginHandler(newTestPlugins(t), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins/keyboard/schema", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response struct {
+		Plugin  string                    `json:"plugin"`
+		Actions map[string]*plugin.Schema `json:"actions"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if response.Plugin != "keyboard" {
+		t.Errorf("expected plugin 'keyboard', got %q", response.Plugin)
+	}
+
+	keystroke := response.Actions["keystroke"]
+	if keystroke == nil || keystroke.Properties["key"] == nil {
+		t.Fatalf("expected keystroke schema with a key property, got %+v", keystroke)
+	}
+	if len(keystroke.Required) != 1 || keystroke.Required[0] != "key" {
+		t.Errorf("expected key to be required, got %v", keystroke.Required)
+	}
+
+	// Actions without a declared schema still get one, so a form can be rendered
+	if shortcut := response.Actions["shortcut"]; shortcut == nil || shortcut.Type != "object" {
+		t.Errorf("expected an object schema for shortcut, got %+v", shortcut)
+	}
+}
+
+func TestPluginHandler_Schema_NotFound(t *testing.T) {
+	handler := NewPluginHandler(newTestPlugins(t), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins/mouse/schema", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+// installTestExecutable writes a keyboard executable that echoes the request it
+// receives back as the response data.
+func installTestExecutable(t *testing.T, m *plugin.Manager) {
+	t.Helper()
+
+	if runtime.GOOS == "windows" {
+		t.Skip("skipping test on Windows")
+	}
+
+	p, err := m.Get("keyboard")
+	if err != nil {
+		t.Fatalf("failed to get plugin: %v", err)
+	}
+
+	script := `#!/bin/sh
+input=$(cat)
+printf '{"success":true,"data":%s}' "$input"
+`
+	if err := os.WriteFile(p.Executable, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write executable: %v", err)
+	}
+}
+
+func TestPluginHandler_List(t *testing.T) {
+	handler := NewPluginHandler(newTestPlugins(t), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response listPluginsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if len(response.Plugins) != 1 {
+		t.Fatalf("expected 1 plugin, got %d", len(response.Plugins))
+	}
+	p := response.Plugins[0]
+	if p.Name != "keyboard" || p.Version != "1.0.0" {
+		t.Errorf("unexpected plugin: %+v", p)
+	}
+	if len(p.Actions) != 3 || p.Actions[0] != "keystroke" {
+		t.Errorf("expected actions [keystroke shortcut scroll], got %v", p.Actions)
+	}
+}
+
+func TestPluginHandler_Get(t *testing.T) {
+	m := newTestPlugins(t)
+	handler := NewPluginHandler(m, nil)
+
+	get := func() pluginDetailResponse {
+		t.Helper()
+
+		req := httptest.NewRequest(http.MethodGet, "/api/plugins/keyboard", nil)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+		}
+
+		var response pluginDetailResponse
+		if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+			t.Fatalf("failed to decode response: %v", err)
+		}
+		return response
+	}
+
+	// The test manifest's executable has not been written yet
+	response := get()
+	if response.Name != "keyboard" {
+		t.Errorf("expected plugin 'keyboard', got %q", response.Name)
+	}
+	if response.Health.Status != "error" || response.Health.Error == "" {
+		t.Errorf("expected unhealthy plugin, got %+v", response.Health)
+	}
+
+	installTestExecutable(t, m)
+
+	response = get()
+	if response.Health.Status != "ok" {
+		t.Errorf("expected healthy plugin, got %+v", response.Health)
+	}
+}
+
+func TestPluginHandler_Get_NotFound(t *testing.T) {
+	handler := NewPluginHandler(newTestPlugins(t), nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/plugins/mouse", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
+
+func TestPluginHandler_Rescan(t *testing.T) {
+	m := newTestPlugins(t)
+	handler := NewPluginHandler(m, nil)
+
+	// Add a second plugin next to the first one
+	p, _ := m.Get("keyboard")
+	mouseDir := filepath.Join(filepath.Dir(p.Path), "mouse")
+	if err := os.MkdirAll(mouseDir, 0755); err != nil {
+		t.Fatalf("failed to create plugin dir: %v", err)
+	}
+	manifest := `{"name": "mouse", "version": "0.1.0", "executable": "mouse", "actions": ["click"]}`
+	if err := os.WriteFile(filepath.Join(mouseDir, "plugin.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("failed to write manifest: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/rescan", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response listPluginsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Plugins) != 2 {
+		t.Fatalf("expected 2 plugins after rescan, got %d", len(response.Plugins))
+	}
+	if response.Plugins[1].Name != "mouse" {
+		t.Errorf("expected plugins sorted by name, got %+v", response.Plugins)
+	}
+
+	if _, err := m.Get("mouse"); err != nil {
+		t.Errorf("expected manager to know the new plugin: %v", err)
+	}
+}
+
+func TestPluginHandler_TestAction(t *testing.T) {
+	m := newTestPlugins(t)
+	installTestExecutable(t, m)
+	handler := NewPluginHandler(m, plugin.NewExecutor(5000))
+
+	body := `{"params": {"key": "c", "modifiers": ["command"]}, "gesture": "thumbs-up"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/keyboard/actions/keystroke/test", bytes.NewBufferString(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response plugin.Response
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if !response.Success {
+		t.Fatalf("expected success, got %+v", response)
+	}
+
+	// The test executable echoes the request it received
+	var sent plugin.Request
+	if err := json.Unmarshal(response.Data, &sent); err != nil {
+		t.Fatalf("failed to decode echoed request: %v", err)
+	}
+	if sent.Action != "keystroke" || sent.Gesture != "thumbs-up" {
+		t.Errorf("unexpected request sent to plugin: %+v", sent)
+	}
+	if !strings.Contains(string(sent.Params), `"key":"c"`) {
+		t.Errorf("expected params to be forwarded, got %s", sent.Params)
+	}
+}
+
+func TestPluginHandler_TestAction_InvalidParams(t *testing.T) {
+	m := newTestPlugins(t)
+	installTestExecutable(t, m)
+	handler := NewPluginHandler(m, plugin.NewExecutor(5000))
+
+	// No params and no schema defaults, so the required key is missing
+	req := httptest.NewRequest(http.MethodPost, "/api/plugins/keyboard/actions/keystroke/test", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+	}
+
+	var response errorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Fields) != 1 || response.Fields[0].Field != "params.key" {
+		t.Errorf("expected an error for params.key, got %+v", response.Fields)
+	}
+}
+
+func TestPluginHandler_TestAction_Errors(t *testing.T) {
+	m := newTestPlugins(t)
+
+	tests := []struct {
+		name     string
+		executor *plugin.Executor
+		path     string
+		want     int
+	}{
+		{"no executor", nil, "/api/plugins/keyboard/actions/shortcut/test", http.StatusServiceUnavailable},
+		{"unknown plugin", plugin.NewExecutor(5000), "/api/plugins/mouse/actions/click/test", http.StatusNotFound},
+		{"unknown action", plugin.NewExecutor(5000), "/api/plugins/keyboard/actions/type-text/test", http.StatusNotFound},
+		{"missing executable", plugin.NewExecutor(5000), "/api/plugins/keyboard/actions/shortcut/test", http.StatusBadGateway},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewPluginHandler(m, tt.executor)
+
+			req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+			rec := httptest.NewRecorder()
+
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.want {
+				t.Errorf("expected status %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
@@ -2,20 +2,27 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
 
+	"github.com/ayusman/kuchipudi/internal/gesture"
 	"github.com/ayusman/kuchipudi/internal/store"
 )
 
 // SamplesHandler handles HTTP requests for gesture sample resources.
+// Uploading samples retrains the gesture template from all recorded samples.
 type SamplesHandler struct {
-	store *store.Store
+	store   *store.Store
+	trainer *gesture.Trainer
 }
 
 // NewSamplesHandler creates a new SamplesHandler with the given store.
 func NewSamplesHandler(s *store.Store) *SamplesHandler {
-	return &SamplesHandler{store: s}
+	return &SamplesHandler{
+		store:   s,
+		trainer: gesture.NewTrainer(),
+	}
 }
 
 // ServeHTTP implements the http.Handler interface.
@@ -62,6 +69,11 @@ type listSamplesResponse struct {
 	Samples []sampleResponse `json:"samples"`
 }
 
+type createSamplesResponse struct {
+	Status   string            `json:"status"`
+	Training *trainingResponse `json:"training"`
+}
+
 // list handles GET /api/gestures/{id}/samples
 func (h *SamplesHandler) list(w http.ResponseWriter, r *http.Request, gestureID string) {
 	samples, err := h.store.Samples().GetByGestureID(gestureID)
@@ -90,7 +102,7 @@ func (h *SamplesHandler) list(w http.ResponseWriter, r *http.Request, gestureID
 // create handles POST /api/gestures/{id}/samples
 func (h *SamplesHandler) create(w http.ResponseWriter, r *http.Request, gestureID string) {
 	// Verify gesture exists
-	_, err := h.store.Gestures().GetByID(gestureID)
+	g, err := h.store.Gestures().GetByID(gestureID)
 	if err != nil {
 		if err == store.ErrNotFound {
 			writeError(w, http.StatusNotFound, "Gesture not found")
@@ -111,10 +123,41 @@ func (h *SamplesHandler) create(w http.ResponseWriter, r *http.Request, gestureI
 		return
 	}
 
-	if err := h.store.Samples().Create(gestureID, req.Samples); err != nil {
+	// Train on previously stored samples plus the new ones before saving,
+	// so invalid samples are rejected without touching the database
+	existing, err := storedSampleData(h.store, gestureID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load samples")
+		return
+	}
+	all := append(existing, req.Samples...)
+
+	t, err := computeTemplate(h.trainer, g, all)
+	if err != nil {
+		var trainErr *trainingError
+		if errors.As(err, &trainErr) {
+			writeError(w, http.StatusBadRequest, trainErr.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to train gesture")
+		return
+	}
+	if err := calibrate(h.store, h.trainer, g, all, t); err != nil {
+		var trainErr *trainingError
+		if errors.As(err, &trainErr) {
+			writeError(w, http.StatusBadRequest, trainErr.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to calibrate gesture")
+		return
+	}
+
+	// The samples and the template trained on them are saved together
+	result, err := saveTemplate(h.store, g, t, req.Samples)
+	if err != nil {
 		writeError(w, http.StatusInternalServerError, "Failed to save samples")
 		return
 	}
 
-	writeJSON(w, http.StatusCreated, map[string]string{"status": "ok"})
+	writeJSON(w, http.StatusCreated, createSamplesResponse{Status: "ok", Training: result})
 }
@@ -0,0 +1,397 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+func TestSamplesHandler_Create_TrainsTemplate(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSamplesHandler(s)
+
+	gesture := &store.Gesture{ID: "g1", Name: "point", Type: store.GestureTypeStatic, Tolerance: 0.15}
+	if err := s.Gestures().Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	body := []byte(`{"samples": [
+		{"type": "static", "landmarks": [{"x": 0.5, "y": 0.5, "z": 0}, {"x": 0.6, "y": 0.4, "z": 0}]},
+		{"type": "static", "landmarks": [{"x": 0.7, "y": 0.5, "z": 0}, {"x": 0.8, "y": 0.4, "z": 0}]}
+	]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/g1/samples", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response createSamplesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Training == nil || response.Training.Points != 2 {
+		t.Fatalf("expected training result with 2 points, got %+v", response.Training)
+	}
+	if len(response.Training.Diagnostics.Deviations) != 2 {
+		t.Errorf("expected 2 deviations, got %+v", response.Training.Diagnostics)
+	}
+
+	landmarks, err := s.Gestures().GetLandmarks("g1")
+	if err != nil {
+		t.Fatalf("GetLandmarks() error = %v", err)
+	}
+	if len(landmarks) != 2 {
+		t.Errorf("expected 2 stored landmarks, got %d", len(landmarks))
+	}
+}
+
+func TestSamplesHandler_Create_TwoHand(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSamplesHandler(s)
+
+	g := &store.Gesture{ID: "g1", Name: "frame", Type: store.GestureTypeTwoHand, Tolerance: 0.5}
+	if err := s.Gestures().Create(g); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	left, right := detector.OpenPalmLandmarks(), detector.ThumbsUpLandmarks()
+	for i := range right.Points {
+		right.Points[i].X -= 0.3
+	}
+	sample, err := json.Marshal(gesture.TwoHandSample{
+		Type: "two_hand",
+		Hands: []gesture.SampleHand{
+			{Handedness: "Left", Landmarks: left.Points[:]},
+			{Handedness: "Right", Landmarks: right.Points[:]},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal sample: %v", err)
+	}
+
+	body := []byte(`{"samples": [` + string(sample) + `,` + string(sample) + `]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/g1/samples", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response createSamplesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.Training == nil || response.Training.Points != 2*detector.NumLandmarks {
+		t.Fatalf("expected training result with both hands, got %+v", response.Training)
+	}
+
+	pair, err := s.Gestures().GetHandPair("g1")
+	if err != nil {
+		t.Fatalf("GetHandPair() error = %v", err)
+	}
+	if pair == nil || pair.OffsetX >= 0 || pair.Scale <= 0 {
+		t.Errorf("expected the right hand to be stored left of the left hand, got %+v", pair)
+	}
+}
+
+func TestSamplesHandler_Create_FlagsOutliers(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSamplesHandler(s)
+
+	g := &store.Gesture{ID: "g1", Name: "palm", Type: store.GestureTypeStatic, Tolerance: 0.5, Neighbors: 1}
+	if err := s.Gestures().Create(g); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	// Open palms with the index fingertip in slightly different places, and one thumbs up
+	var samples []gesture.StaticSample
+	for i, dx := range []float64{0, 0.004, 0.008, 0, 0.012} {
+		hand := detector.OpenPalmLandmarks()
+		if i == 3 {
+			hand = detector.ThumbsUpLandmarks()
+		}
+		hand.Points[detector.IndexTip].X += dx
+		samples = append(samples, gesture.StaticSample{Type: "static", Landmarks: hand.Points[:]})
+	}
+	body, _ := json.Marshal(map[string]interface{}{"samples": samples})
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/g1/samples", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response createSamplesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Training.Outliers) != 1 || response.Training.Outliers[0] != 3 {
+		t.Errorf("expected the thumbs up to be rejected, got outliers %v", response.Training.Outliers)
+	}
+
+	stored, err := s.Samples().GetByGestureID("g1")
+	if err != nil {
+		t.Fatalf("failed to get samples: %v", err)
+	}
+	for i, sample := range stored {
+		if sample.Outlier != (i == 3) {
+			t.Errorf("sample %d: expected outlier %v, got %v", i, i == 3, sample.Outlier)
+		}
+	}
+}
+
+func TestSamplesHandler_Create_CalibratesTolerance(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSamplesHandler(s)
+
+	// An already trained open palm
+	palm := detector.OpenPalmLandmarks()
+	if err := s.Gestures().Create(&store.Gesture{ID: "palm", Name: "palm", Type: store.GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	normalized := palm.Normalize()
+	landmarks := make([]store.Landmark, len(normalized.Points))
+	for i, p := range normalized.Points {
+		landmarks[i] = store.Landmark{Index: i, X: p.X, Y: p.Y, Z: p.Z}
+	}
+	if err := s.Gestures().SaveStaticTemplate("palm", landmarks, ""); err != nil {
+		t.Fatalf("failed to save template: %v", err)
+	}
+
+	// A new gesture that is a slight variation of the open palm
+	g := &store.Gesture{ID: "wave", Name: "wave", Type: store.GestureTypeStatic, Tolerance: 0.15, AutoTolerance: true}
+	if err := s.Gestures().Create(g); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	var samples []gesture.StaticSample
+	for _, dx := range []float64{0.01, 0.03, 0.05} {
+		hand := detector.OpenPalmLandmarks()
+		hand.Points[detector.IndexTip].X += dx
+		hand.Points[detector.MiddleTip].X += dx
+		samples = append(samples, gesture.StaticSample{Type: "static", Landmarks: hand.Points[:]})
+	}
+	body, _ := json.Marshal(map[string]interface{}{"samples": samples})
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/wave/samples", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	var response createSamplesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	c := response.Training.Calibration
+	if c == nil || len(c.Confusions) != 1 || c.Confusions[0].ID != "palm" || !c.Confusions[0].Likely {
+		t.Fatalf("expected the open palm reported as a likely confusion, got %+v", c)
+	}
+	if c.Tolerance <= 0 || c.Tolerance > c.Separation/2 {
+		t.Errorf("expected a tolerance within half the separation %f, got %f", c.Separation, c.Tolerance)
+	}
+
+	stored, err := s.Gestures().GetByID("wave")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if stored.Tolerance != c.Tolerance || stored.RecommendedTolerance != c.Tolerance {
+		t.Errorf("expected the auto tolerance to be set to %f, got %+v", c.Tolerance, stored)
+	}
+}
+
+func TestSamplesHandler_Create_SkipsIncomparableTemplates(t *testing.T) {
+	s := newTestStore(t)
+
+	// A dynamic gesture trained before paths recorded the hand
+	legacy := &store.Gesture{ID: "swipe", Name: "swipe", Type: store.GestureTypeDynamic, Tolerance: 0.5}
+	if err := s.Gestures().Create(legacy); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	if err := s.Gestures().SavePath("swipe", []store.PathPoint{{Sequence: 0, X: 0.8, Y: 0.5}, {Sequence: 1, X: 0.2, Y: 0.5, TimestampMs: 100}}); err != nil {
+		t.Fatalf("failed to save path: %v", err)
+	}
+
+	// A new gesture following the wrist, recorded with the whole hand
+	tracked := &store.Gesture{ID: "push", Name: "push", Type: store.GestureTypeDynamic, Tolerance: 0.5,
+		PathTrack: []store.Tracked{store.TrackWrist}}
+	if err := s.Gestures().Create(tracked); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	var samples []gesture.DynamicSample
+	for _, dy := range []float64{0, 0.02} {
+		sample := gesture.DynamicSample{Type: "dynamic"}
+		for i := range 5 {
+			hand := detector.OpenPalmLandmarks()
+			for j := range hand.Points {
+				hand.Points[j].Y += dy - 0.05*float64(i)
+			}
+			wrist := hand.Points[detector.Wrist]
+			sample.Path = append(sample.Path, gesture.PathPoint{X: wrist.X, Y: wrist.Y, Timestamp: int64(100 * i), Hand: hand.Points[:]})
+		}
+		samples = append(samples, sample)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"samples": samples})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/push/samples", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewSamplesHandler(s).ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+	var response createSamplesResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if c := response.Training.Calibration; c == nil || len(c.Confusions) != 0 {
+		t.Errorf("expected the legacy template left out of calibration, got %+v", c)
+	}
+
+	// Retraining from the stored samples skips it as well
+	req = httptest.NewRequest(http.MethodPost, "/api/gestures/push/train", nil)
+	rec = httptest.NewRecorder()
+	NewTrainHandler(s).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	// And the legacy gesture still trains next to the tracked one
+	legacySample := json.RawMessage(`{"type": "dynamic", "path": [{"x": 0.8, "y": 0.5, "timestamp": 0}, {"x": 0.2, "y": 0.5, "timestamp": 100}]}`)
+	if err := s.Samples().Create("swipe", []json.RawMessage{legacySample}); err != nil {
+		t.Fatalf("failed to create samples: %v", err)
+	}
+	req = httptest.NewRequest(http.MethodPost, "/api/gestures/swipe/train", nil)
+	rec = httptest.NewRecorder()
+	NewTrainHandler(s).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+}
+
+func TestSamplesHandler_Create_SkipsBrokenTemplates(t *testing.T) {
+	s := newTestStore(t)
+
+	// Another gesture whose template is missing most of its landmarks
+	if err := s.Gestures().Create(&store.Gesture{ID: "fist", Name: "fist", Type: store.GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	if err := s.Gestures().SaveStaticTemplate("fist", []store.Landmark{{Index: 0}, {Index: 1, X: 0.1}}, ""); err != nil {
+		t.Fatalf("failed to save template: %v", err)
+	}
+
+	if err := s.Gestures().Create(&store.Gesture{ID: "palm", Name: "palm", Type: store.GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	hand := detector.OpenPalmLandmarks()
+	samples := []gesture.StaticSample{{Type: "static", Landmarks: hand.Points[:]}, {Type: "static", Landmarks: hand.Points[:]}}
+	body, _ := json.Marshal(map[string]interface{}{"samples": samples})
+
+	// The problem with the other gesture does not stop this one from being trained
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/palm/samples", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	NewSamplesHandler(s).ServeHTTP(rec, req)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, rec.Code, rec.Body.String())
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/gestures/palm/train", nil)
+	rec = httptest.NewRecorder()
+	NewTrainHandler(s).ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+}
+
+func TestSamplesHandler_Create_InvalidSamples(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSamplesHandler(s)
+
+	gesture := &store.Gesture{ID: "g1", Name: "swipe", Type: store.GestureTypeDynamic, Tolerance: 0.5}
+	if err := s.Gestures().Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	// A single-point path cannot be trained
+	body := []byte(`{"samples": [{"type": "dynamic", "path": [{"x": 0, "y": 0, "timestamp": 0}]}]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/g1/samples", bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	samples, _ := s.Samples().GetByGestureID("g1")
+	if len(samples) != 0 {
+		t.Errorf("invalid samples should not be stored, got %d", len(samples))
+	}
+}
+
+func TestTrainHandler_Train(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewTrainHandler(s)
+
+	gesture := &store.Gesture{ID: "g1", Name: "swipe", Type: store.GestureTypeDynamic, Tolerance: 0.5}
+	if err := s.Gestures().Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	// Training without samples is rejected
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/g1/train", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d without samples, got %d", http.StatusBadRequest, rec.Code)
+	}
+
+	samples := []json.RawMessage{
+		json.RawMessage(`{"type": "dynamic", "path": [{"x": 0.8, "y": 0.5, "timestamp": 0}, {"x": 0.2, "y": 0.5, "timestamp": 100}]}`),
+	}
+	if err := s.Samples().Create("g1", samples); err != nil {
+		t.Fatalf("failed to create samples: %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/gestures/g1/train", nil)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	path, err := s.Gestures().GetPath("g1")
+	if err != nil {
+		t.Fatalf("GetPath() error = %v", err)
+	}
+	if len(path) != 2 {
+		t.Errorf("expected 2 stored path points, got %d", len(path))
+	}
+}
+
+func TestTrainHandler_NotFound(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewTrainHandler(s)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/gestures/missing/train", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d, got %d", http.StatusNotFound, rec.Code)
+	}
+}
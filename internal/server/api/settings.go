@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// Limits accepted by PUT /api/settings.
+const (
+	minFPS           = 1
+	maxFPS           = 60
+	minTimeoutMs     = 100
+	maxTimeoutMs     = 60000
+	maxMotionPercent = 100
+)
+
+// SettingsHandler handles HTTP requests for the application settings.
+type SettingsHandler struct {
+	store *store.Store
+	apply func(store.Settings)
+}
+
+// NewSettingsHandler creates a new SettingsHandler with the given store.
+// apply is called with the new settings after every successful update so that
+// they take effect without a restart; it may be nil.
+func NewSettingsHandler(s *store.Store, apply func(store.Settings)) *SettingsHandler {
+	return &SettingsHandler{store: s, apply: apply}
+}
+
+// ServeHTTP implements the http.Handler interface.
+// Expected paths: GET /api/settings, PUT /api/settings
+func (h *SettingsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.get(w, r)
+	case http.MethodPut:
+		h.update(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// Request and response types
+
+type settingsResponse struct {
+	CameraID        int     `json:"camera_id"`
+	MotionThreshold float64 `json:"motion_threshold"`
+	IdleFPS         int     `json:"idle_fps"`
+	ActiveFPS       int     `json:"active_fps"`
+	IdleTimeoutMs   int     `json:"idle_timeout_ms"`
+	Port            int     `json:"port"`
+	PluginTimeoutMs int     `json:"plugin_timeout_ms"`
+	MinMatchScore   float64 `json:"min_match_score"`
+	AmbiguityMargin float64 `json:"ambiguity_margin"`
+
+	// RestartRequired lists the updated settings that only take effect after a restart
+	RestartRequired []string `json:"restart_required,omitempty"`
+}
+
+// updateSettingsRequest holds a partial update; omitted fields keep their value.
+type updateSettingsRequest struct {
+	CameraID        *int     `json:"camera_id"`
+	MotionThreshold *float64 `json:"motion_threshold"`
+	IdleFPS         *int     `json:"idle_fps"`
+	ActiveFPS       *int     `json:"active_fps"`
+	IdleTimeoutMs   *int     `json:"idle_timeout_ms"`
+	Port            *int     `json:"port"`
+	PluginTimeoutMs *int     `json:"plugin_timeout_ms"`
+	MinMatchScore   *float64 `json:"min_match_score"`
+	AmbiguityMargin *float64 `json:"ambiguity_margin"`
+}
+
+// toSettingsResponse converts store.Settings to a settingsResponse.
+func toSettingsResponse(s store.Settings) settingsResponse {
+	return settingsResponse{
+		CameraID:        s.CameraID,
+		MotionThreshold: s.MotionThreshold,
+		IdleFPS:         s.IdleFPS,
+		ActiveFPS:       s.ActiveFPS,
+		IdleTimeoutMs:   s.IdleTimeoutMs,
+		Port:            s.Port,
+		PluginTimeoutMs: s.PluginTimeoutMs,
+		MinMatchScore:   s.MinMatchScore,
+		AmbiguityMargin: s.AmbiguityMargin,
+	}
+}
+
+// apply copies the fields present in the request onto s.
+func (req *updateSettingsRequest) apply(s *store.Settings) {
+	if req.CameraID != nil {
+		s.CameraID = *req.CameraID
+	}
+	if req.MotionThreshold != nil {
+		s.MotionThreshold = *req.MotionThreshold
+	}
+	if req.IdleFPS != nil {
+		s.IdleFPS = *req.IdleFPS
+	}
+	if req.ActiveFPS != nil {
+		s.ActiveFPS = *req.ActiveFPS
+	}
+	if req.IdleTimeoutMs != nil {
+		s.IdleTimeoutMs = *req.IdleTimeoutMs
+	}
+	if req.Port != nil {
+		s.Port = *req.Port
+	}
+	if req.PluginTimeoutMs != nil {
+		s.PluginTimeoutMs = *req.PluginTimeoutMs
+	}
+	if req.MinMatchScore != nil {
+		s.MinMatchScore = *req.MinMatchScore
+	}
+	if req.AmbiguityMargin != nil {
+		s.AmbiguityMargin = *req.AmbiguityMargin
+	}
+}
+
+// validateSettings returns a field error for every setting that is out of range.
+func validateSettings(s store.Settings) []fieldError {
+	var fields []fieldError
+	if s.CameraID < 0 {
+		fields = append(fields, fieldError{Field: "camera_id", Message: "must not be negative"})
+	}
+	if s.MotionThreshold <= 0 || s.MotionThreshold > maxMotionPercent {
+		fields = append(fields, fieldError{Field: "motion_threshold", Message: "must be greater than 0 and at most 100"})
+	}
+	if s.IdleFPS < minFPS || s.IdleFPS > maxFPS {
+		fields = append(fields, fieldError{Field: "idle_fps", Message: fmt.Sprintf("must be between %d and %d", minFPS, maxFPS)})
+	}
+	if s.ActiveFPS < minFPS || s.ActiveFPS > maxFPS {
+		fields = append(fields, fieldError{Field: "active_fps", Message: fmt.Sprintf("must be between %d and %d", minFPS, maxFPS)})
+	} else if s.ActiveFPS < s.IdleFPS {
+		fields = append(fields, fieldError{Field: "active_fps", Message: "must not be lower than idle_fps"})
+	}
+	if s.IdleTimeoutMs < minTimeoutMs || s.IdleTimeoutMs > maxTimeoutMs {
+		fields = append(fields, fieldError{Field: "idle_timeout_ms", Message: fmt.Sprintf("must be between %d and %d", minTimeoutMs, maxTimeoutMs)})
+	}
+	if s.Port < 1 || s.Port > 65535 {
+		fields = append(fields, fieldError{Field: "port", Message: "must be between 1 and 65535"})
+	}
+	if s.PluginTimeoutMs < minTimeoutMs || s.PluginTimeoutMs > maxTimeoutMs {
+		fields = append(fields, fieldError{Field: "plugin_timeout_ms", Message: fmt.Sprintf("must be between %d and %d", minTimeoutMs, maxTimeoutMs)})
+	}
+	if s.MinMatchScore < 0 || s.MinMatchScore >= 1 {
+		fields = append(fields, fieldError{Field: "min_match_score", Message: "must be at least 0 and less than 1"})
+	}
+	if s.AmbiguityMargin < 0 || s.AmbiguityMargin >= 1 {
+		fields = append(fields, fieldError{Field: "ambiguity_margin", Message: "must be at least 0 and less than 1"})
+	}
+	return fields
+}
+
+// get handles GET /api/settings and returns the current settings.
+func (h *SettingsHandler) get(w http.ResponseWriter, r *http.Request) {
+	settings, err := h.store.Settings().Load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load settings")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, toSettingsResponse(settings))
+}
+
+// update handles PUT /api/settings, saves the changed settings and applies them.
+func (h *SettingsHandler) update(w http.ResponseWriter, r *http.Request) {
+	var req updateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid JSON")
+		return
+	}
+
+	current, err := h.store.Settings().Load()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load settings")
+		return
+	}
+
+	updated := current
+	req.apply(&updated)
+
+	if fields := validateSettings(updated); len(fields) > 0 {
+		writeFieldErrors(w, http.StatusBadRequest, "Invalid settings", fields)
+		return
+	}
+
+	if err := h.store.Settings().Save(updated); err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to save settings")
+		return
+	}
+
+	if h.apply != nil {
+		h.apply(updated)
+	}
+
+	response := toSettingsResponse(updated)
+	// The camera is opened and the server bound once at startup
+	if updated.CameraID != current.CameraID {
+		response.RestartRequired = append(response.RestartRequired, "camera_id")
+	}
+	if updated.Port != current.Port {
+		response.RestartRequired = append(response.RestartRequired, "port")
+	}
+
+	writeJSON(w, http.StatusOK, response)
+}
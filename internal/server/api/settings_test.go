@@ -0,0 +1,174 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// putSettings sends body to PUT /api/settings and returns the recorder.
+func putSettings(t *testing.T, handler http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodPut, "/api/settings", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestSettingsHandler_GetDefaults(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSettingsHandler(s, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/settings", nil)
+	rec := httptest.NewRecorder()
+
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response settingsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+
+	if !reflect.DeepEqual(response, toSettingsResponse(store.DefaultSettings())) {
+		t.Errorf("expected default settings, got %+v", response)
+	}
+}
+
+func TestSettingsHandler_Update(t *testing.T) {
+	s := newTestStore(t)
+
+	var applied *store.Settings
+	handler := NewSettingsHandler(s, func(settings store.Settings) {
+		applied = &settings
+	})
+
+	rec := putSettings(t, handler, `{"motion_threshold": 0.5, "active_fps": 30, "plugin_timeout_ms": 1000, "ambiguity_margin": 0.1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response settingsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if response.MotionThreshold != 0.5 || response.ActiveFPS != 30 || response.PluginTimeoutMs != 1000 {
+		t.Errorf("expected updated settings in response, got %+v", response)
+	}
+	if response.IdleFPS != store.DefaultIdleFPS {
+		t.Errorf("expected omitted idle_fps to keep its value, got %d", response.IdleFPS)
+	}
+	if len(response.RestartRequired) != 0 {
+		t.Errorf("expected no restart to be required, got %v", response.RestartRequired)
+	}
+
+	if response.AmbiguityMargin != 0.1 || response.MinMatchScore != store.DefaultMinMatchScore {
+		t.Errorf("expected the updated ambiguity margin in response, got %+v", response)
+	}
+
+	if applied == nil || applied.ActiveFPS != 30 || applied.AmbiguityMargin != 0.1 {
+		t.Errorf("expected the new settings to be applied, got %+v", applied)
+	}
+
+	saved, err := s.Settings().Load()
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if saved.MotionThreshold != 0.5 || saved.ActiveFPS != 30 || saved.PluginTimeoutMs != 1000 {
+		t.Errorf("expected settings to be saved, got %+v", saved)
+	}
+}
+
+func TestSettingsHandler_UpdateRestartRequired(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSettingsHandler(s, nil)
+
+	rec := putSettings(t, handler, `{"camera_id": 1, "port": 9090}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusOK, rec.Code, rec.Body.String())
+	}
+
+	var response settingsResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.RestartRequired) != 2 || response.RestartRequired[0] != "camera_id" || response.RestartRequired[1] != "port" {
+		t.Errorf("expected camera_id and port to require a restart, got %v", response.RestartRequired)
+	}
+
+	// Saving the same values again needs no further restart
+	rec = putSettings(t, handler, `{"camera_id": 1, "port": 9090}`)
+	response = settingsResponse{}
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.RestartRequired) != 0 {
+		t.Errorf("expected no restart for unchanged values, got %v", response.RestartRequired)
+	}
+}
+
+func TestSettingsHandler_UpdateValidation(t *testing.T) {
+	tests := []struct {
+		name  string
+		body  string
+		field string
+	}{
+		{"negative camera", `{"camera_id": -1}`, "camera_id"},
+		{"zero threshold", `{"motion_threshold": 0}`, "motion_threshold"},
+		{"threshold above 100", `{"motion_threshold": 150}`, "motion_threshold"},
+		{"idle fps too low", `{"idle_fps": 0}`, "idle_fps"},
+		{"active fps too high", `{"active_fps": 120}`, "active_fps"},
+		{"active below idle", `{"idle_fps": 20, "active_fps": 10}`, "active_fps"},
+		{"idle timeout too short", `{"idle_timeout_ms": 10}`, "idle_timeout_ms"},
+		{"port out of range", `{"port": 70000}`, "port"},
+		{"plugin timeout too long", `{"plugin_timeout_ms": 120000}`, "plugin_timeout_ms"},
+		{"negative match score", `{"min_match_score": -0.1}`, "min_match_score"},
+		{"match score of 1", `{"min_match_score": 1}`, "min_match_score"},
+		{"ambiguity margin of 1", `{"ambiguity_margin": 1}`, "ambiguity_margin"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			applied := false
+			handler := NewSettingsHandler(s, func(store.Settings) { applied = true })
+
+			rec := putSettings(t, handler, tt.body)
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("expected status %d, got %d: %s", http.StatusBadRequest, rec.Code, rec.Body.String())
+			}
+
+			var response errorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(response.Fields) != 1 || response.Fields[0].Field != tt.field {
+				t.Errorf("expected an error for %s, got %+v", tt.field, response.Fields)
+			}
+			if applied {
+				t.Error("expected invalid settings not to be applied")
+			}
+		})
+	}
+}
+
+func TestSettingsHandler_InvalidJSON(t *testing.T) {
+	s := newTestStore(t)
+	handler := NewSettingsHandler(s, nil)
+
+	rec := putSettings(t, handler, `{not json`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rec.Code)
+	}
+}
@@ -0,0 +1,318 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/ayusman/kuchipudi/internal/detector"
+	"github.com/ayusman/kuchipudi/internal/gesture"
+	"github.com/ayusman/kuchipudi/internal/store"
+)
+
+// errNoSamples is returned when a gesture has no recorded samples to train on.
+var errNoSamples = errors.New("no samples recorded for gesture")
+
+// TrainHandler handles HTTP requests that retrain a gesture template from its samples.
+type TrainHandler struct {
+	store   *store.Store
+	trainer *gesture.Trainer
+}
+
+// NewTrainHandler creates a new TrainHandler with the given store.
+func NewTrainHandler(s *store.Store) *TrainHandler {
+	return &TrainHandler{
+		store:   s,
+		trainer: gesture.NewTrainer(),
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+// Expected paths: /api/gestures/{id}/train
+func (h *TrainHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	// Parse gesture ID from path: /api/gestures/{id}/train
+	path := strings.TrimPrefix(r.URL.Path, "/api/gestures/")
+	parts := strings.Split(path, "/")
+
+	if len(parts) != 2 || parts[1] != "train" {
+		writeError(w, http.StatusNotFound, "Not found")
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.train(w, r, parts[0])
+}
+
+// Response types
+
+type trainingResponse struct {
+	GestureID   string               `json:"gesture_id"`
+	Type        string               `json:"type"`
+	Samples     int                  `json:"samples"`
+	Points      int                  `json:"points"`
+	Handedness  string               `json:"handedness,omitempty"` // Hand a static template was recorded with
+	Outliers    []int                `json:"outliers,omitempty"`   // Static samples rejected as exemplars, by position
+	Calibration *gesture.Calibration `json:"calibration,omitempty"`
+	Diagnostics *gesture.Diagnostics `json:"diagnostics"`
+}
+
+// train handles POST /api/gestures/{id}/train
+func (h *TrainHandler) train(w http.ResponseWriter, r *http.Request, gestureID string) {
+	g, err := h.store.Gestures().GetByID(gestureID)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "Gesture not found")
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to get gesture")
+		return
+	}
+
+	samples, err := storedSampleData(h.store, gestureID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "Failed to load samples")
+		return
+	}
+
+	result, err := trainGesture(h.store, h.trainer, g, samples)
+	if err != nil {
+		if errors.Is(err, errNoSamples) {
+			writeError(w, http.StatusBadRequest, "No samples to train")
+			return
+		}
+		var trainErr *trainingError
+		if errors.As(err, &trainErr) {
+			writeError(w, http.StatusBadRequest, trainErr.Error())
+			return
+		}
+		writeError(w, http.StatusInternalServerError, "Failed to save template")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, result)
+}
+
+// trainingError wraps a trainer failure caused by invalid sample data.
+type trainingError struct {
+	err error
+}
+
+func (e *trainingError) Error() string {
+	return "Training failed: " + e.err.Error()
+}
+
+func (e *trainingError) Unwrap() error {
+	return e.err
+}
+
+// storedSampleData returns the raw data of every sample recorded for a gesture.
+func storedSampleData(s *store.Store, gestureID string) ([]json.RawMessage, error) {
+	samples, err := s.Samples().GetByGestureID(gestureID)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]json.RawMessage, 0, len(samples))
+	for _, sample := range samples {
+		data = append(data, sample.Data)
+	}
+	return data, nil
+}
+
+// template is a trained gesture template ready to be saved.
+// Only the fields for the gesture's type are set.
+type template struct {
+	landmarks   []store.Landmark  // Static and two-hand gestures
+	handedness  string            // Static gestures: the hand the samples were recorded with
+	outliers    []int             // Static gestures: positions of samples rejected as exemplars
+	pair        *store.HandPair   // Two-hand gestures
+	path        []store.PathPoint // Dynamic gestures
+	diag        *gesture.Diagnostics
+	calibration *gesture.Calibration // Set by calibrate
+}
+
+// computeTemplate runs the trainer over samples without persisting anything.
+// Invalid sample data is reported as a *trainingError.
+func computeTemplate(trainer *gesture.Trainer, g *store.Gesture, samples []json.RawMessage) (*template, error) {
+	if len(samples) == 0 {
+		return nil, errNoSamples
+	}
+
+	switch g.Type {
+	case store.GestureTypeDynamic:
+		path, err := trainer.TrainDynamic(samples)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+		diag, err := trainer.DiagnoseDynamicWith(samples, path, pathOptions(g))
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+
+		return &template{path: storePath(path), diag: diag}, nil
+
+	case store.GestureTypeTwoHand:
+		pair, err := trainer.TrainTwoHand(samples)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+		diag, err := trainer.DiagnoseTwoHand(samples, pair)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+
+		points := make([]store.Landmark, 0, len(pair.Left)+len(pair.Right))
+		for i, l := range pair.Left {
+			points = append(points, store.Landmark{Hand: store.HandLeft, Index: i, X: l.X, Y: l.Y, Z: l.Z})
+		}
+		for i, l := range pair.Right {
+			points = append(points, store.Landmark{Hand: store.HandRight, Index: i, X: l.X, Y: l.Y, Z: l.Z})
+		}
+		return &template{
+			landmarks: points,
+			pair: &store.HandPair{
+				OffsetX: pair.Offset.X,
+				OffsetY: pair.Offset.Y,
+				OffsetZ: pair.Offset.Z,
+				Scale:   pair.Scale,
+			},
+			diag: diag,
+		}, nil
+
+	default:
+		opts := staticOptions(g)
+		landmarks, err := trainer.TrainStaticWith(samples, opts)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+		// Outliers are flagged whatever the gesture matches by, so that
+		// switching to exemplars later does not need retraining
+		outliers, err := trainer.StaticOutliers(samples, opts)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+		var diag *gesture.Diagnostics
+		if opts.Neighbors > 0 {
+			diag, err = trainer.DiagnoseExemplars(samples, opts)
+		} else {
+			diag, err = trainer.DiagnoseStaticWith(samples, landmarks, opts)
+		}
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+		handedness, err := trainer.StaticHandedness(samples)
+		if err != nil {
+			return nil, &trainingError{err: err}
+		}
+
+		points := make([]store.Landmark, len(landmarks))
+		for i, l := range landmarks {
+			points[i] = store.Landmark{Index: i, X: l.X, Y: l.Y, Z: l.Z}
+		}
+		return &template{landmarks: points, handedness: handedness, outliers: outliers, diag: diag}, nil
+	}
+}
+
+// staticOptions returns how a static gesture compares poses with its template.
+func staticOptions(g *store.Gesture) gesture.StaticOptions {
+	return gesture.StaticOptions{
+		RotationInvariant: g.RotationInvariant,
+		Mode:              gesture.MatchMode(g.MatchMode),
+		Neighbors:         g.Neighbors,
+	}
+}
+
+// pathOptions returns how a dynamic gesture compares paths with its template.
+func pathOptions(g *store.Gesture) gesture.PathOptions {
+	opts := gesture.PathOptions{
+		Normalization: gesture.PathNormalization(g.PathNormalization),
+		Features:      gesture.PathFeatures(g.PathFeatures),
+	}
+	for _, t := range g.PathTrack {
+		opts.Track = append(opts.Track, gesture.Tracked(t))
+	}
+	return opts
+}
+
+// storePath converts a trained path to the points saved for its gesture.
+func storePath(path []gesture.PathPoint) []store.PathPoint {
+	points := make([]store.PathPoint, len(path))
+	for i, p := range path {
+		points[i] = store.PathPoint{Sequence: i, X: p.X, Y: p.Y, TimestampMs: p.Timestamp}
+		for j, l := range p.Hand {
+			points[i].Hand = append(points[i].Hand, store.Landmark{Index: j, X: l.X, Y: l.Y, Z: l.Z})
+		}
+	}
+	return points
+}
+
+// gesturePath converts the saved path of a gesture to the points the trainer compares.
+func gesturePath(path []store.PathPoint) []gesture.PathPoint {
+	points := make([]gesture.PathPoint, len(path))
+	for i, p := range path {
+		points[i] = gesture.PathPoint{X: p.X, Y: p.Y, Timestamp: p.TimestampMs}
+		for _, l := range p.Hand {
+			points[i].Hand = append(points[i].Hand, detector.Point3D{X: l.X, Y: l.Y, Z: l.Z})
+		}
+	}
+	return points
+}
+
+// trainGesture trains a template from samples and persists it for the gesture.
+func trainGesture(s *store.Store, trainer *gesture.Trainer, g *store.Gesture, samples []json.RawMessage) (*trainingResponse, error) {
+	t, err := computeTemplate(trainer, g, samples)
+	if err != nil {
+		return nil, err
+	}
+	if err := calibrate(s, trainer, g, samples, t); err != nil {
+		return nil, err
+	}
+
+	return saveTemplate(s, g, t, nil)
+}
+
+// saveTemplate persists a previously computed template, together with the new
+// samples it was trained on, if any, and builds the training response.
+func saveTemplate(s *store.Store, g *store.Gesture, t *template, samples []json.RawMessage) (*trainingResponse, error) {
+	result := &trainingResponse{
+		GestureID:   g.ID,
+		Type:        string(g.Type),
+		Samples:     t.diag.SampleCount,
+		Diagnostics: t.diag,
+		Calibration: t.calibration,
+	}
+	training := &store.Training{Samples: samples}
+
+	switch g.Type {
+	case store.GestureTypeDynamic:
+		training.Path = t.path
+		result.Points = len(t.path)
+
+	case store.GestureTypeTwoHand:
+		training.Landmarks = t.landmarks
+		training.Pair = t.pair
+		result.Points = len(t.landmarks)
+
+	default:
+		training.Landmarks = t.landmarks
+		training.Handedness = t.handedness
+		training.Outliers = t.outliers
+		result.Points = len(t.landmarks)
+		result.Handedness = t.handedness
+		result.Outliers = t.outliers
+	}
+
+	if t.calibration != nil {
+		training.RecommendedTolerance = t.calibration.Tolerance
+	}
+
+	if err := s.Gestures().SaveTraining(g.ID, training); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,91 @@
+// Package server provides the HTTP server for the Kuchipudi gesture recognition system.
+package server
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sync"
+
+	"github.com/gorilla/websocket"
+)
+
+// eventBuffer is how many events may wait to be sent to a client before
+// further events are dropped for it.
+const eventBuffer = 32
+
+// EventsHandler broadcasts the events of the detection pipeline, such as
+// gestures firing, to WebSocket clients.
+type EventsHandler struct {
+	clients map[chan []byte]bool
+	mu      sync.Mutex
+}
+
+// NewEventsHandler creates a new EventsHandler without clients.
+func NewEventsHandler() *EventsHandler {
+	return &EventsHandler{
+		clients: make(map[chan []byte]bool),
+	}
+}
+
+// Broadcast sends event, encoded as JSON, to every connected client.
+// It never blocks: clients that fall behind miss the event.
+func (h *EventsHandler) Broadcast(event any) {
+	msg, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("events: failed to encode event: %v", err)
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for client := range h.clients {
+		select {
+		case client <- msg:
+		default:
+		}
+	}
+}
+
+// ServeHTTP handles WebSocket upgrade requests.
+func (h *EventsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("websocket upgrade error: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	client := make(chan []byte, eventBuffer)
+	h.mu.Lock()
+	h.clients[client] = true
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.clients, client)
+		h.mu.Unlock()
+	}()
+
+	// Clients only listen; reading tells when they disconnect
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case msg := <-client:
+			if err := conn.WriteMessage(websocket.TextMessage, msg); err != nil {
+				return
+			}
+		}
+	}
+}
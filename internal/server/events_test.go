@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// clientCount returns how many clients are connected to h.
+func (h *EventsHandler) clientCount() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.clients)
+}
+
+func TestEventsHandler_Broadcast(t *testing.T) {
+	events := NewEventsHandler()
+	ts := httptest.NewServer(New(Config{Events: events}))
+	defer ts.Close()
+
+	url := "ws" + strings.TrimPrefix(ts.URL, "http") + "/api/events"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("failed to connect to /api/events: %v", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for events.clientCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the client to be registered")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	events.Broadcast(map[string]any{"outcome": "match", "gesture": "palm"})
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	var got struct {
+		Outcome string `json:"outcome"`
+		Gesture string `json:"gesture"`
+	}
+	if err := conn.ReadJSON(&got); err != nil {
+		t.Fatalf("failed to read event: %v", err)
+	}
+	if got.Outcome != "match" || got.Gesture != "palm" {
+		t.Errorf("expected the broadcast event, got %+v", got)
+	}
+
+	// Disconnected clients are forgotten
+	conn.Close()
+	deadline = time.Now().Add(time.Second)
+	for events.clientCount() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("expected the client to be removed after disconnecting")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	events.Broadcast(json.RawMessage(`{}`))
+}
+
+func TestEventsHandler_NotConfigured(t *testing.T) {
+	srv := New(Config{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/events", nil)
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("expected 404 without events configured, got %d", w.Code)
+	}
+}
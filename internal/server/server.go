@@ -9,6 +9,7 @@ import (
 
 	"github.com/ayusman/kuchipudi/internal/capture"
 	"github.com/ayusman/kuchipudi/internal/detector"
+	"github.com/ayusman/kuchipudi/internal/plugin"
 	"github.com/ayusman/kuchipudi/internal/server/api"
 	"github.com/ayusman/kuchipudi/internal/store"
 )
@@ -17,8 +18,14 @@ import (
 type Config struct {
 	StaticDir string
 	Store     *store.Store
-	Camera    capture.Camera
+	FrameHub  *capture.FrameHub
 	Detector  detector.Detector
+	Plugins   *plugin.Manager
+	Executor  *plugin.Executor
+	Events    *EventsHandler // Serves the pipeline's events at /api/events, if set
+
+	// ApplySettings is called after the settings are updated through the API
+	ApplySettings func(store.Settings)
 }
 
 // Server represents the HTTP server for the Kuchipudi application.
@@ -47,15 +54,21 @@ func (s *Server) setupRoutes() {
 	if s.config.Store != nil {
 		gestureHandler := api.NewGestureHandler(s.config.Store)
 		samplesHandler := api.NewSamplesHandler(s.config.Store)
-		actionHandler := api.NewActionHandler(s.config.Store)
+		trainHandler := api.NewTrainHandler(s.config.Store)
+		actionHandler := api.NewActionHandler(s.config.Store, s.config.Plugins)
 
-		// Use a wrapper to route between gestures and samples handlers
+		// Use a wrapper to route between gestures, samples and training handlers
 		gestureRouter := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// Check if this is a samples request: /api/gestures/{id}/samples
 			if strings.HasSuffix(r.URL.Path, "/samples") {
 				samplesHandler.ServeHTTP(w, r)
 				return
 			}
+			// Check if this is a training request: /api/gestures/{id}/train
+			if strings.HasSuffix(r.URL.Path, "/train") {
+				trainHandler.ServeHTTP(w, r)
+				return
+			}
 			gestureHandler.ServeHTTP(w, r)
 		})
 
@@ -63,20 +76,37 @@ func (s *Server) setupRoutes() {
 		s.mux.Handle("/api/gestures/", gestureRouter)
 		s.mux.Handle("/api/actions", actionHandler)
 		s.mux.Handle("/api/actions/", actionHandler)
+		s.mux.Handle("/api/history", api.NewHistoryHandler(s.config.Store))
+		bundleHandler := api.NewBundleHandler(s.config.Store)
+		s.mux.Handle("/api/export", bundleHandler)
+		s.mux.Handle("/api/import", bundleHandler)
+		s.mux.Handle("/api/settings", api.NewSettingsHandler(s.config.Store, s.config.ApplySettings))
 	}
 
-	// Register camera stream endpoint if Camera is configured
-	if s.config.Camera != nil {
-		streamHandler := NewStreamHandler(s.config.Camera)
+	// Register plugin API handler if the plugin manager is configured
+	if s.config.Plugins != nil {
+		pluginHandler := api.NewPluginHandler(s.config.Plugins, s.config.Executor)
+		s.mux.Handle("/api/plugins", pluginHandler)
+		s.mux.Handle("/api/plugins/", pluginHandler)
+	}
+
+	// Register camera stream endpoint if FrameHub is configured
+	if s.config.FrameHub != nil {
+		streamHandler := NewStreamHandler(s.config.FrameHub)
 		s.mux.Handle("/api/stream", streamHandler)
 	}
 
-	// Register landmarks WebSocket endpoint if Camera and Detector are configured
-	if s.config.Camera != nil && s.config.Detector != nil {
-		landmarksHandler := NewLandmarksHandler(s.config.Detector, s.config.Camera)
+	// Register landmarks WebSocket endpoint if FrameHub and Detector are configured
+	if s.config.FrameHub != nil && s.config.Detector != nil {
+		landmarksHandler := NewLandmarksHandler(s.config.Detector, s.config.FrameHub)
 		s.mux.Handle("/api/landmarks", landmarksHandler)
 	}
 
+	// Register pipeline events WebSocket endpoint if Events is configured
+	if s.config.Events != nil {
+		s.mux.Handle("/api/events", s.config.Events)
+	}
+
 	// Serve static files if StaticDir is configured
 	if s.config.StaticDir != "" {
 		fs := http.FileServer(http.Dir(s.config.StaticDir))
@@ -3,21 +3,24 @@ package server
 
 import (
 	"fmt"
+	"log"
 	"net/http"
-	"time"
 
 	"github.com/ayusman/kuchipudi/internal/capture"
 	"gocv.io/x/gocv"
 )
 
-// StreamHandler serves MJPEG frames from the camera.
+// streamFPS is the frame rate of the MJPEG stream.
+const streamFPS = 15
+
+// StreamHandler serves MJPEG frames from the shared camera.
 type StreamHandler struct {
-	camera capture.Camera
+	frames *capture.FrameHub
 }
 
-// NewStreamHandler creates a new StreamHandler with the given camera.
-func NewStreamHandler(camera capture.Camera) *StreamHandler {
-	return &StreamHandler{camera: camera}
+// NewStreamHandler creates a new StreamHandler that reads frames from the given hub.
+func NewStreamHandler(frames *capture.FrameHub) *StreamHandler {
+	return &StreamHandler{frames: frames}
 }
 
 // ServeHTTP streams MJPEG frames to connected clients.
@@ -27,26 +30,33 @@ func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sub, err := h.frames.Subscribe(streamFPS)
+	if err != nil {
+		log.Printf("stream: failed to open camera: %v", err)
+		http.Error(w, "Camera unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	defer sub.Close()
+
 	w.Header().Set("Content-Type", "multipart/x-mixed-replace; boundary=frame")
 	w.Header().Set("Cache-Control", "no-cache")
 	w.Header().Set("Connection", "keep-alive")
 
 	for {
+		var frame *capture.SharedFrame
 		select {
 		case <-r.Context().Done():
 			return
-		default:
-		}
-
-		frame, err := h.camera.ReadFrame()
-		if err != nil {
-			time.Sleep(100 * time.Millisecond)
-			continue
+		case f, ok := <-sub.Frames():
+			if !ok {
+				return
+			}
+			frame = f
 		}
 
 		// Encode as JPEG
-		buf, err := gocv.IMEncode(".jpg", *frame)
-		frame.Close()
+		buf, err := gocv.IMEncode(".jpg", *frame.Mat)
+		frame.Release()
 		if err != nil {
 			continue
 		}
@@ -62,7 +72,5 @@ func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		if f, ok := w.(http.Flusher); ok {
 			f.Flush()
 		}
-
-		time.Sleep(66 * time.Millisecond) // ~15 FPS
 	}
 }
@@ -6,7 +6,6 @@ import (
 	"log"
 	"net/http"
 	"sync"
-	"time"
 
 	"github.com/ayusman/kuchipudi/internal/capture"
 	"github.com/ayusman/kuchipudi/internal/detector"
@@ -19,23 +18,26 @@ var upgrader = websocket.Upgrader{
 	},
 }
 
+// landmarksFPS is the rate at which landmarks are broadcast to clients.
+const landmarksFPS = 15
+
 // LandmarksHandler broadcasts real-time hand landmarks via WebSocket.
+// It subscribes to the shared frame hub while at least one client is connected.
 type LandmarksHandler struct {
 	detector detector.Detector
-	camera   capture.Camera
+	frames   *capture.FrameHub
 	clients  map[*websocket.Conn]bool
+	sub      *capture.Subscription
 	mu       sync.RWMutex
 }
 
-// NewLandmarksHandler creates a new LandmarksHandler with the given detector and camera.
-func NewLandmarksHandler(d detector.Detector, c capture.Camera) *LandmarksHandler {
-	h := &LandmarksHandler{
+// NewLandmarksHandler creates a new LandmarksHandler with the given detector and frame hub.
+func NewLandmarksHandler(d detector.Detector, frames *capture.FrameHub) *LandmarksHandler {
+	return &LandmarksHandler{
 		detector: d,
-		camera:   c,
+		frames:   frames,
 		clients:  make(map[*websocket.Conn]bool),
 	}
-	go h.broadcast()
-	return h
 }
 
 // ServeHTTP handles WebSocket upgrade requests.
@@ -48,26 +50,35 @@ func (h *LandmarksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	defer conn.Close()
 
 	h.mu.Lock()
-	wasFirstClient := len(h.clients) == 0
 	h.clients[conn] = true
-	h.mu.Unlock()
 
-	// Open camera on first connection
-	if wasFirstClient {
-		if err := h.camera.Open(); err != nil {
+	// Subscribe to camera frames on first connection
+	if h.sub == nil {
+		sub, err := h.frames.Subscribe(landmarksFPS)
+		if err != nil {
+			delete(h.clients, conn)
+			h.mu.Unlock()
+			log.Printf("landmarks: failed to open camera: %v", err)
 			return
 		}
+		h.sub = sub
+		go h.broadcast(sub)
 	}
+	h.mu.Unlock()
 
 	defer func() {
 		h.mu.Lock()
 		delete(h.clients, conn)
-		wasLastClient := len(h.clients) == 0
+		var sub *capture.Subscription
+		if len(h.clients) == 0 {
+			sub = h.sub
+			h.sub = nil
+		}
 		h.mu.Unlock()
 
-		// Close camera when last client disconnects
-		if wasLastClient {
-			h.camera.Close()
+		// Release the camera when last client disconnects
+		if sub != nil {
+			sub.Close()
 		}
 	}()
 
@@ -79,33 +90,18 @@ func (h *LandmarksHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// broadcast sends landmark data to all connected clients.
-func (h *LandmarksHandler) broadcast() {
-	ticker := time.NewTicker(66 * time.Millisecond) // ~15 FPS
-	defer ticker.Stop()
-
-	for range ticker.C {
-		h.mu.RLock()
-		if len(h.clients) == 0 {
-			h.mu.RUnlock()
-			continue
-		}
-		h.mu.RUnlock()
-
-		frame, err := h.camera.ReadFrame()
-		if err != nil {
-			continue
-		}
-
-		hands, err := h.detector.Detect(frame)
-		frame.Close()
+// broadcast sends landmark data to all connected clients until the subscription is closed.
+func (h *LandmarksHandler) broadcast(sub *capture.Subscription) {
+	for frame := range sub.Frames() {
+		hands, err := h.detector.Detect(frame.Mat)
+		frame.Release()
 		if err != nil {
 			continue
 		}
 
 		msg, _ := json.Marshal(map[string]any{
 			"hands":     hands,
-			"timestamp": time.Now().UnixMilli(),
+			"timestamp": frame.Timestamp.UnixMilli(),
 		})
 
 		h.mu.RLock()
@@ -7,15 +7,40 @@ import (
 	"time"
 )
 
+// RepeatMode controls how often an action fires while its gesture is held.
+type RepeatMode string
+
+const (
+	// RepeatOnce fires the action once per activation.
+	RepeatOnce RepeatMode = "once"
+	// RepeatInterval fires the action again at a fixed interval while the gesture is held.
+	RepeatInterval RepeatMode = "repeat"
+	// RepeatAccelerate fires the action again while the gesture is held,
+	// shortening the interval after each repeat down to a minimum.
+	RepeatAccelerate RepeatMode = "accelerate"
+)
+
+// Default repeat settings for new actions.
+const (
+	// DefaultRepeatIntervalMs is the default time between repeats.
+	DefaultRepeatIntervalMs = 500
+	// DefaultRepeatMinIntervalMs is the default shortest interval an accelerating repeat reaches.
+	DefaultRepeatMinIntervalMs = 100
+)
+
 // Action represents a gesture-to-plugin binding stored in the database.
 type Action struct {
-	ID         string
-	GestureID  string
-	PluginName string
-	ActionName string
-	Config     json.RawMessage
-	Enabled    bool
-	CreatedAt  time.Time
+	ID                  string
+	GestureID           string
+	PluginName          string
+	ActionName          string
+	Config              json.RawMessage
+	Params              json.RawMessage // Parameters forwarded to the plugin, may contain {{placeholders}}
+	Enabled             bool
+	RepeatMode          RepeatMode // How the action repeats while its gesture is held
+	RepeatIntervalMs    int        // Time between repeats (initial time for RepeatAccelerate)
+	RepeatMinIntervalMs int        // Shortest time between repeats for RepeatAccelerate
+	CreatedAt           time.Time
 }
 
 // ActionRepository provides CRUD operations for actions.
@@ -28,6 +53,29 @@ func (s *Store) Actions() *ActionRepository {
 	return &ActionRepository{db: s.db}
 }
 
+// actionColumns lists the actions columns in the order scanAction expects them.
+const actionColumns = `id, gesture_id, plugin_name, action_name, config, params, enabled, repeat_mode, repeat_interval_ms, repeat_min_interval_ms, created_at`
+
+// scanAction scans a row selected with actionColumns.
+func scanAction(row interface{ Scan(...any) error }) (*Action, error) {
+	a := &Action{}
+	var config, params string
+	var enabled int
+	var repeatMode string
+
+	err := row.Scan(&a.ID, &a.GestureID, &a.PluginName, &a.ActionName, &config, &params, &enabled,
+		&repeatMode, &a.RepeatIntervalMs, &a.RepeatMinIntervalMs, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	a.Config = json.RawMessage(config)
+	a.Params = json.RawMessage(params)
+	a.Enabled = enabled != 0
+	a.RepeatMode = RepeatMode(repeatMode)
+	return a, nil
+}
+
 // Create inserts a new action into the database.
 func (r *ActionRepository) Create(a *Action) error {
 	a.CreatedAt = time.Now()
@@ -37,26 +85,30 @@ func (r *ActionRepository) Create(a *Action) error {
 		config = json.RawMessage("{}")
 	}
 
+	params := a.Params
+	if params == nil {
+		params = json.RawMessage("{}")
+	}
+
+	if a.RepeatMode == "" {
+		a.RepeatMode = RepeatOnce
+	}
+
 	_, err := r.db.Exec(
-		`INSERT INTO actions (id, gesture_id, plugin_name, action_name, config, enabled, created_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		a.ID, a.GestureID, a.PluginName, a.ActionName, string(config), a.Enabled, a.CreatedAt,
+		`INSERT INTO actions (`+actionColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.ID, a.GestureID, a.PluginName, a.ActionName, string(config), string(params), a.Enabled,
+		string(a.RepeatMode), a.RepeatIntervalMs, a.RepeatMinIntervalMs, a.CreatedAt,
 	)
 	return err
 }
 
 // GetByID retrieves an action by its ID.
 func (r *ActionRepository) GetByID(id string) (*Action, error) {
-	a := &Action{}
-	var config string
-	var enabled int
-
-	err := r.db.QueryRow(
-		`SELECT id, gesture_id, plugin_name, action_name, config, enabled, created_at
-		 FROM actions WHERE id = ?`,
+	a, err := scanAction(r.db.QueryRow(
+		`SELECT `+actionColumns+` FROM actions WHERE id = ?`,
 		id,
-	).Scan(&a.ID, &a.GestureID, &a.PluginName, &a.ActionName, &config, &enabled, &a.CreatedAt)
-
+	))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -64,24 +116,16 @@ func (r *ActionRepository) GetByID(id string) (*Action, error) {
 		return nil, err
 	}
 
-	a.Config = json.RawMessage(config)
-	a.Enabled = enabled != 0
 	return a, nil
 }
 
 // GetByGestureID retrieves an action by its gesture ID.
 // Returns nil, nil if no action is bound to the gesture.
 func (r *ActionRepository) GetByGestureID(gestureID string) (*Action, error) {
-	a := &Action{}
-	var config string
-	var enabled int
-
-	err := r.db.QueryRow(
-		`SELECT id, gesture_id, plugin_name, action_name, config, enabled, created_at
-		 FROM actions WHERE gesture_id = ?`,
+	a, err := scanAction(r.db.QueryRow(
+		`SELECT `+actionColumns+` FROM actions WHERE gesture_id = ?`,
 		gestureID,
-	).Scan(&a.ID, &a.GestureID, &a.PluginName, &a.ActionName, &config, &enabled, &a.CreatedAt)
-
+	))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil // Silent skip - no action bound
@@ -89,16 +133,13 @@ func (r *ActionRepository) GetByGestureID(gestureID string) (*Action, error) {
 		return nil, err
 	}
 
-	a.Config = json.RawMessage(config)
-	a.Enabled = enabled != 0
 	return a, nil
 }
 
 // List retrieves all actions from the database.
 func (r *ActionRepository) List() ([]*Action, error) {
 	rows, err := r.db.Query(
-		`SELECT id, gesture_id, plugin_name, action_name, config, enabled, created_at
-		 FROM actions ORDER BY created_at DESC`,
+		`SELECT ` + actionColumns + ` FROM actions ORDER BY created_at DESC`,
 	)
 	if err != nil {
 		return nil, err
@@ -107,17 +148,10 @@ func (r *ActionRepository) List() ([]*Action, error) {
 
 	var actions []*Action
 	for rows.Next() {
-		a := &Action{}
-		var config string
-		var enabled int
-
-		err := rows.Scan(&a.ID, &a.GestureID, &a.PluginName, &a.ActionName, &config, &enabled, &a.CreatedAt)
+		a, err := scanAction(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		a.Config = json.RawMessage(config)
-		a.Enabled = enabled != 0
 		actions = append(actions, a)
 	}
 
@@ -135,15 +169,26 @@ func (r *ActionRepository) Update(a *Action) error {
 		config = json.RawMessage("{}")
 	}
 
+	params := a.Params
+	if params == nil {
+		params = json.RawMessage("{}")
+	}
+
 	enabled := 0
 	if a.Enabled {
 		enabled = 1
 	}
 
+	if a.RepeatMode == "" {
+		a.RepeatMode = RepeatOnce
+	}
+
 	result, err := r.db.Exec(
-		`UPDATE actions SET gesture_id = ?, plugin_name = ?, action_name = ?, config = ?, enabled = ?
+		`UPDATE actions SET gesture_id = ?, plugin_name = ?, action_name = ?, config = ?, params = ?, enabled = ?,
+		 repeat_mode = ?, repeat_interval_ms = ?, repeat_min_interval_ms = ?
 		 WHERE id = ?`,
-		a.GestureID, a.PluginName, a.ActionName, string(config), enabled, a.ID,
+		a.GestureID, a.PluginName, a.ActionName, string(config), string(params), enabled,
+		string(a.RepeatMode), a.RepeatIntervalMs, a.RepeatMinIntervalMs, a.ID,
 	)
 	if err != nil {
 		return err
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestActionRepository_RepeatSettings(t *testing.T) {
+	s := newTestStore(t)
+
+	if err := s.Gestures().Create(&Gesture{ID: "g1", Name: "palm", Type: GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	repo := s.Actions()
+	action := &Action{
+		ID:         "a1",
+		GestureID:  "g1",
+		PluginName: "system-control",
+		ActionName: "volume-up",
+		Enabled:    true,
+	}
+	if err := repo.Create(action); err != nil {
+		t.Fatalf("failed to create action: %v", err)
+	}
+
+	got, err := repo.GetByID("a1")
+	if err != nil {
+		t.Fatalf("failed to get action: %v", err)
+	}
+	if got.RepeatMode != RepeatOnce {
+		t.Errorf("expected default repeat mode %q, got %q", RepeatOnce, got.RepeatMode)
+	}
+
+	got.RepeatMode = RepeatAccelerate
+	got.RepeatIntervalMs = 400
+	got.RepeatMinIntervalMs = 80
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update action: %v", err)
+	}
+
+	got, err = repo.GetByGestureID("g1")
+	if err != nil {
+		t.Fatalf("failed to get action: %v", err)
+	}
+	if got.RepeatMode != RepeatAccelerate || got.RepeatIntervalMs != 400 || got.RepeatMinIntervalMs != 80 {
+		t.Errorf("repeat settings not persisted: %+v", got)
+	}
+}
+
+func TestActionRepository_Params(t *testing.T) {
+	s := newTestStore(t)
+
+	if err := s.Gestures().Create(&Gesture{ID: "g1", Name: "palm", Type: GestureTypeStatic}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	repo := s.Actions()
+	action := &Action{
+		ID:         "a1",
+		GestureID:  "g1",
+		PluginName: "keyboard",
+		ActionName: "keystroke",
+		Params:     json.RawMessage(`{"key":"c","modifiers":["command"]}`),
+		Enabled:    true,
+	}
+	if err := repo.Create(action); err != nil {
+		t.Fatalf("failed to create action: %v", err)
+	}
+
+	got, err := repo.GetByID("a1")
+	if err != nil {
+		t.Fatalf("failed to get action: %v", err)
+	}
+	if string(got.Params) != `{"key":"c","modifiers":["command"]}` {
+		t.Errorf("params not persisted: %s", got.Params)
+	}
+
+	got.Params = nil
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update action: %v", err)
+	}
+
+	got, err = repo.GetByID("a1")
+	if err != nil {
+		t.Fatalf("failed to get action: %v", err)
+	}
+	if string(got.Params) != "{}" {
+		t.Errorf("expected empty params object, got %s", got.Params)
+	}
+}
@@ -0,0 +1,535 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Bundle format identifiers. BundleVersion is bumped whenever the bundle
+// layout changes in a way older versions cannot read.
+const (
+	BundleFormat  = "kuchipudi-gestures"
+	BundleVersion = 1
+)
+
+// ErrInvalidBundle is returned when a bundle cannot be imported.
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+// Bundle is a portable set of gestures with their templates, recorded samples
+// and action bindings. Gestures are identified by name; IDs are assigned on import.
+type Bundle struct {
+	Format     string          `json:"format"`
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exported_at"`
+	Gestures   []BundleGesture `json:"gestures"`
+}
+
+// BundleGesture is a gesture as stored in a bundle.
+type BundleGesture struct {
+	Name                 string            `json:"name"`
+	Type                 GestureType       `json:"type"`
+	Tolerance            float64           `json:"tolerance"`
+	HoldMs               int               `json:"hold_ms"`
+	MinFrames            int               `json:"min_frames"`
+	CooldownMs           int               `json:"cooldown_ms"`
+	FireOnRelease        bool              `json:"fire_on_release"`
+	Handedness           Handedness        `json:"handedness,omitempty"`
+	TemplateHandedness   string            `json:"template_handedness,omitempty"` // Hand the template was recorded with
+	RotationInvariant    bool              `json:"rotation_invariant,omitempty"`
+	MatchMode            MatchMode         `json:"match_mode,omitempty"`
+	Neighbors            int               `json:"neighbors,omitempty"`
+	AutoTolerance        bool              `json:"auto_tolerance,omitempty"`
+	RecommendedTolerance float64           `json:"recommended_tolerance,omitempty"` // Tolerance the template's training recommended
+	PathNormalization    PathNormalization `json:"path_normalization,omitempty"`
+	PathFeatures         PathFeatures      `json:"path_features,omitempty"`
+	PathTrack            []Tracked         `json:"path_track,omitempty"`
+	Landmarks            []Landmark        `json:"landmarks,omitempty"` // Trained template of a static or two-hand gesture
+	HandPair             *HandPair         `json:"hand_pair,omitempty"` // Placement of the hands of a two-hand gesture
+	Path                 []PathPoint       `json:"path,omitempty"`      // Trained template of a dynamic gesture
+	Samples              []json.RawMessage `json:"samples,omitempty"`   // Raw recorded samples
+	Outliers             []int             `json:"outliers,omitempty"`  // Positions in Samples that training rejected
+	Actions              []BundleAction    `json:"actions,omitempty"`
+}
+
+// BundleAction is an action binding as stored in a bundle.
+type BundleAction struct {
+	PluginName          string          `json:"plugin_name"`
+	ActionName          string          `json:"action_name"`
+	Config              json.RawMessage `json:"config,omitempty"`
+	Params              json.RawMessage `json:"params,omitempty"`
+	Enabled             bool            `json:"enabled"`
+	RepeatMode          RepeatMode      `json:"repeat_mode,omitempty"`
+	RepeatIntervalMs    int             `json:"repeat_interval_ms"`
+	RepeatMinIntervalMs int             `json:"repeat_min_interval_ms"`
+}
+
+// HasTemplate reports whether the gesture carries a trained template.
+func (g *BundleGesture) HasTemplate() bool {
+	return len(g.Landmarks) > 0 || len(g.Path) > 0
+}
+
+// Validate checks that the bundle can be imported by this version.
+// The returned error wraps ErrInvalidBundle.
+func (b *Bundle) Validate() error {
+	if b.Format != BundleFormat {
+		return fmt.Errorf("%w: unknown format %q", ErrInvalidBundle, b.Format)
+	}
+	if b.Version < 1 || b.Version > BundleVersion {
+		return fmt.Errorf("%w: unsupported version %d, this version supports up to %d",
+			ErrInvalidBundle, b.Version, BundleVersion)
+	}
+
+	for i, g := range b.Gestures {
+		if g.Name == "" {
+			return fmt.Errorf("%w: gesture %d has no name", ErrInvalidBundle, i)
+		}
+		if g.Type != GestureTypeStatic && g.Type != GestureTypeDynamic && g.Type != GestureTypeTwoHand {
+			return fmt.Errorf("%w: gesture %q has invalid type %q", ErrInvalidBundle, g.Name, g.Type)
+		}
+		if g.Handedness != "" && !g.Handedness.Valid() {
+			return fmt.Errorf("%w: gesture %q has invalid handedness %q", ErrInvalidBundle, g.Name, g.Handedness)
+		}
+		if g.MatchMode != "" && !g.MatchMode.Valid() {
+			return fmt.Errorf("%w: gesture %q has invalid match_mode %q", ErrInvalidBundle, g.Name, g.MatchMode)
+		}
+		if g.PathNormalization != "" && !g.PathNormalization.Valid() {
+			return fmt.Errorf("%w: gesture %q has invalid path_normalization %q", ErrInvalidBundle, g.Name, g.PathNormalization)
+		}
+		if g.PathFeatures != "" && !g.PathFeatures.Valid() {
+			return fmt.Errorf("%w: gesture %q has invalid path_features %q", ErrInvalidBundle, g.Name, g.PathFeatures)
+		}
+		for _, t := range g.PathTrack {
+			if !t.Valid() {
+				return fmt.Errorf("%w: gesture %q has invalid path_track entry %q", ErrInvalidBundle, g.Name, t)
+			}
+		}
+		if g.Neighbors < 0 {
+			return fmt.Errorf("%w: gesture %q has negative neighbors", ErrInvalidBundle, g.Name)
+		}
+		for _, i := range g.Outliers {
+			if i < 0 || i >= len(g.Samples) {
+				return fmt.Errorf("%w: gesture %q has outlier %d outside its samples", ErrInvalidBundle, g.Name, i)
+			}
+		}
+		for _, a := range g.Actions {
+			if a.PluginName == "" || a.ActionName == "" {
+				return fmt.Errorf("%w: gesture %q has an action without plugin_name or action_name", ErrInvalidBundle, g.Name)
+			}
+			switch a.RepeatMode {
+			case "", RepeatOnce, RepeatInterval, RepeatAccelerate:
+			default:
+				return fmt.Errorf("%w: gesture %q has an action with invalid repeat_mode %q", ErrInvalidBundle, g.Name, a.RepeatMode)
+			}
+		}
+	}
+
+	return nil
+}
+
+// ExportOptions selects what Export writes. The zero value exports everything.
+type ExportOptions struct {
+	GestureIDs       []string // Gestures to export, all gestures if empty
+	ExcludeTemplates bool
+	ExcludeSamples   bool
+	ExcludeActions   bool
+}
+
+// ConflictStrategy decides what Import does with a gesture whose name is already taken.
+type ConflictStrategy string
+
+const (
+	// ConflictSkip leaves the existing gesture alone and does not import the new one.
+	ConflictSkip ConflictStrategy = "skip"
+	// ConflictRename imports the new gesture under a free name such as "wave (2)".
+	ConflictRename ConflictStrategy = "rename"
+	// ConflictOverwrite replaces the existing gesture, keeping its ID.
+	ConflictOverwrite ConflictStrategy = "overwrite"
+)
+
+// ImportStatus describes what happened to one gesture of an imported bundle.
+type ImportStatus string
+
+const (
+	ImportCreated     ImportStatus = "created"
+	ImportSkipped     ImportStatus = "skipped"
+	ImportRenamed     ImportStatus = "renamed"
+	ImportOverwritten ImportStatus = "overwritten"
+)
+
+// ImportResult reports the outcome of importing one gesture.
+type ImportResult struct {
+	Name          string // Name in the bundle
+	GestureID     string // ID of the imported or existing gesture
+	ImportedAs    string // Name the gesture was saved under, empty if skipped
+	Status        ImportStatus
+	NeedsTraining bool // Samples were imported without a template
+}
+
+// BundleRepository exports and imports gesture bundles.
+type BundleRepository struct {
+	db     *sql.DB
+	notify GestureChangeFunc
+}
+
+// Bundles returns the bundle repository for this store.
+func (s *Store) Bundles() *BundleRepository {
+	return &BundleRepository{db: s.db, notify: s.notifyGestureChange}
+}
+
+// Export builds a bundle of the selected gestures, ordered by name.
+// Returns an error wrapping ErrNotFound if a selected gesture does not exist.
+func (r *BundleRepository) Export(opts ExportOptions) (*Bundle, error) {
+	gestures := &GestureRepository{db: r.db}
+
+	var selected []*Gesture
+	if len(opts.GestureIDs) == 0 {
+		all, err := gestures.List()
+		if err != nil {
+			return nil, err
+		}
+		selected = all
+	} else {
+		for _, id := range opts.GestureIDs {
+			g, err := gestures.GetByID(id)
+			if err != nil {
+				return nil, fmt.Errorf("gesture %s: %w", id, err)
+			}
+			selected = append(selected, g)
+		}
+	}
+
+	bundle := &Bundle{
+		Format:     BundleFormat,
+		Version:    BundleVersion,
+		ExportedAt: time.Now().UTC(),
+		Gestures:   make([]BundleGesture, 0, len(selected)),
+	}
+
+	for _, g := range selected {
+		bg := BundleGesture{
+			Name:              g.Name,
+			Type:              g.Type,
+			Tolerance:         g.Tolerance,
+			HoldMs:            g.HoldMs,
+			MinFrames:         g.MinFrames,
+			CooldownMs:        g.CooldownMs,
+			FireOnRelease:     g.FireOnRelease,
+			Handedness:        g.Handedness,
+			RotationInvariant: g.RotationInvariant,
+			MatchMode:         g.MatchMode,
+			Neighbors:         g.Neighbors,
+			AutoTolerance:     g.AutoTolerance,
+			PathNormalization: g.PathNormalization,
+			PathFeatures:      g.PathFeatures,
+			PathTrack:         g.PathTrack,
+		}
+
+		if !opts.ExcludeTemplates {
+			bg.TemplateHandedness = g.TemplateHandedness
+			bg.RecommendedTolerance = g.RecommendedTolerance
+			landmarks, err := gestures.GetLandmarks(g.ID)
+			if err != nil {
+				return nil, err
+			}
+			path, err := gestures.GetPath(g.ID)
+			if err != nil {
+				return nil, err
+			}
+			pair, err := gestures.GetHandPair(g.ID)
+			if err != nil {
+				return nil, err
+			}
+			bg.Landmarks, bg.Path, bg.HandPair = landmarks, path, pair
+		}
+
+		if !opts.ExcludeSamples {
+			samples, err := (&SampleRepository{db: r.db}).GetByGestureID(g.ID)
+			if err != nil {
+				return nil, err
+			}
+			for i, s := range samples {
+				bg.Samples = append(bg.Samples, s.Data)
+				if s.Outlier {
+					bg.Outliers = append(bg.Outliers, i)
+				}
+			}
+		}
+
+		if !opts.ExcludeActions {
+			actions, err := r.gestureActions(g.ID)
+			if err != nil {
+				return nil, err
+			}
+			bg.Actions = actions
+		}
+
+		bundle.Gestures = append(bundle.Gestures, bg)
+	}
+
+	// Order by name so exports of the same gestures are identical
+	sort.Slice(bundle.Gestures, func(i, j int) bool {
+		return bundle.Gestures[i].Name < bundle.Gestures[j].Name
+	})
+	return bundle, nil
+}
+
+// gestureActions returns the actions bound to a gesture in the order they were created.
+func (r *BundleRepository) gestureActions(gestureID string) ([]BundleAction, error) {
+	rows, err := r.db.Query(
+		`SELECT `+actionColumns+` FROM actions WHERE gesture_id = ? ORDER BY created_at, id`,
+		gestureID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var actions []BundleAction
+	for rows.Next() {
+		a, err := scanAction(rows)
+		if err != nil {
+			return nil, err
+		}
+		actions = append(actions, BundleAction{
+			PluginName:          a.PluginName,
+			ActionName:          a.ActionName,
+			Config:              a.Config,
+			Params:              a.Params,
+			Enabled:             a.Enabled,
+			RepeatMode:          a.RepeatMode,
+			RepeatIntervalMs:    a.RepeatIntervalMs,
+			RepeatMinIntervalMs: a.RepeatMinIntervalMs,
+		})
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return actions, nil
+}
+
+// Import saves the gestures of a bundle in a single transaction, resolving name
+// conflicts with strategy. Nothing is imported if any gesture fails.
+// The bundle is validated first; validation errors wrap ErrInvalidBundle.
+func (r *BundleRepository) Import(b *Bundle, strategy ConflictStrategy) ([]ImportResult, error) {
+	if err := b.Validate(); err != nil {
+		return nil, err
+	}
+	switch strategy {
+	case ConflictSkip, ConflictRename, ConflictOverwrite:
+	default:
+		return nil, fmt.Errorf("unknown conflict strategy %q", strategy)
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	results := make([]ImportResult, 0, len(b.Gestures))
+	for i := range b.Gestures {
+		result, err := importGesture(tx, &b.Gestures[i], strategy)
+		if err != nil {
+			return nil, fmt.Errorf("gesture %q: %w", b.Gestures[i].Name, err)
+		}
+		results = append(results, result)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for _, result := range results {
+		if result.Status != ImportSkipped {
+			r.notify(result.GestureID, false)
+		}
+	}
+	return results, nil
+}
+
+// importGesture saves one bundle gesture inside tx.
+func importGesture(tx *sql.Tx, bg *BundleGesture, strategy ConflictStrategy) (ImportResult, error) {
+	result := ImportResult{
+		Name:          bg.Name,
+		ImportedAs:    bg.Name,
+		Status:        ImportCreated,
+		NeedsTraining: !bg.HasTemplate() && len(bg.Samples) > 0,
+	}
+
+	existingID, err := gestureIDByName(tx, bg.Name)
+	if err != nil {
+		return ImportResult{}, err
+	}
+
+	if existingID != "" {
+		switch strategy {
+		case ConflictSkip:
+			return ImportResult{Name: bg.Name, GestureID: existingID, Status: ImportSkipped}, nil
+		case ConflictRename:
+			name, err := freeGestureName(tx, bg.Name)
+			if err != nil {
+				return ImportResult{}, err
+			}
+			result.ImportedAs = name
+			result.Status = ImportRenamed
+		case ConflictOverwrite:
+			result.GestureID = existingID
+			result.Status = ImportOverwritten
+		}
+	}
+
+	g := &Gesture{
+		ID:                result.GestureID,
+		Name:              result.ImportedAs,
+		Type:              bg.Type,
+		Tolerance:         bg.Tolerance,
+		Samples:           len(bg.Samples),
+		HoldMs:            bg.HoldMs,
+		MinFrames:         bg.MinFrames,
+		CooldownMs:        bg.CooldownMs,
+		FireOnRelease:     bg.FireOnRelease,
+		Handedness:        bg.Handedness,
+		RotationInvariant: bg.RotationInvariant,
+		MatchMode:         bg.MatchMode,
+		Neighbors:         bg.Neighbors,
+		AutoTolerance:     bg.AutoTolerance,
+		PathNormalization: bg.PathNormalization,
+		PathFeatures:      bg.PathFeatures,
+		PathTrack:         bg.PathTrack,
+	}
+	if g.Tolerance == 0 {
+		g.Tolerance = 0.15
+	}
+	g.applyDefaults()
+
+	now := time.Now()
+	if result.Status == ImportOverwritten {
+		// Replace everything attached to the gesture but keep its ID and creation time
+		_, err = tx.Exec(
+			`UPDATE gestures SET type = ?, tolerance = ?, samples = ?,
+			 hold_ms = ?, min_frames = ?, cooldown_ms = ?, fire_on_release = ?,
+			 handedness = ?, template_handedness = ?, rotation_invariant = ?, match_mode = ?, neighbors = ?,
+			 auto_tolerance = ?, recommended_tolerance = ?, path_normalization = ?, path_features = ?, path_track = ?, updated_at = ?
+			 WHERE id = ?`,
+			string(g.Type), g.Tolerance, g.Samples,
+			g.HoldMs, g.MinFrames, g.CooldownMs, g.FireOnRelease,
+			string(g.Handedness), bg.TemplateHandedness, g.RotationInvariant, string(g.MatchMode), g.Neighbors,
+			g.AutoTolerance, bg.RecommendedTolerance, string(g.PathNormalization), string(g.PathFeatures), joinTrack(g.PathTrack), now, g.ID,
+		)
+		if err != nil {
+			return ImportResult{}, err
+		}
+		for _, table := range []string{"gesture_landmarks", "gesture_hand_pairs", "gesture_paths", "gesture_samples", "actions"} {
+			if _, err := tx.Exec(`DELETE FROM `+table+` WHERE gesture_id = ?`, g.ID); err != nil {
+				return ImportResult{}, err
+			}
+		}
+	} else {
+		g.ID = uuid.New().String()
+		result.GestureID = g.ID
+		_, err = tx.Exec(
+			`INSERT INTO gestures (`+gestureColumns+`)
+			 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			g.ID, g.Name, string(g.Type), g.Tolerance, g.Samples,
+			g.HoldMs, g.MinFrames, g.CooldownMs, g.FireOnRelease,
+			string(g.Handedness), bg.TemplateHandedness, g.RotationInvariant, string(g.MatchMode), g.Neighbors,
+			g.AutoTolerance, bg.RecommendedTolerance, string(g.PathNormalization), string(g.PathFeatures), joinTrack(g.PathTrack), now, now,
+		)
+		if err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	if err := insertLandmarks(tx, g.ID, bg.Landmarks); err != nil {
+		return ImportResult{}, err
+	}
+
+	if bg.HandPair != nil {
+		if err := saveHandPair(tx, g.ID, *bg.HandPair); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	if err := insertPath(tx, g.ID, bg.Path); err != nil {
+		return ImportResult{}, err
+	}
+
+	outliers := make(map[int]bool, len(bg.Outliers))
+	for _, i := range bg.Outliers {
+		outliers[i] = true
+	}
+	for i, data := range bg.Samples {
+		if _, err := tx.Exec(
+			`INSERT INTO gesture_samples (gesture_id, sample_index, data, outlier) VALUES (?, ?, ?, ?)`,
+			g.ID, i, string(data), outliers[i],
+		); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	for _, ba := range bg.Actions {
+		if err := importAction(tx, g.ID, ba); err != nil {
+			return ImportResult{}, err
+		}
+	}
+
+	return result, nil
+}
+
+// importAction binds a bundle action to a gesture inside tx.
+func importAction(tx *sql.Tx, gestureID string, ba BundleAction) error {
+	config := ba.Config
+	if len(config) == 0 {
+		config = json.RawMessage("{}")
+	}
+
+	params := ba.Params
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+
+	repeatMode := ba.RepeatMode
+	if repeatMode == "" {
+		repeatMode = RepeatOnce
+	}
+
+	_, err := tx.Exec(
+		`INSERT INTO actions (`+actionColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		uuid.New().String(), gestureID, ba.PluginName, ba.ActionName, string(config), string(params), ba.Enabled,
+		string(repeatMode), ba.RepeatIntervalMs, ba.RepeatMinIntervalMs, time.Now(),
+	)
+	return err
+}
+
+// gestureIDByName returns the ID of the gesture with the given name, or "" if there is none.
+func gestureIDByName(tx *sql.Tx, name string) (string, error) {
+	var id string
+	err := tx.QueryRow(`SELECT id FROM gestures WHERE name = ?`, name).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	return id, err
+}
+
+// freeGestureName returns the first of "name (2)", "name (3)", ... that no gesture uses.
+func freeGestureName(tx *sql.Tx, name string) (string, error) {
+	for n := 2; ; n++ {
+		candidate := fmt.Sprintf("%s (%d)", name, n)
+		id, err := gestureIDByName(tx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if id == "" {
+			return candidate, nil
+		}
+	}
+}
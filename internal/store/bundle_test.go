@@ -0,0 +1,276 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// seedBundleGesture creates a trained static gesture with one sample and one action.
+func seedBundleGesture(t *testing.T, s *Store, id, name string) {
+	t.Helper()
+
+	g := &Gesture{ID: id, Name: name, Type: GestureTypeStatic, Tolerance: 0.2, MinFrames: 4, CooldownMs: 750,
+		Handedness: HandednessRight, MatchMode: MatchFeatures}
+	if err := s.Gestures().Create(g); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	if err := s.Gestures().SaveStaticTemplate(id, []Landmark{{Index: 0, X: 0.1, Y: 0.2, Z: 0.3}}, "Right"); err != nil {
+		t.Fatalf("failed to save landmarks: %v", err)
+	}
+	if err := s.Samples().Create(id, []json.RawMessage{json.RawMessage(`{"landmarks":[]}`)}); err != nil {
+		t.Fatalf("failed to save samples: %v", err)
+	}
+	a := &Action{ID: id + "-action", GestureID: id, PluginName: "keyboard", ActionName: "keystroke",
+		Params: json.RawMessage(`{"key":"space"}`), Enabled: true, RepeatMode: RepeatInterval,
+		RepeatIntervalMs: 300, RepeatMinIntervalMs: 100}
+	if err := s.Actions().Create(a); err != nil {
+		t.Fatalf("failed to create action: %v", err)
+	}
+}
+
+func TestBundleRepository_ExportImportRoundTrip(t *testing.T) {
+	src := newTestStore(t)
+	seedBundleGesture(t, src, "g1", "palm")
+	seedBundleGesture(t, src, "g2", "fist")
+
+	bundle, err := src.Bundles().Export(ExportOptions{})
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	if bundle.Format != BundleFormat || bundle.Version != BundleVersion {
+		t.Errorf("unexpected bundle header: %q v%d", bundle.Format, bundle.Version)
+	}
+	if len(bundle.Gestures) != 2 || bundle.Gestures[0].Name != "fist" || bundle.Gestures[1].Name != "palm" {
+		t.Fatalf("expected fist and palm ordered by name, got %+v", bundle.Gestures)
+	}
+
+	// Bundles survive a trip through JSON
+	data, err := json.Marshal(bundle)
+	if err != nil {
+		t.Fatalf("failed to marshal bundle: %v", err)
+	}
+	var decoded Bundle
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal bundle: %v", err)
+	}
+
+	dst := newTestStore(t)
+	results, err := dst.Bundles().Import(&decoded, ConflictSkip)
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if len(results) != 2 || results[0].Status != ImportCreated || results[1].Status != ImportCreated {
+		t.Fatalf("expected two created gestures, got %+v", results)
+	}
+
+	g, err := dst.Gestures().GetByName("palm")
+	if err != nil {
+		t.Fatalf("failed to get imported gesture: %v", err)
+	}
+	if g.ID == "g1" || g.ID != results[1].GestureID {
+		t.Errorf("expected a new ID reported in the result, got %q (result %q)", g.ID, results[1].GestureID)
+	}
+	if g.Tolerance != 0.2 || g.MinFrames != 4 || g.CooldownMs != 750 || g.Samples != 1 {
+		t.Errorf("gesture settings not imported: %+v", g)
+	}
+	if g.Handedness != HandednessRight || g.TemplateHandedness != "Right" {
+		t.Errorf("handedness not imported: %+v", g)
+	}
+	if g.MatchMode != MatchFeatures {
+		t.Errorf("match mode not imported: %+v", g)
+	}
+
+	landmarks, err := dst.Gestures().GetLandmarks(g.ID)
+	if err != nil || len(landmarks) != 1 || landmarks[0].Z != 0.3 {
+		t.Errorf("expected the template to be imported, got %+v (err %v)", landmarks, err)
+	}
+
+	samples, err := dst.Samples().GetByGestureID(g.ID)
+	if err != nil || len(samples) != 1 {
+		t.Errorf("expected one sample, got %d (err %v)", len(samples), err)
+	}
+
+	a, err := dst.Actions().GetByGestureID(g.ID)
+	if err != nil || a == nil {
+		t.Fatalf("expected the action to be imported, got %v (err %v)", a, err)
+	}
+	if a.PluginName != "keyboard" || string(a.Params) != `{"key":"space"}` || a.RepeatMode != RepeatInterval || a.RepeatIntervalMs != 300 {
+		t.Errorf("action not imported faithfully: %+v", a)
+	}
+}
+
+func TestBundleRepository_ExportSelection(t *testing.T) {
+	s := newTestStore(t)
+	seedBundleGesture(t, s, "g1", "palm")
+	seedBundleGesture(t, s, "g2", "fist")
+
+	bundle, err := s.Bundles().Export(ExportOptions{
+		GestureIDs:       []string{"g1"},
+		ExcludeTemplates: true,
+		ExcludeActions:   true,
+	})
+	if err != nil {
+		t.Fatalf("Export() failed: %v", err)
+	}
+	if len(bundle.Gestures) != 1 {
+		t.Fatalf("expected one gesture, got %d", len(bundle.Gestures))
+	}
+	bg := bundle.Gestures[0]
+	if bg.HasTemplate() || len(bg.Actions) != 0 || len(bg.Samples) != 1 {
+		t.Errorf("expected only samples to be exported, got %+v", bg)
+	}
+
+	_, err = s.Bundles().Export(ExportOptions{GestureIDs: []string{"missing"}})
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound for an unknown gesture, got %v", err)
+	}
+}
+
+func TestBundleRepository_ImportConflicts(t *testing.T) {
+	tests := []struct {
+		strategy ConflictStrategy
+		status   ImportStatus
+		name     string
+	}{
+		{ConflictSkip, ImportSkipped, ""},
+		{ConflictRename, ImportRenamed, "palm (2)"},
+		{ConflictOverwrite, ImportOverwritten, "palm"},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.strategy), func(t *testing.T) {
+			s := newTestStore(t)
+			seedBundleGesture(t, s, "g1", "palm")
+
+			bundle := &Bundle{
+				Format:  BundleFormat,
+				Version: BundleVersion,
+				Gestures: []BundleGesture{{
+					Name: "palm", Type: GestureTypeDynamic, Tolerance: 0.3,
+					Path: []PathPoint{{Sequence: 0, X: 1, Y: 2}},
+				}},
+			}
+
+			results, err := s.Bundles().Import(bundle, tt.strategy)
+			if err != nil {
+				t.Fatalf("Import() failed: %v", err)
+			}
+			result := results[0]
+			if result.Status != tt.status || result.ImportedAs != tt.name {
+				t.Errorf("expected %s as %q, got %+v", tt.status, tt.name, result)
+			}
+
+			original, err := s.Gestures().GetByID("g1")
+			if err != nil {
+				t.Fatalf("failed to get original gesture: %v", err)
+			}
+
+			switch tt.strategy {
+			case ConflictSkip, ConflictRename:
+				if original.Type != GestureTypeStatic {
+					t.Errorf("expected the existing gesture to be untouched, got %+v", original)
+				}
+			case ConflictOverwrite:
+				if result.GestureID != "g1" || original.Type != GestureTypeDynamic || original.Samples != 0 {
+					t.Errorf("expected the existing gesture to be replaced in place, got %+v", original)
+				}
+				if a, _ := s.Actions().GetByGestureID("g1"); a != nil {
+					t.Errorf("expected the old action to be removed, got %+v", a)
+				}
+				if landmarks, _ := s.Gestures().GetLandmarks("g1"); len(landmarks) != 0 {
+					t.Errorf("expected the old template to be removed, got %+v", landmarks)
+				}
+			}
+
+			if tt.strategy == ConflictRename {
+				renamed, err := s.Gestures().GetByName("palm (2)")
+				if err != nil || renamed.ID != result.GestureID {
+					t.Errorf("expected the renamed gesture to exist, got %+v (err %v)", renamed, err)
+				}
+			}
+		})
+	}
+}
+
+func TestBundleRepository_ImportNeedsTraining(t *testing.T) {
+	s := newTestStore(t)
+
+	bundle := &Bundle{
+		Format:  BundleFormat,
+		Version: BundleVersion,
+		Gestures: []BundleGesture{
+			{Name: "samples-only", Type: GestureTypeStatic, Samples: []json.RawMessage{json.RawMessage(`{}`)}},
+			{Name: "trained", Type: GestureTypeStatic, Landmarks: []Landmark{{Index: 0}},
+				Samples: []json.RawMessage{json.RawMessage(`{}`)}},
+			{Name: "empty", Type: GestureTypeStatic},
+		},
+	}
+
+	results, err := s.Bundles().Import(bundle, ConflictSkip)
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if !results[0].NeedsTraining || results[1].NeedsTraining || results[2].NeedsTraining {
+		t.Errorf("expected only the samples-only gesture to need training, got %+v", results)
+	}
+}
+
+func TestBundleRepository_ImportInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		bundle Bundle
+	}{
+		{"wrong format", Bundle{Format: "other", Version: 1}},
+		{"future version", Bundle{Format: BundleFormat, Version: BundleVersion + 1}},
+		{"missing version", Bundle{Format: BundleFormat}},
+		{"unnamed gesture", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Type: GestureTypeStatic}}}},
+		{"bad type", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Name: "x", Type: "wiggle"}}}},
+		{"bad match mode", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Name: "x", Type: GestureTypeStatic, MatchMode: "joints"}}}},
+		{"bad path normalization", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Name: "x", Type: GestureTypeDynamic, PathNormalization: "stretched"}}}},
+		{"bad path features", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Name: "x", Type: GestureTypeDynamic, PathFeatures: "acceleration"}}}},
+		{"bad path track", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Name: "x", Type: GestureTypeDynamic, PathTrack: []Tracked{"wrist", "elbow"}}}}},
+		{"outlier without a sample", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Name: "x", Type: GestureTypeStatic, Outliers: []int{0}}}}},
+		{"bad action", Bundle{Format: BundleFormat, Version: 1,
+			Gestures: []BundleGesture{{Name: "x", Type: GestureTypeStatic, Actions: []BundleAction{{PluginName: "keyboard"}}}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestStore(t)
+			_, err := s.Bundles().Import(&tt.bundle, ConflictSkip)
+			if !errors.Is(err, ErrInvalidBundle) {
+				t.Errorf("expected ErrInvalidBundle, got %v", err)
+			}
+		})
+	}
+}
+
+func TestBundleRepository_ImportDuplicateNames(t *testing.T) {
+	s := newTestStore(t)
+
+	// Gestures later in a bundle conflict with those imported before them
+	bundle := &Bundle{
+		Format:  BundleFormat,
+		Version: BundleVersion,
+		Gestures: []BundleGesture{
+			{Name: "palm", Type: GestureTypeStatic},
+			{Name: "palm", Type: GestureTypeDynamic},
+		},
+	}
+
+	results, err := s.Bundles().Import(bundle, ConflictRename)
+	if err != nil {
+		t.Fatalf("Import() failed: %v", err)
+	}
+	if results[0].ImportedAs != "palm" || results[1].ImportedAs != "palm (2)" {
+		t.Errorf("expected palm and palm (2), got %+v", results)
+	}
+}
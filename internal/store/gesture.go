@@ -2,7 +2,9 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 )
 
@@ -17,74 +19,311 @@ const (
 	GestureTypeStatic GestureType = "static"
 	// GestureTypeDynamic represents a dynamic motion-based gesture.
 	GestureTypeDynamic GestureType = "dynamic"
+	// GestureTypeTwoHand represents a static pose made with both hands.
+	GestureTypeTwoHand GestureType = "two_hand"
+)
+
+// Hands of a two-hand template, stored in Landmark.Hand.
+const (
+	HandLeft  = 0
+	HandRight = 1
+)
+
+// Handedness restricts which hands may make a one-hand gesture.
+type Handedness string
+
+const (
+	// HandednessEither lets either hand make the gesture; the other hand's pose is mirrored.
+	HandednessEither Handedness = "either"
+	// HandednessLeft only lets the left hand make the gesture.
+	HandednessLeft Handedness = "left"
+	// HandednessRight only lets the right hand make the gesture.
+	HandednessRight Handedness = "right"
+)
+
+// Valid reports whether h is one of the known handedness values.
+func (h Handedness) Valid() bool {
+	switch h {
+	case HandednessEither, HandednessLeft, HandednessRight:
+		return true
+	}
+	return false
+}
+
+// MatchMode selects what a static gesture's pose is compared by.
+type MatchMode string
+
+const (
+	// MatchLandmarks compares the positions of all hand landmarks.
+	MatchLandmarks MatchMode = "landmarks"
+	// MatchFeatures compares finger joint angles, extension and spread.
+	MatchFeatures MatchMode = "features"
+)
+
+// Valid reports whether m is one of the known match modes.
+func (m MatchMode) Valid() bool {
+	return m == MatchLandmarks || m == MatchFeatures
+}
+
+// PathNormalization selects how a dynamic gesture's path is scaled and
+// positioned before it is compared.
+type PathNormalization string
+
+const (
+	// NormalizeAxes stretches each axis of the path to the same range.
+	NormalizeAxes PathNormalization = "axes"
+	// NormalizeUniform scales the path as a whole, keeping its shape.
+	NormalizeUniform PathNormalization = "uniform"
+	// NormalizeCentered scales the path as a whole around its centroid.
+	NormalizeCentered PathNormalization = "centered"
+)
+
+// Valid reports whether n is one of the known path normalizations.
+func (n PathNormalization) Valid() bool {
+	switch n {
+	case NormalizeAxes, NormalizeUniform, NormalizeCentered:
+		return true
+	}
+	return false
+}
+
+// PathFeatures selects what is compared at each point of a dynamic gesture's path.
+type PathFeatures string
+
+const (
+	// PathPositions compares normalized positions only.
+	PathPositions PathFeatures = "positions"
+	// PathDisplacement also compares the unscaled distance from the start of the path.
+	PathDisplacement PathFeatures = "displacement"
+	// PathVelocity also compares the unscaled speed and direction of the motion.
+	PathVelocity PathFeatures = "velocity"
+)
+
+// Valid reports whether f is one of the known path features.
+func (f PathFeatures) Valid() bool {
+	switch f {
+	case PathPositions, PathDisplacement, PathVelocity:
+		return true
+	}
+	return false
+}
+
+// Tracked is something a dynamic gesture's path follows besides the point
+// recorded in PathPoint.X and Y.
+type Tracked string
+
+const (
+	TrackWrist     Tracked = "wrist"
+	TrackPalm      Tracked = "palm"
+	TrackThumbTip  Tracked = "thumb_tip"
+	TrackIndexTip  Tracked = "index_tip"
+	TrackMiddleTip Tracked = "middle_tip"
+	TrackRingTip   Tracked = "ring_tip"
+	TrackPinkyTip  Tracked = "pinky_tip"
+	// TrackDepth follows how far the hand moves toward or away from the camera.
+	TrackDepth Tracked = "depth"
+	// TrackPose follows the curl of the fingers and the orientation of the hand.
+	TrackPose Tracked = "pose"
+)
+
+// Valid reports whether t is one of the known tracked points and measurements.
+func (t Tracked) Valid() bool {
+	switch t {
+	case TrackWrist, TrackPalm, TrackThumbTip, TrackIndexTip, TrackMiddleTip, TrackRingTip, TrackPinkyTip,
+		TrackDepth, TrackPose:
+		return true
+	}
+	return false
+}
+
+// joinTrack stores a list of tracked points as a comma-separated string.
+func joinTrack(track []Tracked) string {
+	s := make([]string, len(track))
+	for i, t := range track {
+		s[i] = string(t)
+	}
+	return strings.Join(s, ",")
+}
+
+// splitTrack reads a list of tracked points stored by joinTrack.
+func splitTrack(s string) []Tracked {
+	if s == "" {
+		return nil
+	}
+	var track []Tracked
+	for _, t := range strings.Split(s, ",") {
+		track = append(track, Tracked(t))
+	}
+	return track
+}
+
+// Default activation settings for new gestures.
+const (
+	// DefaultMinFrames is the default number of consecutive matching frames before a gesture activates.
+	DefaultMinFrames = 3
+	// DefaultCooldownMs is the default minimum time between two firings of a gesture.
+	DefaultCooldownMs = 1000
 )
 
 // Gesture represents a gesture definition stored in the database.
 type Gesture struct {
-	ID        string
-	Name      string
-	Type      GestureType
-	Tolerance float64
-	Samples   int
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	ID                   string
+	Name                 string
+	Type                 GestureType
+	Tolerance            float64
+	Samples              int
+	HoldMs               int               // How long the gesture must be held before it activates
+	MinFrames            int               // Minimum consecutive matching frames before it activates
+	CooldownMs           int               // Minimum time between two firings
+	FireOnRelease        bool              // Fire when the gesture is released instead of when it activates
+	Handedness           Handedness        // Which hands may make the gesture
+	TemplateHandedness   string            // Detector label of the hand the template was recorded with, "" if unknown
+	RotationInvariant    bool              // Match static poses regardless of how the hand is rotated
+	MatchMode            MatchMode         // What static poses are compared by
+	Neighbors            int               // Static poses: nearest samples voting on a match, 0 to use the averaged template
+	AutoTolerance        bool              // Use RecommendedTolerance as Tolerance whenever the gesture is trained
+	RecommendedTolerance float64           // Tolerance recommended by the last training, 0 if never trained
+	PathNormalization    PathNormalization // How dynamic paths are scaled and positioned
+	PathFeatures         PathFeatures      // What dynamic paths are compared by besides position
+	PathTrack            []Tracked         // What dynamic paths follow instead of X and Y alone, nil for X and Y
+	CreatedAt            time.Time
+	UpdatedAt            time.Time
 }
 
 // Landmark represents a single 3D point from the gesture_landmarks table.
+// Hand is always HandLeft for one-hand gestures.
 type Landmark struct {
-	Index int
-	X     float64
-	Y     float64
-	Z     float64
+	Hand  int     `json:"hand,omitempty"`
+	Index int     `json:"index"`
+	X     float64 `json:"x"`
+	Y     float64 `json:"y"`
+	Z     float64 `json:"z"`
 }
 
 // PathPoint represents a point in a gesture path from the gesture_paths table.
 type PathPoint struct {
-	Sequence    int
-	X           float64
-	Y           float64
-	TimestampMs int64
+	Sequence    int        `json:"sequence"`
+	X           float64    `json:"x"`
+	Y           float64    `json:"y"`
+	TimestampMs int64      `json:"timestamp_ms"`
+	Hand        []Landmark `json:"hand,omitempty"` // Landmarks of the hand at this point, if recorded
+}
+
+// encodeHand stores the landmarks of a path point as JSON, or as an empty
+// string if there are none.
+func encodeHand(hand []Landmark) (string, error) {
+	if len(hand) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(hand)
+	return string(data), err
+}
+
+// decodeHand reads the landmarks of a path point stored by encodeHand.
+func decodeHand(s string) ([]Landmark, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var hand []Landmark
+	err := json.Unmarshal([]byte(s), &hand)
+	return hand, err
+}
+
+// HandPair describes where the right hand of a two-hand template sits relative
+// to the left hand, in units of the left hand's size.
+type HandPair struct {
+	OffsetX float64 `json:"offset_x"`
+	OffsetY float64 `json:"offset_y"`
+	OffsetZ float64 `json:"offset_z"`
+	Scale   float64 `json:"scale"` // Right hand size divided by left hand size
 }
 
 // GestureRepository provides CRUD operations for gestures.
+// Successful writes are reported to the store's gesture change listeners.
 type GestureRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	notify GestureChangeFunc
 }
 
 // Gestures returns the gesture repository for this store.
 func (s *Store) Gestures() *GestureRepository {
-	return &GestureRepository{db: s.db}
+	return &GestureRepository{db: s.db, notify: s.notifyGestureChange}
+}
+
+// gestureColumns lists the gestures columns in the order scanGesture expects them.
+const gestureColumns = `id, name, type, tolerance, samples, hold_ms, min_frames, cooldown_ms, fire_on_release,
+	handedness, template_handedness, rotation_invariant, match_mode, neighbors, auto_tolerance, recommended_tolerance,
+	path_normalization, path_features, path_track, created_at, updated_at`
+
+// scanGesture scans a row selected with gestureColumns.
+func scanGesture(row interface{ Scan(...any) error }) (*Gesture, error) {
+	g := &Gesture{}
+	var gestureType, handedness, matchMode, pathNormalization, pathFeatures, pathTrack string
+
+	err := row.Scan(&g.ID, &g.Name, &gestureType, &g.Tolerance, &g.Samples,
+		&g.HoldMs, &g.MinFrames, &g.CooldownMs, &g.FireOnRelease,
+		&handedness, &g.TemplateHandedness, &g.RotationInvariant, &matchMode, &g.Neighbors, &g.AutoTolerance, &g.RecommendedTolerance,
+		&pathNormalization, &pathFeatures, &pathTrack, &g.CreatedAt, &g.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	g.Type = GestureType(gestureType)
+	g.Handedness = Handedness(handedness)
+	g.MatchMode = MatchMode(matchMode)
+	g.PathNormalization = PathNormalization(pathNormalization)
+	g.PathFeatures = PathFeatures(pathFeatures)
+	g.PathTrack = splitTrack(pathTrack)
+	return g, nil
 }
 
 // Create inserts a new gesture into the database.
+// Empty matching settings are stored as their defaults.
 func (r *GestureRepository) Create(g *Gesture) error {
 	now := time.Now()
 	g.CreatedAt = now
 	g.UpdatedAt = now
+	g.applyDefaults()
 
 	_, err := r.db.Exec(
-		`INSERT INTO gestures (id, name, type, tolerance, samples, created_at, updated_at)
-		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		g.ID, g.Name, string(g.Type), g.Tolerance, g.Samples, g.CreatedAt, g.UpdatedAt,
+		`INSERT INTO gestures (`+gestureColumns+`)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		g.ID, g.Name, string(g.Type), g.Tolerance, g.Samples,
+		g.HoldMs, g.MinFrames, g.CooldownMs, g.FireOnRelease,
+		string(g.Handedness), g.TemplateHandedness, g.RotationInvariant, string(g.MatchMode), g.Neighbors,
+		g.AutoTolerance, g.RecommendedTolerance, string(g.PathNormalization), string(g.PathFeatures), joinTrack(g.PathTrack),
+		g.CreatedAt, g.UpdatedAt,
 	)
 	if err != nil {
 		return err
 	}
 
+	r.notify(g.ID, false)
 	return nil
 }
 
+// applyDefaults fills in the matching settings left empty.
+func (g *Gesture) applyDefaults() {
+	if g.Handedness == "" {
+		g.Handedness = HandednessEither
+	}
+	if g.MatchMode == "" {
+		g.MatchMode = MatchLandmarks
+	}
+	if g.PathNormalization == "" {
+		g.PathNormalization = NormalizeAxes
+	}
+	if g.PathFeatures == "" {
+		g.PathFeatures = PathPositions
+	}
+}
+
 // GetByID retrieves a gesture by its ID.
 func (r *GestureRepository) GetByID(id string) (*Gesture, error) {
-	g := &Gesture{}
-	var gestureType string
-
-	err := r.db.QueryRow(
-		`SELECT id, name, type, tolerance, samples, created_at, updated_at
-		 FROM gestures WHERE id = ?`,
+	g, err := scanGesture(r.db.QueryRow(
+		`SELECT `+gestureColumns+` FROM gestures WHERE id = ?`,
 		id,
-	).Scan(&g.ID, &g.Name, &gestureType, &g.Tolerance, &g.Samples, &g.CreatedAt, &g.UpdatedAt)
-
+	))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -92,21 +331,15 @@ func (r *GestureRepository) GetByID(id string) (*Gesture, error) {
 		return nil, err
 	}
 
-	g.Type = GestureType(gestureType)
 	return g, nil
 }
 
 // GetByName retrieves a gesture by its name.
 func (r *GestureRepository) GetByName(name string) (*Gesture, error) {
-	g := &Gesture{}
-	var gestureType string
-
-	err := r.db.QueryRow(
-		`SELECT id, name, type, tolerance, samples, created_at, updated_at
-		 FROM gestures WHERE name = ?`,
+	g, err := scanGesture(r.db.QueryRow(
+		`SELECT `+gestureColumns+` FROM gestures WHERE name = ?`,
 		name,
-	).Scan(&g.ID, &g.Name, &gestureType, &g.Tolerance, &g.Samples, &g.CreatedAt, &g.UpdatedAt)
-
+	))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, ErrNotFound
@@ -114,15 +347,13 @@ func (r *GestureRepository) GetByName(name string) (*Gesture, error) {
 		return nil, err
 	}
 
-	g.Type = GestureType(gestureType)
 	return g, nil
 }
 
 // List retrieves all gestures from the database.
 func (r *GestureRepository) List() ([]*Gesture, error) {
 	rows, err := r.db.Query(
-		`SELECT id, name, type, tolerance, samples, created_at, updated_at
-		 FROM gestures ORDER BY created_at DESC`,
+		`SELECT ` + gestureColumns + ` FROM gestures ORDER BY created_at DESC`,
 	)
 	if err != nil {
 		return nil, err
@@ -131,15 +362,10 @@ func (r *GestureRepository) List() ([]*Gesture, error) {
 
 	var gestures []*Gesture
 	for rows.Next() {
-		g := &Gesture{}
-		var gestureType string
-
-		err := rows.Scan(&g.ID, &g.Name, &gestureType, &g.Tolerance, &g.Samples, &g.CreatedAt, &g.UpdatedAt)
+		g, err := scanGesture(rows)
 		if err != nil {
 			return nil, err
 		}
-
-		g.Type = GestureType(gestureType)
 		gestures = append(gestures, g)
 	}
 
@@ -151,13 +377,69 @@ func (r *GestureRepository) List() ([]*Gesture, error) {
 }
 
 // Update updates an existing gesture in the database.
+// TemplateHandedness and RecommendedTolerance belong to the trained template
+// and are left unchanged.
 func (r *GestureRepository) Update(g *Gesture) error {
 	g.UpdatedAt = time.Now()
+	g.applyDefaults()
 
 	result, err := r.db.Exec(
-		`UPDATE gestures SET name = ?, type = ?, tolerance = ?, samples = ?, updated_at = ?
+		`UPDATE gestures SET name = ?, type = ?, tolerance = ?, samples = ?,
+		 hold_ms = ?, min_frames = ?, cooldown_ms = ?, fire_on_release = ?,
+		 handedness = ?, rotation_invariant = ?, match_mode = ?, neighbors = ?,
+		 auto_tolerance = ?, path_normalization = ?, path_features = ?, path_track = ?, updated_at = ?
+		 WHERE id = ?`,
+		g.Name, string(g.Type), g.Tolerance, g.Samples,
+		g.HoldMs, g.MinFrames, g.CooldownMs, g.FireOnRelease,
+		string(g.Handedness), g.RotationInvariant, string(g.MatchMode), g.Neighbors,
+		g.AutoTolerance, string(g.PathNormalization), string(g.PathFeatures), joinTrack(g.PathTrack), g.UpdatedAt, g.ID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	r.notify(g.ID, false)
+	return nil
+}
+
+// SetRecommendedTolerance records the tolerance recommended by training a gesture.
+// Gestures with AutoTolerance also switch to it.
+func (r *GestureRepository) SetRecommendedTolerance(id string, tolerance float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setRecommendedTolerance(tx, id, tolerance); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.notify(id, false)
+	return nil
+}
+
+// setRecommendedTolerance records the recommended tolerance of a gesture inside tx.
+// Returns ErrNotFound if the gesture does not exist.
+func setRecommendedTolerance(tx *sql.Tx, id string, tolerance float64) error {
+	result, err := tx.Exec(
+		`UPDATE gestures SET recommended_tolerance = ?,
+		 tolerance = CASE WHEN auto_tolerance THEN ? ELSE tolerance END, updated_at = ?
 		 WHERE id = ?`,
-		g.Name, string(g.Type), g.Tolerance, g.Samples, g.UpdatedAt, g.ID,
+		tolerance, tolerance, time.Now(), id,
 	)
 	if err != nil {
 		return err
@@ -191,15 +473,17 @@ func (r *GestureRepository) Delete(id string) error {
 		return ErrNotFound
 	}
 
+	r.notify(id, true)
 	return nil
 }
 
-// GetLandmarks retrieves the normalized landmarks for a static gesture.
+// GetLandmarks retrieves the normalized landmarks for a static or two-hand gesture,
+// ordered by hand and index.
 // Returns an empty slice if no landmarks are stored (gesture not yet trained).
 func (r *GestureRepository) GetLandmarks(gestureID string) ([]Landmark, error) {
 	rows, err := r.db.Query(
-		`SELECT landmark_index, x, y, z FROM gesture_landmarks
-		 WHERE gesture_id = ? ORDER BY landmark_index`,
+		`SELECT hand, landmark_index, x, y, z FROM gesture_landmarks
+		 WHERE gesture_id = ? ORDER BY hand, landmark_index`,
 		gestureID,
 	)
 	if err != nil {
@@ -210,7 +494,7 @@ func (r *GestureRepository) GetLandmarks(gestureID string) ([]Landmark, error) {
 	var landmarks []Landmark
 	for rows.Next() {
 		var l Landmark
-		if err := rows.Scan(&l.Index, &l.X, &l.Y, &l.Z); err != nil {
+		if err := rows.Scan(&l.Hand, &l.Index, &l.X, &l.Y, &l.Z); err != nil {
 			return nil, err
 		}
 		landmarks = append(landmarks, l)
@@ -227,7 +511,7 @@ func (r *GestureRepository) GetLandmarks(gestureID string) ([]Landmark, error) {
 // Returns an empty slice if no path is stored (gesture not yet trained).
 func (r *GestureRepository) GetPath(gestureID string) ([]PathPoint, error) {
 	rows, err := r.db.Query(
-		`SELECT sequence, x, y, timestamp_ms FROM gesture_paths
+		`SELECT sequence, x, y, timestamp_ms, hand FROM gesture_paths
 		 WHERE gesture_id = ? ORDER BY sequence`,
 		gestureID,
 	)
@@ -239,7 +523,11 @@ func (r *GestureRepository) GetPath(gestureID string) ([]PathPoint, error) {
 	var path []PathPoint
 	for rows.Next() {
 		var p PathPoint
-		if err := rows.Scan(&p.Sequence, &p.X, &p.Y, &p.TimestampMs); err != nil {
+		var hand string
+		if err := rows.Scan(&p.Sequence, &p.X, &p.Y, &p.TimestampMs, &hand); err != nil {
+			return nil, err
+		}
+		if p.Hand, err = decodeHand(hand); err != nil {
 			return nil, err
 		}
 		path = append(path, p)
@@ -251,3 +539,249 @@ func (r *GestureRepository) GetPath(gestureID string) ([]PathPoint, error) {
 
 	return path, nil
 }
+
+// SaveLandmarks replaces the normalized landmarks of a static gesture in a single transaction.
+// The hand the template was recorded with becomes unknown.
+// It also bumps the gesture's updated_at timestamp.
+func (r *GestureRepository) SaveLandmarks(gestureID string, landmarks []Landmark) error {
+	return r.saveLandmarks(gestureID, landmarks, "", nil)
+}
+
+// SaveStaticTemplate replaces the normalized landmarks of a static gesture and
+// the handedness label of the hand they were recorded with in a single transaction.
+// It also bumps the gesture's updated_at timestamp.
+func (r *GestureRepository) SaveStaticTemplate(gestureID string, landmarks []Landmark, handedness string) error {
+	return r.saveLandmarks(gestureID, landmarks, handedness, nil)
+}
+
+// SaveTwoHandTemplate replaces the landmarks of both hands and their relative
+// placement for a two-hand gesture in a single transaction.
+// It also bumps the gesture's updated_at timestamp.
+func (r *GestureRepository) SaveTwoHandTemplate(gestureID string, landmarks []Landmark, pair HandPair) error {
+	return r.saveLandmarks(gestureID, landmarks, "", &pair)
+}
+
+// saveLandmarks replaces the landmarks of a gesture, the handedness of the hand
+// they were recorded with and, if pair is not nil, its hand pair.
+func (r *GestureRepository) saveLandmarks(gestureID string, landmarks []Landmark, handedness string, pair *HandPair) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := touchGesture(tx, gestureID); err != nil {
+		return err
+	}
+
+	if err := writeLandmarks(tx, gestureID, landmarks, handedness, pair); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.notify(gestureID, false)
+	return nil
+}
+
+// writeLandmarks replaces the landmarks of a gesture, the handedness of the
+// hand they were recorded with and, if pair is not nil, its hand pair inside tx.
+func writeLandmarks(tx *sql.Tx, gestureID string, landmarks []Landmark, handedness string, pair *HandPair) error {
+	if err := insertLandmarks(tx, gestureID, landmarks); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`UPDATE gestures SET template_handedness = ? WHERE id = ?`, handedness, gestureID); err != nil {
+		return err
+	}
+
+	if pair != nil {
+		return saveHandPair(tx, gestureID, *pair)
+	}
+	return nil
+}
+
+// insertLandmarks replaces the landmarks of a gesture inside tx.
+func insertLandmarks(tx *sql.Tx, gestureID string, landmarks []Landmark) error {
+	if _, err := tx.Exec(`DELETE FROM gesture_landmarks WHERE gesture_id = ?`, gestureID); err != nil {
+		return err
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO gesture_landmarks (gesture_id, hand, landmark_index, x, y, z) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, l := range landmarks {
+		if _, err := stmt.Exec(gestureID, l.Hand, l.Index, l.X, l.Y, l.Z); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveHandPair inserts or replaces the hand pair of a gesture inside tx.
+func saveHandPair(tx *sql.Tx, gestureID string, pair HandPair) error {
+	_, err := tx.Exec(
+		`INSERT INTO gesture_hand_pairs (gesture_id, offset_x, offset_y, offset_z, scale) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT(gesture_id) DO UPDATE SET offset_x = excluded.offset_x, offset_y = excluded.offset_y,
+		 offset_z = excluded.offset_z, scale = excluded.scale`,
+		gestureID, pair.OffsetX, pair.OffsetY, pair.OffsetZ, pair.Scale,
+	)
+	return err
+}
+
+// GetHandPair retrieves the relative placement of the hands of a two-hand gesture.
+// Returns nil, nil if the gesture has not been trained.
+func (r *GestureRepository) GetHandPair(gestureID string) (*HandPair, error) {
+	pair := &HandPair{}
+	err := r.db.QueryRow(
+		`SELECT offset_x, offset_y, offset_z, scale FROM gesture_hand_pairs WHERE gesture_id = ?`,
+		gestureID,
+	).Scan(&pair.OffsetX, &pair.OffsetY, &pair.OffsetZ, &pair.Scale)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// SavePath replaces the path points of a dynamic gesture in a single transaction.
+// It also bumps the gesture's updated_at timestamp.
+func (r *GestureRepository) SavePath(gestureID string, path []PathPoint) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := touchGesture(tx, gestureID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM gesture_paths WHERE gesture_id = ?`, gestureID); err != nil {
+		return err
+	}
+
+	if err := insertPath(tx, gestureID, path); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.notify(gestureID, false)
+	return nil
+}
+
+// Training is what training a gesture produces, saved at once by SaveTraining.
+// Exactly one of Landmarks and Path holds the template, depending on the type
+// of the gesture.
+type Training struct {
+	Samples              []json.RawMessage // Samples to add before the template, nil if all were stored already
+	Landmarks            []Landmark        // Template of a static or two-hand gesture
+	Handedness           string            // Detector label of the hand a static template was recorded with
+	Pair                 *HandPair         // Relative placement of the hands of a two-hand template
+	Path                 []PathPoint       // Template of a dynamic gesture
+	Outliers             []int             // Positions of the samples training rejected, as SetOutliers takes them
+	RecommendedTolerance float64           // Tolerance recommended by calibration, 0 if not calibrated
+}
+
+// SaveTraining adds the new samples of a gesture and replaces its template,
+// outlier flags and recommended tolerance in a single transaction, so that
+// listeners are notified once and only see the gesture fully trained.
+// It also bumps the gesture's updated_at timestamp.
+func (r *GestureRepository) SaveTraining(gestureID string, t *Training) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := touchGesture(tx, gestureID); err != nil {
+		return err
+	}
+
+	if len(t.Samples) > 0 {
+		if err := insertSamples(tx, gestureID, t.Samples); err != nil {
+			return err
+		}
+	}
+
+	if t.Path != nil {
+		if _, err := tx.Exec(`DELETE FROM gesture_paths WHERE gesture_id = ?`, gestureID); err != nil {
+			return err
+		}
+		if err := insertPath(tx, gestureID, t.Path); err != nil {
+			return err
+		}
+	} else {
+		if err := writeLandmarks(tx, gestureID, t.Landmarks, t.Handedness, t.Pair); err != nil {
+			return err
+		}
+	}
+
+	if err := setOutliers(tx, gestureID, t.Outliers); err != nil {
+		return err
+	}
+
+	if t.RecommendedTolerance > 0 {
+		if err := setRecommendedTolerance(tx, gestureID, t.RecommendedTolerance); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.notify(gestureID, false)
+	return nil
+}
+
+// insertPath inserts the points of a gesture path inside a transaction.
+func insertPath(tx *sql.Tx, gestureID string, path []PathPoint) error {
+	stmt, err := tx.Prepare(`INSERT INTO gesture_paths (gesture_id, sequence, x, y, timestamp_ms, hand) VALUES (?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, p := range path {
+		hand, err := encodeHand(p.Hand)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(gestureID, p.Sequence, p.X, p.Y, p.TimestampMs, hand); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// touchGesture updates the updated_at timestamp of a gesture inside a transaction.
+// Returns ErrNotFound if the gesture does not exist.
+func touchGesture(tx *sql.Tx, gestureID string) error {
+	result, err := tx.Exec(`UPDATE gestures SET updated_at = ? WHERE id = ?`, time.Now(), gestureID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package store
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 	"testing"
@@ -297,6 +298,283 @@ func TestGestureRepository_Update_NotFound(t *testing.T) {
 	}
 }
 
+func TestGestureRepository_ActivationSettings(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	gesture := &Gesture{
+		ID:            "test-id-1",
+		Name:          "palm",
+		Type:          GestureTypeStatic,
+		Tolerance:     0.15,
+		HoldMs:        400,
+		MinFrames:     5,
+		CooldownMs:    2000,
+		FireOnRelease: true,
+	}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	got, err := repo.GetByID(gesture.ID)
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.HoldMs != 400 || got.MinFrames != 5 || got.CooldownMs != 2000 || !got.FireOnRelease {
+		t.Errorf("activation settings not persisted: %+v", got)
+	}
+
+	got.HoldMs = 0
+	got.FireOnRelease = false
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update gesture: %v", err)
+	}
+
+	got, err = repo.GetByID(gesture.ID)
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.HoldMs != 0 || got.FireOnRelease {
+		t.Errorf("activation settings not updated: %+v", got)
+	}
+}
+
+func TestGestureRepository_Handedness(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	// New gestures may be made with either hand by default
+	gesture := &Gesture{ID: "g1", Name: "palm", Type: GestureTypeStatic, Tolerance: 0.15}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	got, err := repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.Handedness != HandednessEither || got.TemplateHandedness != "" {
+		t.Errorf("expected either hand and no template hand, got %+v", got)
+	}
+
+	if err := repo.SaveStaticTemplate("g1", []Landmark{{Index: 0}}, "Right"); err != nil {
+		t.Fatalf("SaveStaticTemplate() error = %v", err)
+	}
+
+	// Updating the gesture keeps the hand its template was recorded with
+	got.Handedness = HandednessLeft
+	got.RotationInvariant = true
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update gesture: %v", err)
+	}
+	got, err = repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.Handedness != HandednessLeft || got.TemplateHandedness != "Right" || !got.RotationInvariant {
+		t.Errorf("expected a rotation-invariant left-only gesture with a right-hand template, got %+v", got)
+	}
+}
+
+func TestGestureRepository_MatchMode(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	gesture := &Gesture{ID: "g1", Name: "peace", Type: GestureTypeStatic, Tolerance: 0.15}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	got, err := repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.MatchMode != MatchLandmarks {
+		t.Errorf("expected match mode %q by default, got %q", MatchLandmarks, got.MatchMode)
+	}
+
+	got.MatchMode = MatchFeatures
+	got.Neighbors = 3
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update gesture: %v", err)
+	}
+	got, err = repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.MatchMode != MatchFeatures || got.Neighbors != 3 {
+		t.Errorf("expected match mode %q voting over 3 neighbors, got %q and %d", MatchFeatures, got.MatchMode, got.Neighbors)
+	}
+}
+
+func TestGestureRepository_PathOptions(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	gesture := &Gesture{ID: "g1", Name: "circle", Type: GestureTypeDynamic, Tolerance: 0.15}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	got, err := repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.PathNormalization != NormalizeAxes || got.PathFeatures != PathPositions {
+		t.Errorf("expected %q and %q by default, got %q and %q", NormalizeAxes, PathPositions, got.PathNormalization, got.PathFeatures)
+	}
+
+	got.PathNormalization = NormalizeCentered
+	got.PathFeatures = PathDisplacement
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update gesture: %v", err)
+	}
+	got, err = repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.PathNormalization != NormalizeCentered || got.PathFeatures != PathDisplacement {
+		t.Errorf("expected %q and %q, got %q and %q", NormalizeCentered, PathDisplacement, got.PathNormalization, got.PathFeatures)
+	}
+}
+
+func TestGestureRepository_PathTrack(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	gesture := &Gesture{ID: "g1", Name: "pinch-drag", Type: GestureTypeDynamic, Tolerance: 0.15}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	got, err := repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if got.PathTrack != nil {
+		t.Errorf("expected no tracked points by default, got %v", got.PathTrack)
+	}
+
+	got.PathTrack = []Tracked{TrackIndexTip, TrackThumbTip, TrackPose}
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update gesture: %v", err)
+	}
+	got, err = repo.GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture: %v", err)
+	}
+	if len(got.PathTrack) != 3 || got.PathTrack[0] != TrackIndexTip || got.PathTrack[1] != TrackThumbTip || got.PathTrack[2] != TrackPose {
+		t.Errorf("expected the tracked points in order, got %v", got.PathTrack)
+	}
+}
+
+func TestGestureRepository_SetRecommendedTolerance(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	manual := &Gesture{ID: "g1", Name: "palm", Type: GestureTypeStatic, Tolerance: 0.15}
+	auto := &Gesture{ID: "g2", Name: "fist", Type: GestureTypeStatic, Tolerance: 0.15, AutoTolerance: true}
+	for _, g := range []*Gesture{manual, auto} {
+		if err := repo.Create(g); err != nil {
+			t.Fatalf("failed to create gesture: %v", err)
+		}
+		if err := repo.SetRecommendedTolerance(g.ID, 0.4); err != nil {
+			t.Fatalf("SetRecommendedTolerance() error = %v", err)
+		}
+	}
+
+	got, _ := repo.GetByID("g1")
+	if got.Tolerance != 0.15 || got.RecommendedTolerance != 0.4 {
+		t.Errorf("expected a manual tolerance to be kept, got %+v", got)
+	}
+	got, _ = repo.GetByID("g2")
+	if got.Tolerance != 0.4 || got.RecommendedTolerance != 0.4 {
+		t.Errorf("expected an auto tolerance to follow the recommendation, got %+v", got)
+	}
+
+	// Updating the gesture keeps the recommendation of its last training
+	got.AutoTolerance = false
+	if err := repo.Update(got); err != nil {
+		t.Fatalf("failed to update gesture: %v", err)
+	}
+	got, _ = repo.GetByID("g2")
+	if got.AutoTolerance || got.RecommendedTolerance != 0.4 {
+		t.Errorf("expected a manual gesture keeping its recommendation, got %+v", got)
+	}
+
+	if err := repo.SetRecommendedTolerance("missing", 0.4); err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestGestureRepository_SaveTraining(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	static := &Gesture{ID: "g1", Name: "palm", Type: GestureTypeStatic, Tolerance: 0.15, AutoTolerance: true}
+	dynamic := &Gesture{ID: "g2", Name: "wave", Type: GestureTypeDynamic, Tolerance: 0.15}
+	for _, g := range []*Gesture{static, dynamic} {
+		if err := repo.Create(g); err != nil {
+			t.Fatalf("failed to create gesture: %v", err)
+		}
+	}
+	if err := s.Samples().Create("g1", []json.RawMessage{json.RawMessage(`{"n":0}`)}); err != nil {
+		t.Fatalf("failed to create samples: %v", err)
+	}
+
+	var notified int
+	s.OnGestureChange(func(id string, deleted bool) {
+		notified++
+	})
+
+	err := repo.SaveTraining("g1", &Training{
+		Samples:              []json.RawMessage{json.RawMessage(`{"n":1}`), json.RawMessage(`{"n":2}`)},
+		Landmarks:            []Landmark{{Index: 0, X: 0.5}},
+		Handedness:           "Right",
+		Outliers:             []int{2},
+		RecommendedTolerance: 0.3,
+	})
+	if err != nil {
+		t.Fatalf("SaveTraining() error = %v", err)
+	}
+	if notified != 1 {
+		t.Errorf("expected listeners notified once, got %d", notified)
+	}
+
+	got, _ := repo.GetByID("g1")
+	if got.Samples != 3 || got.TemplateHandedness != "Right" || got.Tolerance != 0.3 || got.RecommendedTolerance != 0.3 {
+		t.Errorf("expected the samples, template and tolerance saved, got %+v", got)
+	}
+	if landmarks, _ := repo.GetLandmarks("g1"); len(landmarks) != 1 || landmarks[0].X != 0.5 {
+		t.Errorf("expected the template landmarks saved, got %+v", landmarks)
+	}
+	samples, _ := s.Samples().GetByGestureID("g1")
+	if len(samples) != 3 || samples[0].Outlier || samples[1].Outlier || !samples[2].Outlier {
+		t.Errorf("expected the last new sample flagged as the outlier, got %+v", samples)
+	}
+
+	// A dynamic template replaces the path and leaves the landmarks alone
+	path := []PathPoint{{Sequence: 0, X: 0.1}, {Sequence: 1, X: 0.2}}
+	if err := repo.SaveTraining("g2", &Training{Path: path}); err != nil {
+		t.Fatalf("SaveTraining() error = %v", err)
+	}
+	if got, _ := repo.GetPath("g2"); len(got) != 2 || got[1].X != 0.2 {
+		t.Errorf("expected the path saved, got %+v", got)
+	}
+	if got, _ := repo.GetByID("g2"); got.RecommendedTolerance != 0 || got.Tolerance != 0.15 {
+		t.Errorf("expected an uncalibrated training to leave the tolerance alone, got %+v", got)
+	}
+
+	// Nothing is saved, and nobody notified, if any part fails
+	notified = 0
+	err = repo.SaveTraining("missing", &Training{
+		Samples:   []json.RawMessage{json.RawMessage(`{}`)},
+		Landmarks: []Landmark{{Index: 0}},
+	})
+	if err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	if samples, _ := s.Samples().GetByGestureID("missing"); len(samples) != 0 || notified != 0 {
+		t.Errorf("expected a failed training to save nothing, got %d samples and %d notifications", len(samples), notified)
+	}
+}
+
 func TestGestureType_Constants(t *testing.T) {
 	// Verify the gesture type constants
 	if GestureTypeStatic != "static" {
@@ -306,3 +584,175 @@ func TestGestureType_Constants(t *testing.T) {
 		t.Errorf("GestureTypeDynamic should be 'dynamic', got %q", GestureTypeDynamic)
 	}
 }
+
+func TestGestureRepository_SaveLandmarks(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	gesture := &Gesture{ID: "g1", Name: "fist", Type: GestureTypeStatic, Tolerance: 0.15}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	first := []Landmark{{Index: 0, X: 0, Y: 0, Z: 0}, {Index: 1, X: 0.1, Y: 0.2, Z: 0.3}}
+	if err := repo.SaveLandmarks("g1", first); err != nil {
+		t.Fatalf("SaveLandmarks() error = %v", err)
+	}
+
+	// Saving again replaces the previous template
+	second := []Landmark{{Index: 0, X: 1, Y: 1, Z: 1}}
+	if err := repo.SaveLandmarks("g1", second); err != nil {
+		t.Fatalf("SaveLandmarks() error = %v", err)
+	}
+
+	landmarks, err := repo.GetLandmarks("g1")
+	if err != nil {
+		t.Fatalf("GetLandmarks() error = %v", err)
+	}
+	if len(landmarks) != 1 || landmarks[0].X != 1 {
+		t.Errorf("expected replaced landmarks, got %+v", landmarks)
+	}
+}
+
+func TestGestureRepository_SavePath(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	gesture := &Gesture{ID: "g1", Name: "swipe", Type: GestureTypeDynamic, Tolerance: 0.5}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	path := []PathPoint{
+		{Sequence: 0, X: 0.8, Y: 0.5, TimestampMs: 0},
+		{Sequence: 1, X: 0.2, Y: 0.5, TimestampMs: 100},
+	}
+	if err := repo.SavePath("g1", path); err != nil {
+		t.Fatalf("SavePath() error = %v", err)
+	}
+
+	got, err := repo.GetPath("g1")
+	if err != nil {
+		t.Fatalf("GetPath() error = %v", err)
+	}
+	if len(got) != 2 || got[1].X != 0.2 || got[1].TimestampMs != 100 {
+		t.Errorf("unexpected path: %+v", got)
+	}
+	if got[0].Hand != nil {
+		t.Errorf("expected no hand landmarks when none were recorded, got %+v", got[0].Hand)
+	}
+
+	// The landmarks of the hand are kept with each point
+	path[1].Hand = []Landmark{{Index: 0, X: 0.2, Y: 0.5}, {Index: 8, X: 0.25, Y: 0.3, Z: -0.02}}
+	if err := repo.SavePath("g1", path); err != nil {
+		t.Fatalf("SavePath() error = %v", err)
+	}
+	got, err = repo.GetPath("g1")
+	if err != nil {
+		t.Fatalf("GetPath() error = %v", err)
+	}
+	if len(got[1].Hand) != 2 || got[1].Hand[1] != path[1].Hand[1] {
+		t.Errorf("expected the hand landmarks to be kept, got %+v", got[1].Hand)
+	}
+}
+
+func TestGestureRepository_SaveTwoHandTemplate(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	gesture := &Gesture{ID: "g1", Name: "heart", Type: GestureTypeTwoHand, Tolerance: 0.5}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+
+	// Untrained gestures have no hand pair
+	pair, err := repo.GetHandPair("g1")
+	if err != nil || pair != nil {
+		t.Fatalf("expected no hand pair before training, got %+v (err %v)", pair, err)
+	}
+
+	landmarks := []Landmark{
+		{Hand: HandRight, Index: 0, X: 2},
+		{Hand: HandLeft, Index: 0, X: 1},
+	}
+	want := HandPair{OffsetX: 1.5, OffsetY: -0.5, OffsetZ: 0.1, Scale: 0.9}
+	if err := repo.SaveTwoHandTemplate("g1", landmarks, want); err != nil {
+		t.Fatalf("SaveTwoHandTemplate() error = %v", err)
+	}
+
+	got, err := repo.GetLandmarks("g1")
+	if err != nil {
+		t.Fatalf("GetLandmarks() error = %v", err)
+	}
+	if len(got) != 2 || got[0].Hand != HandLeft || got[0].X != 1 || got[1].Hand != HandRight || got[1].X != 2 {
+		t.Errorf("expected the left hand before the right, got %+v", got)
+	}
+
+	pair, err = repo.GetHandPair("g1")
+	if err != nil {
+		t.Fatalf("GetHandPair() error = %v", err)
+	}
+	if pair == nil || *pair != want {
+		t.Errorf("expected hand pair %+v, got %+v", want, pair)
+	}
+
+	// Saving again replaces the previous pair
+	want.Scale = 1.1
+	if err := repo.SaveTwoHandTemplate("g1", landmarks, want); err != nil {
+		t.Fatalf("SaveTwoHandTemplate() error = %v", err)
+	}
+	if pair, _ = repo.GetHandPair("g1"); pair == nil || pair.Scale != 1.1 {
+		t.Errorf("expected the hand pair to be replaced, got %+v", pair)
+	}
+}
+
+func TestGestureRepository_SaveLandmarks_NotFound(t *testing.T) {
+	s := newTestStore(t)
+
+	err := s.Gestures().SaveLandmarks("missing", []Landmark{{Index: 0}})
+	if err != ErrNotFound {
+		t.Errorf("expected ErrNotFound, got: %v", err)
+	}
+}
+
+func TestStore_OnGestureChange(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Gestures()
+
+	type change struct {
+		id      string
+		deleted bool
+	}
+	var changes []change
+	s.OnGestureChange(func(id string, deleted bool) {
+		changes = append(changes, change{id, deleted})
+	})
+
+	gesture := &Gesture{ID: "g1", Name: "fist", Type: GestureTypeStatic, Tolerance: 0.15}
+	if err := repo.Create(gesture); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	gesture.Tolerance = 0.2
+	if err := repo.Update(gesture); err != nil {
+		t.Fatalf("failed to update gesture: %v", err)
+	}
+	if err := repo.SaveLandmarks("g1", []Landmark{{Index: 0}}); err != nil {
+		t.Fatalf("failed to save landmarks: %v", err)
+	}
+	if err := repo.Delete("g1"); err != nil {
+		t.Fatalf("failed to delete gesture: %v", err)
+	}
+
+	// Failed writes are not reported
+	repo.Delete("g1")
+
+	want := []change{{"g1", false}, {"g1", false}, {"g1", false}, {"g1", true}}
+	if len(changes) != len(want) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(want), len(changes), changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("change %d = %+v, want %+v", i, changes[i], want[i])
+		}
+	}
+}
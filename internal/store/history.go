@@ -0,0 +1,215 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryLimit is the number of runs returned by List when no limit is given.
+const DefaultHistoryLimit = 50
+
+// ActionRun records one attempt to run an action after its gesture fired,
+// including attempts that failed before the plugin was started.
+type ActionRun struct {
+	ID          int64
+	GestureID   string
+	GestureName string
+	ActionID    string
+	PluginName  string
+	ActionName  string
+	Request     json.RawMessage // Request sent to the plugin, nil if it was never sent
+	Response    json.RawMessage // Response returned by the plugin, nil if there was none
+	Success     bool
+	Error       string // Why the run failed, empty on success
+	LatencyMs   int64  // Time spent waiting for the plugin
+	CreatedAt   time.Time
+}
+
+// ActionRunFilter selects action runs. Zero values match everything.
+type ActionRunFilter struct {
+	GestureID  string
+	ActionID   string
+	PluginName string
+	Success    *bool     // Only successful or only failed runs
+	Since      time.Time // Runs at or after this time
+	Until      time.Time // Runs before this time
+	Limit      int       // Maximum number of runs, DefaultHistoryLimit if zero
+	Offset     int
+}
+
+// HistoryRepository records and queries action runs.
+type HistoryRepository struct {
+	db *sql.DB
+}
+
+// History returns the action run history repository for this store.
+func (s *Store) History() *HistoryRepository {
+	return &HistoryRepository{db: s.db}
+}
+
+// actionRunColumns lists the action_runs columns in the order scanActionRun expects them.
+const actionRunColumns = `id, gesture_id, gesture_name, action_id, plugin_name, action_name, request, response, success, error, latency_ms, created_at`
+
+// scanActionRun scans a row selected with actionRunColumns.
+func scanActionRun(row interface{ Scan(...any) error }) (*ActionRun, error) {
+	run := &ActionRun{}
+	var request, response sql.NullString
+	var success int
+
+	err := row.Scan(&run.ID, &run.GestureID, &run.GestureName, &run.ActionID, &run.PluginName, &run.ActionName,
+		&request, &response, &success, &run.Error, &run.LatencyMs, &run.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Valid {
+		run.Request = json.RawMessage(request.String)
+	}
+	if response.Valid {
+		run.Response = json.RawMessage(response.String)
+	}
+	run.Success = success != 0
+	return run, nil
+}
+
+// nullJSON converts raw JSON to a nullable column value.
+func nullJSON(data json.RawMessage) sql.NullString {
+	if len(data) == 0 {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: string(data), Valid: true}
+}
+
+// Create records an action run and sets its ID.
+// CreatedAt is set to the current time if it is zero. Times are stored in UTC
+// so that they compare correctly as text.
+func (r *HistoryRepository) Create(run *ActionRun) error {
+	if run.CreatedAt.IsZero() {
+		run.CreatedAt = time.Now()
+	}
+
+	result, err := r.db.Exec(
+		`INSERT INTO action_runs (gesture_id, gesture_name, action_id, plugin_name, action_name,
+		 request, response, success, error, latency_ms, created_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		run.GestureID, run.GestureName, run.ActionID, run.PluginName, run.ActionName,
+		nullJSON(run.Request), nullJSON(run.Response), run.Success, run.Error, run.LatencyMs, run.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return err
+	}
+
+	run.ID, err = result.LastInsertId()
+	return err
+}
+
+// List returns the runs matching filter, newest first, along with the total
+// number of matching runs ignoring Limit and Offset.
+func (r *HistoryRepository) List(filter ActionRunFilter) ([]*ActionRun, int, error) {
+	var conditions []string
+	var args []any
+
+	if filter.GestureID != "" {
+		conditions = append(conditions, "gesture_id = ?")
+		args = append(args, filter.GestureID)
+	}
+	if filter.ActionID != "" {
+		conditions = append(conditions, "action_id = ?")
+		args = append(args, filter.ActionID)
+	}
+	if filter.PluginName != "" {
+		conditions = append(conditions, "plugin_name = ?")
+		args = append(args, filter.PluginName)
+	}
+	if filter.Success != nil {
+		conditions = append(conditions, "success = ?")
+		args = append(args, *filter.Success)
+	}
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int
+	if err := r.db.QueryRow(`SELECT COUNT(*) FROM action_runs`+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = DefaultHistoryLimit
+	}
+
+	rows, err := r.db.Query(
+		`SELECT `+actionRunColumns+` FROM action_runs`+where+` ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?`,
+		append(args, limit, filter.Offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var runs []*ActionRun
+	for rows.Next() {
+		run, err := scanActionRun(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		runs = append(runs, run)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	return runs, total, nil
+}
+
+// Prune deletes runs recorded before the given time, then all but the newest
+// maxRuns runs. A zero time or maxRuns disables that limit.
+// Returns the number of runs deleted.
+func (r *HistoryRepository) Prune(before time.Time, maxRuns int) (int64, error) {
+	var deleted int64
+
+	if !before.IsZero() {
+		result, err := r.db.Exec(`DELETE FROM action_runs WHERE created_at < ?`, before.UTC())
+		if err != nil {
+			return 0, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		deleted += n
+	}
+
+	if maxRuns > 0 {
+		result, err := r.db.Exec(
+			`DELETE FROM action_runs WHERE id NOT IN (
+				SELECT id FROM action_runs ORDER BY created_at DESC, id DESC LIMIT ?
+			)`,
+			maxRuns,
+		)
+		if err != nil {
+			return deleted, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+
+	return deleted, nil
+}
@@ -0,0 +1,164 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+// createRuns records one run per entry of successes, a minute apart and ending at now.
+func createRuns(t *testing.T, s *Store, gestureID string, now time.Time, successes ...bool) {
+	t.Helper()
+
+	for i, success := range successes {
+		run := &ActionRun{
+			GestureID:   gestureID,
+			GestureName: "gesture-" + gestureID,
+			ActionID:    "action-" + gestureID,
+			PluginName:  "keyboard",
+			ActionName:  "keystroke",
+			Request:     json.RawMessage(`{"action":"keystroke"}`),
+			Success:     success,
+			LatencyMs:   12,
+			CreatedAt:   now.Add(time.Duration(i-len(successes)+1) * time.Minute),
+		}
+		if !success {
+			run.Error = "plugin execution failed"
+		}
+		if err := s.History().Create(run); err != nil {
+			t.Fatalf("failed to create run: %v", err)
+		}
+	}
+}
+
+func TestHistoryRepository_CreateAndList(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.History()
+
+	run := &ActionRun{
+		GestureID:   "g1",
+		GestureName: "thumbs-up",
+		ActionID:    "a1",
+		PluginName:  "keyboard",
+		ActionName:  "keystroke",
+		Request:     json.RawMessage(`{"action":"keystroke","params":{"key":"c"}}`),
+		Response:    json.RawMessage(`{"success":true}`),
+		Success:     true,
+		LatencyMs:   42,
+	}
+	if err := repo.Create(run); err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+	if run.ID == 0 {
+		t.Error("expected run ID to be set")
+	}
+
+	// A run that never reached the plugin
+	if err := repo.Create(&ActionRun{GestureID: "g1", ActionID: "a1", PluginName: "mouse", Error: "plugin not found"}); err != nil {
+		t.Fatalf("failed to create run: %v", err)
+	}
+
+	runs, total, err := repo.List(ActionRunFilter{})
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	if total != 2 || len(runs) != 2 {
+		t.Fatalf("expected 2 runs, got %d (total %d)", len(runs), total)
+	}
+
+	failed, got := runs[0], runs[1]
+	if failed.Request != nil || failed.Response != nil || failed.Error != "plugin not found" {
+		t.Errorf("unexpected failed run: %+v", failed)
+	}
+	if got.GestureName != "thumbs-up" || !got.Success || got.LatencyMs != 42 {
+		t.Errorf("unexpected run: %+v", got)
+	}
+	if string(got.Response) != `{"success":true}` {
+		t.Errorf("unexpected response: %s", got.Response)
+	}
+}
+
+func TestHistoryRepository_ListFilters(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+
+	createRuns(t, s, "g1", now, true, false, true)
+	createRuns(t, s, "g2", now, false, false)
+
+	success, failure := true, false
+	tests := []struct {
+		name      string
+		filter    ActionRunFilter
+		wantTotal int
+		wantLen   int
+	}{
+		{"all", ActionRunFilter{}, 5, 5},
+		{"gesture", ActionRunFilter{GestureID: "g1"}, 3, 3},
+		{"action", ActionRunFilter{ActionID: "action-g2"}, 2, 2},
+		{"plugin", ActionRunFilter{PluginName: "mouse"}, 0, 0},
+		{"successful", ActionRunFilter{Success: &success}, 2, 2},
+		{"failed", ActionRunFilter{GestureID: "g2", Success: &failure}, 2, 2},
+		{"since", ActionRunFilter{Since: now.Add(-30 * time.Second)}, 2, 2},
+		{"until", ActionRunFilter{Until: now.Add(-90 * time.Second)}, 1, 1},
+		{"limit", ActionRunFilter{Limit: 2}, 5, 2},
+		{"offset", ActionRunFilter{Limit: 2, Offset: 4}, 5, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			runs, total, err := s.History().List(tt.filter)
+			if err != nil {
+				t.Fatalf("failed to list runs: %v", err)
+			}
+			if total != tt.wantTotal || len(runs) != tt.wantLen {
+				t.Errorf("expected %d runs (total %d), got %d (total %d)", tt.wantLen, tt.wantTotal, len(runs), total)
+			}
+		})
+	}
+
+	// Newest runs come first
+	runs, _, err := s.History().List(ActionRunFilter{GestureID: "g1"})
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	for i := 1; i < len(runs); i++ {
+		if runs[i].CreatedAt.After(runs[i-1].CreatedAt) {
+			t.Errorf("runs not sorted newest first: %v before %v", runs[i-1].CreatedAt, runs[i].CreatedAt)
+		}
+	}
+}
+
+func TestHistoryRepository_Prune(t *testing.T) {
+	s := newTestStore(t)
+	now := time.Now()
+	repo := s.History()
+
+	createRuns(t, s, "g1", now, true, true, true, true, true)
+
+	deleted, err := repo.Prune(now.Add(-150*time.Second), 0)
+	if err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 runs older than the cutoff to be deleted, got %d", deleted)
+	}
+
+	deleted, err = repo.Prune(time.Time{}, 1)
+	if err != nil {
+		t.Fatalf("failed to prune: %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("expected 2 runs over the limit to be deleted, got %d", deleted)
+	}
+
+	runs, total, err := repo.List(ActionRunFilter{})
+	if err != nil {
+		t.Fatalf("failed to list runs: %v", err)
+	}
+	if total != 1 {
+		t.Fatalf("expected 1 run to remain, got %d", total)
+	}
+	if !runs[0].CreatedAt.Equal(now) {
+		t.Errorf("expected the newest run to remain, got one from %v", runs[0].CreatedAt)
+	}
+}
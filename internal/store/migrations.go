@@ -1,8 +1,30 @@
 package store
 
-// runMigrations executes all database migrations.
-func (s *Store) runMigrations() error {
-	migrations := []string{
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when the database was written by a newer version
+// of the application than this one.
+var ErrSchemaTooNew = errors.New("database schema is newer than this version supports")
+
+// migration is one numbered step of the database schema.
+// Each step runs in its own transaction and is recorded in schema_migrations.
+type migration struct {
+	version     int
+	description string
+	up          func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Versions must be consecutive.
+// Append new steps to the end; never change a step that has been released.
+var migrations = []migration{
+	{1, "initial schema", execAll(
 		// Gestures table - stores gesture definitions
 		`CREATE TABLE IF NOT EXISTS gestures (
 			id TEXT PRIMARY KEY,
@@ -65,13 +87,310 @@ func (s *Store) runMigrations() error {
 		`CREATE INDEX IF NOT EXISTS idx_gesture_paths_gesture_id ON gesture_paths(gesture_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_actions_gesture_id ON actions(gesture_id)`,
 		`CREATE INDEX IF NOT EXISTS idx_gesture_samples_gesture_id ON gesture_samples(gesture_id)`,
+	)},
+
+	// Steps 2 and 3 were applied by releases that predate schema_migrations,
+	// so the columns may already exist in databases being upgraded from them
+	{2, "gesture activation settings", addColumns("gestures",
+		column{"hold_ms", "INTEGER NOT NULL DEFAULT 0"},
+		column{"min_frames", "INTEGER NOT NULL DEFAULT 3"},
+		column{"cooldown_ms", "INTEGER NOT NULL DEFAULT 1000"},
+		column{"fire_on_release", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+
+	{3, "action params and repeat settings", addColumns("actions",
+		column{"params", "TEXT NOT NULL DEFAULT '{}'"},
+		column{"repeat_mode", "TEXT NOT NULL DEFAULT 'once'"},
+		column{"repeat_interval_ms", "INTEGER NOT NULL DEFAULT 500"},
+		column{"repeat_min_interval_ms", "INTEGER NOT NULL DEFAULT 100"},
+	)},
+
+	{4, "action run history", execAll(
+		// Action runs table - records every attempt to run an action, for the history API
+		`CREATE TABLE IF NOT EXISTS action_runs (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			gesture_id TEXT NOT NULL,
+			gesture_name TEXT NOT NULL DEFAULT '',
+			action_id TEXT NOT NULL,
+			plugin_name TEXT NOT NULL,
+			action_name TEXT NOT NULL,
+			request TEXT,
+			response TEXT,
+			success INTEGER NOT NULL DEFAULT 0,
+			error TEXT NOT NULL DEFAULT '',
+			latency_ms INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_action_runs_created_at ON action_runs(created_at)`,
+		`CREATE INDEX IF NOT EXISTS idx_action_runs_gesture_id ON action_runs(gesture_id)`,
+	)},
+
+	{5, "two-hand gestures", execAll(
+		// Recreate the gestures table to allow the two_hand type in its CHECK constraint
+		`CREATE TABLE gestures_new (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			type TEXT NOT NULL CHECK(type IN ('static', 'dynamic', 'two_hand')),
+			tolerance REAL NOT NULL DEFAULT 0.15,
+			samples INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			hold_ms INTEGER NOT NULL DEFAULT 0,
+			min_frames INTEGER NOT NULL DEFAULT 3,
+			cooldown_ms INTEGER NOT NULL DEFAULT 1000,
+			fire_on_release INTEGER NOT NULL DEFAULT 0
+		)`,
+		`INSERT INTO gestures_new (id, name, type, tolerance, samples, created_at, updated_at,
+			hold_ms, min_frames, cooldown_ms, fire_on_release)
+		 SELECT id, name, type, tolerance, samples, created_at, updated_at,
+			hold_ms, min_frames, cooldown_ms, fire_on_release FROM gestures`,
+		`DROP TABLE gestures`,
+		`ALTER TABLE gestures_new RENAME TO gestures`,
+
+		// Which hand a template landmark belongs to: 0 for one-hand gestures,
+		// 0 (left) or 1 (right) for two-hand gestures
+		`ALTER TABLE gesture_landmarks ADD COLUMN hand INTEGER NOT NULL DEFAULT 0`,
+
+		// Gesture hand pairs table - stores where the right hand sits relative to the left in two-hand templates
+		`CREATE TABLE IF NOT EXISTS gesture_hand_pairs (
+			gesture_id TEXT PRIMARY KEY REFERENCES gestures(id) ON DELETE CASCADE,
+			offset_x REAL NOT NULL,
+			offset_y REAL NOT NULL,
+			offset_z REAL NOT NULL,
+			scale REAL NOT NULL
+		)`,
+	)},
+
+	{6, "gesture handedness", addColumns("gestures",
+		// Which hands may make the gesture: either, left or right
+		column{"handedness", "TEXT NOT NULL DEFAULT 'either'"},
+		// Handedness label of the hand the template was recorded with, empty if unknown
+		column{"template_handedness", "TEXT NOT NULL DEFAULT ''"},
+	)},
+
+	{7, "rotation-invariant static gestures", addColumns("gestures",
+		column{"rotation_invariant", "INTEGER NOT NULL DEFAULT 0"},
+	)},
+
+	{8, "static gesture match modes", addColumns("gestures",
+		column{"match_mode", "TEXT NOT NULL DEFAULT 'landmarks'"},
+	)},
+
+	{9, "exemplar matching", execAll(
+		// How many nearest samples vote on a match; 0 compares with the averaged template
+		`ALTER TABLE gestures ADD COLUMN neighbors INTEGER NOT NULL DEFAULT 0`,
+		// Samples training rejected as outliers are not used as exemplars
+		`ALTER TABLE gesture_samples ADD COLUMN outlier INTEGER NOT NULL DEFAULT 0`,
+	)},
+
+	{10, "tolerance calibration", addColumns("gestures",
+		// Set tolerance to the recommended one whenever the gesture is trained
+		column{"auto_tolerance", "INTEGER NOT NULL DEFAULT 0"},
+		// Tolerance recommended by the last training, 0 if never trained
+		column{"recommended_tolerance", "REAL NOT NULL DEFAULT 0"},
+	)},
+
+	{11, "dynamic path normalization", addColumns("gestures",
+		// How dynamic paths are scaled and positioned before comparison
+		column{"path_normalization", "TEXT NOT NULL DEFAULT 'axes'"},
+		// What is compared at each point of a dynamic path besides its position
+		column{"path_features", "TEXT NOT NULL DEFAULT 'positions'"},
+	)},
+
+	{12, "multi-landmark dynamic gestures", execAll(
+		// Comma-separated points of the hand and measurements a dynamic path follows
+		`ALTER TABLE gestures ADD COLUMN path_track TEXT NOT NULL DEFAULT ''`,
+		// Landmarks of the hand at each path point as a JSON array, empty if not recorded
+		`ALTER TABLE gesture_paths ADD COLUMN hand TEXT NOT NULL DEFAULT ''`,
+	)},
+}
+
+// SchemaVersion is the schema version this build of the application writes.
+var SchemaVersion = migrations[len(migrations)-1].version
+
+// runMigrations brings the database up to SchemaVersion.
+// Databases created before versioning was introduced start at version 0.
+// An existing database is backed up next to its file before it is upgraded.
+func (s *Store) runMigrations() error {
+	_, err := s.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version INTEGER PRIMARY KEY,
+		description TEXT NOT NULL,
+		applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	current, err := s.Version()
+	if err != nil {
+		return err
+	}
+	if current > SchemaVersion {
+		return fmt.Errorf("%w: database is at version %d, this version supports up to %d",
+			ErrSchemaTooNew, current, SchemaVersion)
+	}
+	if current == SchemaVersion {
+		return nil
+	}
+
+	if err := s.backup(current); err != nil {
+		return fmt.Errorf("failed to back up database before upgrading: %w", err)
+	}
+
+	// Foreign keys are enforced per connection, so pin one for the upgrade.
+	// Enforcement is off while migrating so that steps can recreate tables
+	// other tables refer to without cascading deletes; the references are
+	// checked once all steps have run.
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `PRAGMA foreign_keys = OFF`); err != nil {
+		return err
 	}
+	defer conn.ExecContext(ctx, `PRAGMA foreign_keys = ON`)
 
-	for _, migration := range migrations {
-		if _, err := s.db.Exec(migration); err != nil {
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := applyMigration(ctx, conn, m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.description, err)
+		}
+	}
+
+	return checkForeignKeys(ctx, conn)
+}
+
+// checkForeignKeys returns an error if any row refers to a missing parent row.
+func checkForeignKeys(ctx context.Context, conn *sql.Conn) error {
+	rows, err := conn.QueryContext(ctx, `PRAGMA foreign_key_check`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		var table string
+		var rowid sql.NullInt64
+		var parent string
+		var fkid int
+		if err := rows.Scan(&table, &rowid, &parent, &fkid); err != nil {
+			return err
+		}
+		return fmt.Errorf("foreign key violation after upgrade: %s row %d refers to a missing %s", table, rowid.Int64, parent)
+	}
+	return rows.Err()
+}
+
+// applyMigration runs one migration step and records it in a single transaction.
+func applyMigration(ctx context.Context, conn *sql.Conn, m migration) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := m.up(tx); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO schema_migrations (version, description, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.description, time.Now(),
+	)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Version returns the schema version of the database, 0 if no migrations have been recorded.
+func (s *Store) Version() (int, error) {
+	var version int
+	err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_migrations`).Scan(&version)
+	return version, err
+}
+
+// backup copies the database to "<path>.v<version>-<timestamp>.bak" before an upgrade.
+// New and in-memory databases have nothing worth keeping and are not backed up.
+func (s *Store) backup(version int) error {
+	if s.path == "" || strings.Contains(s.path, ":memory:") || strings.Contains(s.path, "mode=memory") {
+		return nil
+	}
+
+	var tables int
+	err := s.db.QueryRow(
+		`SELECT COUNT(*) FROM sqlite_master
+		 WHERE type = 'table' AND name != 'schema_migrations' AND name NOT LIKE 'sqlite_%'`,
+	).Scan(&tables)
+	if err != nil {
+		return err
+	}
+	if tables == 0 {
+		return nil
+	}
+
+	// VACUUM INTO writes a consistent copy even while other connections are open
+	path := fmt.Sprintf("%s.v%d-%s.bak", s.path, version, time.Now().Format("20060102-150405"))
+	_, err = s.db.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
+// execAll returns a migration step that executes each statement in order.
+func execAll(statements ...string) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, stmt := range statements {
+			if _, err := tx.Exec(stmt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// column is a column name and its SQL definition.
+type column struct {
+	name, definition string
+}
+
+// addColumns returns a migration step that adds each column to table unless it is already present.
+func addColumns(table string, columns ...column) func(tx *sql.Tx) error {
+	return func(tx *sql.Tx) error {
+		for _, c := range columns {
+			if err := addColumnIfMissing(tx, table, c.name, c.definition); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// addColumnIfMissing adds a column to an existing table unless it is already present.
+func addColumnIfMissing(tx *sql.Tx, table, column, definition string) error {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
 			return err
 		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
 	}
 
-	return nil
+	_, err = tx.Exec(`ALTER TABLE ` + table + ` ADD COLUMN ` + column + ` ` + definition)
+	return err
 }
@@ -12,21 +12,26 @@ type Sample struct {
 	GestureID   string          `json:"gesture_id"`
 	SampleIndex int             `json:"sample_index"`
 	Data        json.RawMessage `json:"data"`
+	Outlier     bool            `json:"outlier"` // Rejected by training, so not used as an exemplar
 	CreatedAt   time.Time       `json:"created_at"`
 }
 
 // SampleRepository provides CRUD operations for gesture samples.
+// Successful writes are reported to the store's gesture change listeners as
+// changes of the gesture, whose exemplars are built from its samples.
 type SampleRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	notify GestureChangeFunc
 }
 
 // Samples returns the sample repository for this store.
 func (s *Store) Samples() *SampleRepository {
-	return &SampleRepository{db: s.db}
+	return &SampleRepository{db: s.db, notify: s.notifyGestureChange}
 }
 
 // Create inserts multiple samples for a gesture in a single transaction.
-// It also updates the sample count on the gesture.
+// New samples are indexed after any existing ones, and the sample count on
+// the gesture is updated to the new total.
 func (r *SampleRepository) Create(gestureID string, samples []json.RawMessage) error {
 	tx, err := r.db.Begin()
 	if err != nil {
@@ -34,6 +39,31 @@ func (r *SampleRepository) Create(gestureID string, samples []json.RawMessage) e
 	}
 	defer tx.Rollback()
 
+	if err := insertSamples(tx, gestureID, samples); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.notify(gestureID, false)
+	return nil
+}
+
+// insertSamples appends samples to those of a gesture inside tx and updates
+// the sample count on the gesture.
+func insertSamples(tx *sql.Tx, gestureID string, samples []json.RawMessage) error {
+	// Continue numbering after previously uploaded samples
+	var nextIndex int
+	err := tx.QueryRow(
+		`SELECT COALESCE(MAX(sample_index) + 1, 0) FROM gesture_samples WHERE gesture_id = ?`,
+		gestureID,
+	).Scan(&nextIndex)
+	if err != nil {
+		return err
+	}
+
 	stmt, err := tx.Prepare(`INSERT INTO gesture_samples (gesture_id, sample_index, data) VALUES (?, ?, ?)`)
 	if err != nil {
 		return err
@@ -41,25 +71,24 @@ func (r *SampleRepository) Create(gestureID string, samples []json.RawMessage) e
 	defer stmt.Close()
 
 	for i, data := range samples {
-		if _, err := stmt.Exec(gestureID, i, string(data)); err != nil {
+		if _, err := stmt.Exec(gestureID, nextIndex+i, string(data)); err != nil {
 			return err
 		}
 	}
 
 	// Update sample count on the gesture
-	_, err = tx.Exec(`UPDATE gestures SET samples = ?, updated_at = ? WHERE id = ?`,
-		len(samples), time.Now(), gestureID)
-	if err != nil {
-		return err
-	}
-
-	return tx.Commit()
+	_, err = tx.Exec(
+		`UPDATE gestures SET samples = (SELECT COUNT(*) FROM gesture_samples WHERE gesture_id = ?), updated_at = ?
+		 WHERE id = ?`,
+		gestureID, time.Now(), gestureID,
+	)
+	return err
 }
 
 // GetByGestureID retrieves all samples for a given gesture.
 func (r *SampleRepository) GetByGestureID(gestureID string) ([]Sample, error) {
 	rows, err := r.db.Query(
-		`SELECT id, gesture_id, sample_index, data, created_at
+		`SELECT id, gesture_id, sample_index, data, outlier, created_at
 		 FROM gesture_samples
 		 WHERE gesture_id = ?
 		 ORDER BY sample_index`,
@@ -74,7 +103,7 @@ func (r *SampleRepository) GetByGestureID(gestureID string) ([]Sample, error) {
 	for rows.Next() {
 		var s Sample
 		var data string
-		if err := rows.Scan(&s.ID, &s.GestureID, &s.SampleIndex, &data, &s.CreatedAt); err != nil {
+		if err := rows.Scan(&s.ID, &s.GestureID, &s.SampleIndex, &data, &s.Outlier, &s.CreatedAt); err != nil {
 			return nil, err
 		}
 		s.Data = json.RawMessage(data)
@@ -88,8 +117,55 @@ func (r *SampleRepository) GetByGestureID(gestureID string) ([]Sample, error) {
 	return samples, nil
 }
 
+// SetOutliers flags the samples of a gesture that training rejected as outliers
+// and clears the flag on all others. Samples are identified by their position
+// in the order GetByGestureID returns them.
+func (r *SampleRepository) SetOutliers(gestureID string, positions []int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setOutliers(tx, gestureID, positions); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	r.notify(gestureID, false)
+	return nil
+}
+
+// setOutliers flags the samples of a gesture at positions as outliers inside
+// tx and clears the flag on all others.
+func setOutliers(tx *sql.Tx, gestureID string, positions []int) error {
+	if _, err := tx.Exec(`UPDATE gesture_samples SET outlier = 0 WHERE gesture_id = ?`, gestureID); err != nil {
+		return err
+	}
+
+	for _, position := range positions {
+		_, err := tx.Exec(
+			`UPDATE gesture_samples SET outlier = 1 WHERE id = (
+				SELECT id FROM gesture_samples WHERE gesture_id = ? ORDER BY sample_index LIMIT 1 OFFSET ?
+			)`,
+			gestureID, position,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // DeleteByGestureID removes all samples for a given gesture.
 func (r *SampleRepository) DeleteByGestureID(gestureID string) error {
-	_, err := r.db.Exec(`DELETE FROM gesture_samples WHERE gesture_id = ?`, gestureID)
-	return err
+	if _, err := r.db.Exec(`DELETE FROM gesture_samples WHERE gesture_id = ?`, gestureID); err != nil {
+		return err
+	}
+
+	r.notify(gestureID, false)
+	return nil
 }
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestSampleRepository_SetOutliers(t *testing.T) {
+	s := newTestStore(t)
+
+	if err := s.Gestures().Create(&Gesture{ID: "g1", Name: "palm", Type: GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	samples := []json.RawMessage{json.RawMessage(`{"n": 0}`), json.RawMessage(`{"n": 1}`), json.RawMessage(`{"n": 2}`)}
+	if err := s.Samples().Create("g1", samples); err != nil {
+		t.Fatalf("failed to create samples: %v", err)
+	}
+
+	outliers := func() []bool {
+		t.Helper()
+		stored, err := s.Samples().GetByGestureID("g1")
+		if err != nil {
+			t.Fatalf("failed to get samples: %v", err)
+		}
+		flags := make([]bool, len(stored))
+		for i, sample := range stored {
+			flags[i] = sample.Outlier
+		}
+		return flags
+	}
+
+	if got := outliers(); got[0] || got[1] || got[2] {
+		t.Errorf("expected no outliers before training, got %v", got)
+	}
+
+	if err := s.Samples().SetOutliers("g1", []int{1}); err != nil {
+		t.Fatalf("SetOutliers() error = %v", err)
+	}
+	if got := outliers(); got[0] || !got[1] || got[2] {
+		t.Errorf("expected only sample 1 flagged, got %v", got)
+	}
+
+	// Retraining replaces the previous flags
+	if err := s.Samples().SetOutliers("g1", []int{2}); err != nil {
+		t.Fatalf("SetOutliers() error = %v", err)
+	}
+	if got := outliers(); got[0] || got[1] || !got[2] {
+		t.Errorf("expected only sample 2 flagged, got %v", got)
+	}
+}
+
+func TestSampleRepository_OutliersSeenOnNotify(t *testing.T) {
+	s := newTestStore(t)
+
+	if err := s.Gestures().Create(&Gesture{ID: "g1", Name: "palm", Type: GestureTypeStatic, Tolerance: 0.15}); err != nil {
+		t.Fatalf("failed to create gesture: %v", err)
+	}
+	samples := []json.RawMessage{json.RawMessage(`{"n": 0}`), json.RawMessage(`{"n": 1}`)}
+	if err := s.Samples().Create("g1", samples); err != nil {
+		t.Fatalf("failed to create samples: %v", err)
+	}
+
+	// Listeners reload exemplars as soon as they are told of a change, so the
+	// flags must already be stored by then
+	var seen [][]bool
+	s.OnGestureChange(func(id string, deleted bool) {
+		stored, err := s.Samples().GetByGestureID(id)
+		if err != nil {
+			t.Errorf("failed to get samples: %v", err)
+			return
+		}
+		flags := make([]bool, len(stored))
+		for i, sample := range stored {
+			flags[i] = sample.Outlier
+		}
+		seen = append(seen, flags)
+	})
+
+	err := s.Gestures().SaveTraining("g1", &Training{Landmarks: []Landmark{{Index: 0}}, Outliers: []int{1}})
+	if err != nil {
+		t.Fatalf("SaveTraining() error = %v", err)
+	}
+	if len(seen) != 1 || seen[0][0] || !seen[0][1] {
+		t.Errorf("expected one notification with sample 1 flagged, got %v", seen)
+	}
+
+	if err := s.Samples().SetOutliers("g1", []int{0}); err != nil {
+		t.Fatalf("SetOutliers() error = %v", err)
+	}
+	if len(seen) != 2 || !seen[1][0] || seen[1][1] {
+		t.Errorf("expected SetOutliers to notify with sample 0 flagged, got %v", seen)
+	}
+}
@@ -0,0 +1,211 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"strconv"
+)
+
+// Setting keys stored in the settings table.
+const (
+	SettingCameraID        = "camera_id"
+	SettingMotionThreshold = "motion_threshold"
+	SettingIdleFPS         = "idle_fps"
+	SettingActiveFPS       = "active_fps"
+	SettingIdleTimeoutMs   = "idle_timeout_ms"
+	SettingPort            = "port"
+	SettingPluginTimeoutMs = "plugin_timeout_ms"
+	SettingMinMatchScore   = "min_match_score"
+	SettingAmbiguityMargin = "ambiguity_margin"
+)
+
+// Default settings, used for keys that have not been saved.
+const (
+	DefaultCameraID        = 0
+	DefaultMotionThreshold = 0.05 // Percentage of pixels that must change
+	DefaultIdleFPS         = 5
+	DefaultActiveFPS       = 15
+	DefaultIdleTimeoutMs   = 2000
+	DefaultPort            = 8080
+	DefaultPluginTimeoutMs = 5000
+	DefaultMinMatchScore   = 0    // Disabled: the tolerance of each gesture decides
+	DefaultAmbiguityMargin = 0.05 // Best match must score 5% higher than the runner-up
+)
+
+// Settings holds the application settings.
+type Settings struct {
+	CameraID        int     // Camera device ID
+	MotionThreshold float64 // Percentage of pixels that must change to count as motion
+	IdleFPS         int     // Frame rate when no motion is detected
+	ActiveFPS       int     // Frame rate during active detection
+	IdleTimeoutMs   int     // Time without motion before returning to idle
+	Port            int     // HTTP server port
+	PluginTimeoutMs int     // Time a plugin may take to handle a request
+	MinMatchScore   float64 // Score (0-1) the best match must reach for any gesture to be recognized
+	AmbiguityMargin float64 // Relative score margin over the runner-up below which a match is ambiguous
+}
+
+// DefaultSettings returns the settings used when nothing has been saved.
+func DefaultSettings() Settings {
+	return Settings{
+		CameraID:        DefaultCameraID,
+		MotionThreshold: DefaultMotionThreshold,
+		IdleFPS:         DefaultIdleFPS,
+		ActiveFPS:       DefaultActiveFPS,
+		IdleTimeoutMs:   DefaultIdleTimeoutMs,
+		Port:            DefaultPort,
+		PluginTimeoutMs: DefaultPluginTimeoutMs,
+		MinMatchScore:   DefaultMinMatchScore,
+		AmbiguityMargin: DefaultAmbiguityMargin,
+	}
+}
+
+// SettingsRepository reads and writes application settings stored as key-value pairs.
+type SettingsRepository struct {
+	db *sql.DB
+}
+
+// Settings returns the settings repository for this store.
+func (s *Store) Settings() *SettingsRepository {
+	return &SettingsRepository{db: s.db}
+}
+
+// GetString returns the value of a setting, or def if it has not been saved.
+func (r *SettingsRepository) GetString(key, def string) (string, error) {
+	var value string
+	err := r.db.QueryRow(`SELECT value FROM settings WHERE key = ?`, key).Scan(&value)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return def, nil
+		}
+		return "", err
+	}
+	return value, nil
+}
+
+// GetInt returns the integer value of a setting, or def if it has not been saved
+// or does not hold an integer.
+func (r *SettingsRepository) GetInt(key string, def int) (int, error) {
+	value, err := r.GetString(key, "")
+	if err != nil || value == "" {
+		return def, err
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return def, nil
+	}
+	return n, nil
+}
+
+// GetFloat returns the floating-point value of a setting, or def if it has not
+// been saved or does not hold a number.
+func (r *SettingsRepository) GetFloat(key string, def float64) (float64, error) {
+	value, err := r.GetString(key, "")
+	if err != nil || value == "" {
+		return def, err
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return def, nil
+	}
+	return f, nil
+}
+
+// SetString saves the value of a setting.
+func (r *SettingsRepository) SetString(key, value string) error {
+	return setSetting(r.db, key, value)
+}
+
+// SetInt saves the integer value of a setting.
+func (r *SettingsRepository) SetInt(key string, value int) error {
+	return setSetting(r.db, key, strconv.Itoa(value))
+}
+
+// SetFloat saves the floating-point value of a setting.
+func (r *SettingsRepository) SetFloat(key string, value float64) error {
+	return setSetting(r.db, key, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// setSetting inserts or replaces a setting using db, which may be a transaction.
+func setSetting(db interface {
+	Exec(string, ...any) (sql.Result, error)
+}, key, value string) error {
+	_, err := db.Exec(
+		`INSERT INTO settings (key, value) VALUES (?, ?)
+		 ON CONFLICT(key) DO UPDATE SET value = excluded.value`,
+		key, value,
+	)
+	return err
+}
+
+// Load returns all application settings, using defaults for any that have not been saved.
+func (r *SettingsRepository) Load() (Settings, error) {
+	s := DefaultSettings()
+
+	ints := []struct {
+		key string
+		dst *int
+	}{
+		{SettingCameraID, &s.CameraID},
+		{SettingIdleFPS, &s.IdleFPS},
+		{SettingActiveFPS, &s.ActiveFPS},
+		{SettingIdleTimeoutMs, &s.IdleTimeoutMs},
+		{SettingPort, &s.Port},
+		{SettingPluginTimeoutMs, &s.PluginTimeoutMs},
+	}
+	for _, v := range ints {
+		n, err := r.GetInt(v.key, *v.dst)
+		if err != nil {
+			return Settings{}, err
+		}
+		*v.dst = n
+	}
+
+	floats := []struct {
+		key string
+		dst *float64
+	}{
+		{SettingMotionThreshold, &s.MotionThreshold},
+		{SettingMinMatchScore, &s.MinMatchScore},
+		{SettingAmbiguityMargin, &s.AmbiguityMargin},
+	}
+	for _, v := range floats {
+		f, err := r.GetFloat(v.key, *v.dst)
+		if err != nil {
+			return Settings{}, err
+		}
+		*v.dst = f
+	}
+
+	return s, nil
+}
+
+// Save writes all application settings in a single transaction.
+func (r *SettingsRepository) Save(s Settings) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	values := map[string]string{
+		SettingCameraID:        strconv.Itoa(s.CameraID),
+		SettingMotionThreshold: strconv.FormatFloat(s.MotionThreshold, 'g', -1, 64),
+		SettingIdleFPS:         strconv.Itoa(s.IdleFPS),
+		SettingActiveFPS:       strconv.Itoa(s.ActiveFPS),
+		SettingIdleTimeoutMs:   strconv.Itoa(s.IdleTimeoutMs),
+		SettingPort:            strconv.Itoa(s.Port),
+		SettingPluginTimeoutMs: strconv.Itoa(s.PluginTimeoutMs),
+		SettingMinMatchScore:   strconv.FormatFloat(s.MinMatchScore, 'g', -1, 64),
+		SettingAmbiguityMargin: strconv.FormatFloat(s.AmbiguityMargin, 'g', -1, 64),
+	}
+	for key, value := range values {
+		if err := setSetting(tx, key, value); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,82 @@
+package store
+
+import "testing"
+
+func TestSettingsRepository_Defaults(t *testing.T) {
+	s := newTestStore(t)
+
+	got, err := s.Settings().Load()
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if got != DefaultSettings() {
+		t.Errorf("expected default settings, got %+v", got)
+	}
+}
+
+func TestSettingsRepository_SaveAndLoad(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Settings()
+
+	want := Settings{
+		CameraID:        1,
+		MotionThreshold: 2.5,
+		IdleFPS:         3,
+		ActiveFPS:       30,
+		IdleTimeoutMs:   1500,
+		Port:            9090,
+		PluginTimeoutMs: 2000,
+		MinMatchScore:   0.6,
+		AmbiguityMargin: 0.1,
+	}
+	if err := repo.Save(want); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+
+	got, err := repo.Load()
+	if err != nil {
+		t.Fatalf("failed to load settings: %v", err)
+	}
+	if got != want {
+		t.Errorf("expected %+v, got %+v", want, got)
+	}
+
+	// Saving again replaces the previous values
+	want.Port = 8081
+	if err := repo.Save(want); err != nil {
+		t.Fatalf("failed to save settings: %v", err)
+	}
+	if got, _ := repo.Load(); got.Port != 8081 {
+		t.Errorf("expected port 8081, got %d", got.Port)
+	}
+}
+
+func TestSettingsRepository_TypedAccessors(t *testing.T) {
+	s := newTestStore(t)
+	repo := s.Settings()
+
+	if err := repo.SetInt(SettingIdleFPS, 7); err != nil {
+		t.Fatalf("failed to set int: %v", err)
+	}
+	if n, err := repo.GetInt(SettingIdleFPS, 5); err != nil || n != 7 {
+		t.Errorf("expected 7, got %d (%v)", n, err)
+	}
+
+	if err := repo.SetFloat(SettingMotionThreshold, 0.25); err != nil {
+		t.Fatalf("failed to set float: %v", err)
+	}
+	if f, err := repo.GetFloat(SettingMotionThreshold, 1); err != nil || f != 0.25 {
+		t.Errorf("expected 0.25, got %v (%v)", f, err)
+	}
+
+	// Missing and malformed values fall back to the default
+	if v, err := repo.GetString("theme", "dark"); err != nil || v != "dark" {
+		t.Errorf("expected default 'dark', got %q (%v)", v, err)
+	}
+	if err := repo.SetString(SettingPort, "eighty"); err != nil {
+		t.Fatalf("failed to set string: %v", err)
+	}
+	if n, err := repo.GetInt(SettingPort, DefaultPort); err != nil || n != DefaultPort {
+		t.Errorf("expected default port for a malformed value, got %d (%v)", n, err)
+	}
+}
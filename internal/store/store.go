@@ -4,14 +4,22 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"sync"
 
 	_ "modernc.org/sqlite"
 )
 
+// GestureChangeFunc is called after a gesture or its trained template has been written.
+// deleted is true when the gesture was removed.
+type GestureChangeFunc func(gestureID string, deleted bool)
+
 // Store represents a SQLite database connection for storing gestures and related data.
 type Store struct {
 	db   *sql.DB
 	path string
+
+	listenersMu      sync.RWMutex
+	gestureListeners []GestureChangeFunc
 }
 
 // New creates a new Store with the given database path.
@@ -42,6 +50,30 @@ func New(dbPath string) (*Store, error) {
 	return s, nil
 }
 
+// OnGestureChange registers a function that is called after any gesture is
+// created, updated, retrained or deleted. Listeners run synchronously on the
+// goroutine that made the change, after the change has been committed.
+func (s *Store) OnGestureChange(fn GestureChangeFunc) {
+	if fn == nil {
+		return
+	}
+
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.gestureListeners = append(s.gestureListeners, fn)
+}
+
+// notifyGestureChange calls every registered gesture change listener.
+func (s *Store) notifyGestureChange(gestureID string, deleted bool) {
+	s.listenersMu.RLock()
+	listeners := s.gestureListeners
+	s.listenersMu.RUnlock()
+
+	for _, fn := range listeners {
+		fn(gestureID, deleted)
+	}
+}
+
 // Close closes the database connection.
 func (s *Store) Close() error {
 	return s.db.Close()
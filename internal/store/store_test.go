@@ -1,6 +1,8 @@
 package store
 
 import (
+	"database/sql"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -65,6 +67,259 @@ func TestNewStore_RunsMigrations(t *testing.T) {
 	}
 }
 
+func TestNewStore_UpgradesOldGesturesTable(t *testing.T) {
+	tmpDir := t.TempDir()
+	dbPath := filepath.Join(tmpDir, "test.db")
+
+	// Create a gestures table as written by earlier versions
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE gestures (
+		id TEXT PRIMARY KEY,
+		name TEXT UNIQUE NOT NULL,
+		type TEXT NOT NULL,
+		tolerance REAL NOT NULL DEFAULT 0.15,
+		samples INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		t.Fatalf("failed to create old table: %v", err)
+	}
+	if _, err := db.Exec(`INSERT INTO gestures (id, name, type) VALUES ('g1', 'palm', 'static')`); err != nil {
+		t.Fatalf("failed to insert gesture: %v", err)
+	}
+	db.Close()
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	g, err := s.Gestures().GetByID("g1")
+	if err != nil {
+		t.Fatalf("failed to get gesture after upgrade: %v", err)
+	}
+	if g.MinFrames != DefaultMinFrames || g.CooldownMs != DefaultCooldownMs {
+		t.Errorf("expected default activation settings, got %+v", g)
+	}
+}
+
+func TestNewStore_UpgradeKeepsTemplates(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// Create a trained gesture as written by earlier versions
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	for _, stmt := range []string{
+		`CREATE TABLE gestures (
+			id TEXT PRIMARY KEY,
+			name TEXT NOT NULL UNIQUE,
+			type TEXT NOT NULL CHECK(type IN ('static', 'dynamic')),
+			tolerance REAL NOT NULL DEFAULT 0.15,
+			samples INTEGER NOT NULL DEFAULT 0,
+			created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+			updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)`,
+		`CREATE TABLE gesture_landmarks (
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			gesture_id TEXT NOT NULL REFERENCES gestures(id) ON DELETE CASCADE,
+			landmark_index INTEGER NOT NULL,
+			x REAL NOT NULL,
+			y REAL NOT NULL,
+			z REAL NOT NULL
+		)`,
+		`INSERT INTO gestures (id, name, type) VALUES ('g1', 'palm', 'static')`,
+		`INSERT INTO gesture_landmarks (gesture_id, landmark_index, x, y, z) VALUES ('g1', 0, 0.1, 0.2, 0.3)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("failed to set up old schema: %v", err)
+		}
+	}
+	db.Close()
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	// Rebuilding the gestures table must not cascade to its templates
+	landmarks, err := s.Gestures().GetLandmarks("g1")
+	if err != nil {
+		t.Fatalf("failed to get landmarks: %v", err)
+	}
+	if len(landmarks) != 1 || landmarks[0].Z != 0.3 || landmarks[0].Hand != HandLeft {
+		t.Errorf("expected the template to survive the upgrade, got %+v", landmarks)
+	}
+
+	// The rebuilt table accepts the new gesture type
+	if err := s.Gestures().Create(&Gesture{ID: "g2", Name: "heart", Type: GestureTypeTwoHand}); err != nil {
+		t.Errorf("failed to create a two-hand gesture: %v", err)
+	}
+}
+
+func TestNewStore_RecordsSchemaVersion(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	version, err := s.Version()
+	if err != nil {
+		t.Fatalf("failed to get version: %v", err)
+	}
+	if version != SchemaVersion {
+		t.Errorf("expected version %d, got %d", SchemaVersion, version)
+	}
+
+	var steps int
+	if err := s.DB().QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&steps); err != nil {
+		t.Fatalf("failed to count migrations: %v", err)
+	}
+	if steps != len(migrations) {
+		t.Errorf("expected %d recorded migrations, got %d", len(migrations), steps)
+	}
+	s.Close()
+
+	// Neither creating nor reopening an up-to-date database makes a backup
+	s, err = New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to reopen store: %v", err)
+	}
+	s.Close()
+
+	backups, _ := filepath.Glob(dbPath + ".*.bak")
+	if len(backups) != 0 {
+		t.Errorf("expected no backups, got %v", backups)
+	}
+}
+
+func TestNewStore_BacksUpBeforeUpgrade(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	// A database written before schema versions were recorded
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	_, err = db.Exec(`CREATE TABLE settings (key TEXT PRIMARY KEY, value TEXT NOT NULL);
+		INSERT INTO settings (key, value) VALUES ('camera_id', '1')`)
+	if err != nil {
+		t.Fatalf("failed to create old database: %v", err)
+	}
+	db.Close()
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	defer s.Close()
+
+	backups, err := filepath.Glob(dbPath + ".v0-*.bak")
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("expected one backup of version 0, got %v (%v)", backups, err)
+	}
+
+	backup, err := sql.Open("sqlite", backups[0])
+	if err != nil {
+		t.Fatalf("failed to open backup: %v", err)
+	}
+	defer backup.Close()
+
+	var value string
+	if err := backup.QueryRow(`SELECT value FROM settings WHERE key = 'camera_id'`).Scan(&value); err != nil {
+		t.Fatalf("failed to read backup: %v", err)
+	}
+	if value != "1" {
+		t.Errorf("expected backed up setting '1', got %q", value)
+	}
+
+	// The backup is a copy of the old schema
+	var tables int
+	if err := backup.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'gestures'`).Scan(&tables); err != nil {
+		t.Fatalf("failed to inspect backup: %v", err)
+	}
+	if tables != 0 {
+		t.Error("expected the backup to be taken before upgrading")
+	}
+}
+
+func TestNewStore_RefusesNewerSchema(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	_, err = s.DB().Exec(`INSERT INTO schema_migrations (version, description) VALUES (?, 'from the future')`, SchemaVersion+1)
+	if err != nil {
+		t.Fatalf("failed to record migration: %v", err)
+	}
+	s.Close()
+
+	_, err = New(dbPath)
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("expected ErrSchemaTooNew, got %v", err)
+	}
+}
+
+func TestNewStore_FailedMigrationRollsBack(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	s, err := New(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	s.Close()
+
+	// Add a step that fails halfway through
+	saved, savedVersion := migrations, SchemaVersion
+	t.Cleanup(func() {
+		migrations, SchemaVersion = saved, savedVersion
+	})
+	migrations = append(migrations[:len(migrations):len(migrations)], migration{
+		savedVersion + 1, "broken", execAll(
+			`CREATE TABLE half_done (id INTEGER)`,
+			`ALTER TABLE missing ADD COLUMN x INTEGER`,
+		),
+	})
+	SchemaVersion = savedVersion + 1
+
+	if _, err := New(dbPath); err == nil {
+		t.Fatal("expected the failing migration to be reported")
+	}
+
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'`).Scan(&tables); err != nil {
+		t.Fatalf("failed to inspect database: %v", err)
+	}
+	if tables != 0 {
+		t.Error("expected the failed step to be rolled back")
+	}
+
+	var version int
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		t.Fatalf("failed to read version: %v", err)
+	}
+	if version != savedVersion {
+		t.Errorf("expected version to stay at %d, got %d", savedVersion, version)
+	}
+}
+
 func TestStore_Close(t *testing.T) {
 	// Create a temporary directory for the test
 	tmpDir, err := os.MkdirTemp("", "kuchipudi-test-*")
@@ -1,8 +1,13 @@
 // Package main provides a keyboard plugin for macOS.
 // It sends keyboard shortcuts and keystrokes via AppleScript.
+//
+// The plugin runs in persistent mode: it stays running and answers
+// newline-delimited JSON-RPC requests on stdin. When started without
+// KUCHIPUDI_PLUGIN_MODE=persistent it handles a single request instead.
 package main
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -25,6 +30,27 @@ type Response struct {
 	Data    json.RawMessage `json:"data,omitempty"`
 }
 
+// rpcRequest is a JSON-RPC 2.0 request from the plugin executor in persistent mode.
+type rpcRequest struct {
+	ID     uint64          `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// rpcResponse is a JSON-RPC 2.0 response to the plugin executor in persistent mode.
+type rpcResponse struct {
+	JSONRPC string    `json:"jsonrpc"`
+	ID      uint64    `json:"id"`
+	Result  any       `json:"result,omitempty"`
+	Error   *rpcError `json:"error,omitempty"`
+}
+
+// rpcError is the error object of a JSON-RPC 2.0 response.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
 // KeystrokeParams defines parameters for keystroke and shortcut actions.
 type KeystrokeParams struct {
 	Key       string   `json:"key"`
@@ -43,27 +69,63 @@ var modifierMap = map[string]string{
 }
 
 func main() {
+	if os.Getenv("KUCHIPUDI_PLUGIN_MODE") == "persistent" {
+		serve()
+		return
+	}
+
 	// Read request from stdin
 	var req Request
 	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
-		writeErrorResponse(fmt.Sprintf("failed to decode request: %v", err))
+		writeResponse(errorResponse(fmt.Sprintf("failed to decode request: %v", err)))
 		return
 	}
 
-	// Handle keystroke and shortcut actions
+	writeResponse(handle(req))
+}
+
+// serve answers JSON-RPC requests from stdin until it is closed.
+func serve() {
+	scanner := bufio.NewScanner(os.Stdin)
+	enc := json.NewEncoder(os.Stdout)
+
+	for scanner.Scan() {
+		var call rpcRequest
+		if err := json.Unmarshal(scanner.Bytes(), &call); err != nil {
+			continue // Without an ID there is no one to answer
+		}
+
+		resp := rpcResponse{JSONRPC: "2.0", ID: call.ID}
+		switch call.Method {
+		case "ping":
+			resp.Result = "pong"
+		case "execute":
+			var req Request
+			if err := json.Unmarshal(call.Params, &req); err != nil {
+				resp.Error = &rpcError{Code: -32602, Message: fmt.Sprintf("invalid params: %v", err)}
+			} else {
+				resp.Result = handle(req)
+			}
+		default:
+			resp.Error = &rpcError{Code: -32601, Message: "method not found: " + call.Method}
+		}
+
+		enc.Encode(resp)
+	}
+}
+
+// handle runs a keystroke or shortcut action.
+func handle(req Request) Response {
 	switch req.Action {
 	case "keystroke", "shortcut":
 		if err := handleKeystroke(req.Params); err != nil {
-			writeErrorResponse(fmt.Sprintf("action %s failed: %v", req.Action, err))
-			return
+			return errorResponse(fmt.Sprintf("action %s failed: %v", req.Action, err))
 		}
 	default:
-		writeErrorResponse(fmt.Sprintf("unknown action: %s", req.Action))
-		return
+		return errorResponse(fmt.Sprintf("unknown action: %s", req.Action))
 	}
 
-	// Write success response
-	writeSuccessResponse()
+	return Response{Success: true}
 }
 
 // handleKeystroke processes keystroke and shortcut actions.
@@ -103,20 +165,16 @@ func buildKeystrokeScript(key string, modifiers []string) string {
 	return fmt.Sprintf(`tell application "System Events" to keystroke "%s" using {%s}`, key, modifierList)
 }
 
-// writeErrorResponse writes an error response to stdout.
-func writeErrorResponse(errMsg string) {
-	resp := Response{
+// errorResponse builds a failed response with the given message.
+func errorResponse(errMsg string) Response {
+	return Response{
 		Success: false,
 		Error:   errMsg,
 	}
-	json.NewEncoder(os.Stdout).Encode(resp)
 }
 
-// writeSuccessResponse writes a success response to stdout.
-func writeSuccessResponse() {
-	resp := Response{
-		Success: true,
-	}
+// writeResponse writes a response to stdout.
+func writeResponse(resp Response) {
 	json.NewEncoder(os.Stdout).Encode(resp)
 }
 
@@ -6,6 +6,9 @@
 // API Base URL
 const API_BASE = '/api';
 
+// Number of pipeline events kept in the activity list
+const MAX_ACTIVITIES = 10;
+
 // DOM Elements
 const navLinks = document.querySelectorAll('.nav-link');
 const sections = document.querySelectorAll('.section');
@@ -14,10 +17,17 @@ const statusText = document.getElementById('status-text');
 const gestureCount = document.getElementById('gesture-count');
 const actionCount = document.getElementById('action-count');
 const gestureList = document.getElementById('gesture-list');
+const mappingList = document.getElementById('mapping-list');
 const activityList = document.getElementById('activity-list');
-const cameraSelect = document.getElementById('camera-select');
-const sensitivitySlider = document.getElementById('sensitivity-slider');
-const sensitivityValue = document.getElementById('sensitivity-value');
+const cameraIdInput = document.getElementById('camera-id');
+const motionThresholdInput = document.getElementById('motion-threshold');
+const idleFpsInput = document.getElementById('idle-fps');
+const activeFpsInput = document.getElementById('active-fps');
+const idleTimeoutInput = document.getElementById('idle-timeout');
+const pluginTimeoutInput = document.getElementById('plugin-timeout');
+const minMatchScoreInput = document.getElementById('min-match-score');
+const ambiguityMarginInput = document.getElementById('ambiguity-margin');
+const serverPortInput = document.getElementById('server-port');
 const startAtLogin = document.getElementById('start-at-login');
 const saveSettingsBtn = document.getElementById('save-settings-btn');
 const addGestureBtn = document.getElementById('add-gesture-btn');
@@ -81,8 +91,10 @@ function navigateTo(sectionId) {
         loadGestures();
     } else if (sectionId === 'gestures') {
         loadGestures();
+    } else if (sectionId === 'actions') {
+        loadActions();
     } else if (sectionId === 'settings') {
-        loadCameras();
+        loadSettings();
     }
 }
 
@@ -162,46 +174,126 @@ function renderEmptyState() {
 }
 
 /**
- * Load available cameras
+ * Load gesture-action mappings from API
  */
-async function loadCameras() {
+async function loadActions() {
     try {
-        // Try to get camera list from API first
-        const cameras = await fetchJSON('/cameras');
-        renderCameraOptions(cameras);
+        const [actionData, gestureData] = await Promise.all([
+            fetchJSON('/actions'),
+            fetchJSON('/gestures')
+        ]);
+
+        const gestureNames = {};
+        (gestureData.gestures || []).forEach(gesture => {
+            gestureNames[gesture.id] = gesture.name;
+        });
+
+        renderMappings(actionData.actions || [], gestureNames);
     } catch (error) {
-        // Fallback to browser API if available
-        if (navigator.mediaDevices && navigator.mediaDevices.enumerateDevices) {
-            try {
-                const devices = await navigator.mediaDevices.enumerateDevices();
-                const videoDevices = devices.filter(device => device.kind === 'videoinput');
-                renderCameraOptions(videoDevices.map((device, index) => ({
-                    id: device.deviceId,
-                    name: device.label || `Camera ${index + 1}`
-                })));
-            } catch (err) {
-                console.error('Failed to enumerate devices:', err);
-                cameraSelect.innerHTML = '<option value="">No cameras found</option>';
-            }
-        } else {
-            cameraSelect.innerHTML = '<option value="">Camera API unavailable</option>';
-        }
+        console.error('Failed to load actions:', error);
+        mappingList.innerHTML = '<p class="placeholder-text">Failed to load mappings.</p>';
     }
 }
 
 /**
- * Render camera options in select
- * @param {Array} cameras - Array of camera objects
+ * Render gesture-action mappings with their repeat settings
+ * @param {Array} actions - Array of action objects
+ * @param {object} gestureNames - Gesture names keyed by gesture ID
  */
-function renderCameraOptions(cameras) {
-    if (!Array.isArray(cameras) || cameras.length === 0) {
-        cameraSelect.innerHTML = '<option value="">No cameras found</option>';
+function renderMappings(actions, gestureNames) {
+    if (actions.length === 0) {
+        mappingList.innerHTML = '<p class="placeholder-text">No mappings configured yet.</p>';
         return;
     }
 
-    cameraSelect.innerHTML = cameras.map(camera => `
-        <option value="${escapeHtml(camera.id)}">${escapeHtml(camera.name)}</option>
+    mappingList.innerHTML = actions.map(action => `
+        <div class="mapping-item" data-id="${escapeHtml(action.id)}">
+            <span class="mapping-gesture">${escapeHtml(gestureNames[action.gesture_id] || action.gesture_id)}</span>
+            <span class="mapping-arrow">→</span>
+            <span class="mapping-action">${escapeHtml(action.plugin_name)} / ${escapeHtml(action.action_name)}</span>
+            <div class="mapping-repeat">
+                <select class="form-control repeat-mode" title="While held">
+                    <option value="once" ${action.repeat_mode === 'once' ? 'selected' : ''}>Fire once</option>
+                    <option value="repeat" ${action.repeat_mode === 'repeat' ? 'selected' : ''}>Repeat</option>
+                    <option value="accelerate" ${action.repeat_mode === 'accelerate' ? 'selected' : ''}>Accelerate</option>
+                </select>
+                <input type="number" class="form-control repeat-interval" min="50" step="50"
+                       value="${action.repeat_interval_ms}" title="Repeat interval (ms)">
+                <input type="number" class="form-control repeat-min-interval" min="50" step="50"
+                       value="${action.repeat_min_interval_ms}" title="Fastest interval (ms)">
+            </div>
+        </div>
     `).join('');
+
+    mappingList.querySelectorAll('.mapping-item').forEach(item => {
+        updateRepeatInputs(item);
+        item.querySelectorAll('.repeat-mode, .repeat-interval, .repeat-min-interval').forEach(input => {
+            input.addEventListener('change', () => saveRepeat(item));
+        });
+    });
+}
+
+/**
+ * Show only the interval inputs that apply to the selected repeat mode
+ * @param {HTMLElement} item - Mapping item element
+ */
+function updateRepeatInputs(item) {
+    const mode = item.querySelector('.repeat-mode').value;
+    item.querySelector('.repeat-interval').style.display = mode === 'once' ? 'none' : '';
+    item.querySelector('.repeat-min-interval').style.display = mode === 'accelerate' ? '' : 'none';
+}
+
+/**
+ * Save the repeat settings of a mapping
+ * @param {HTMLElement} item - Mapping item element
+ */
+async function saveRepeat(item) {
+    updateRepeatInputs(item);
+
+    const settings = {
+        repeat_mode: item.querySelector('.repeat-mode').value,
+        repeat_interval_ms: parseInt(item.querySelector('.repeat-interval').value, 10),
+        repeat_min_interval_ms: parseInt(item.querySelector('.repeat-min-interval').value, 10)
+    };
+
+    try {
+        await fetchJSON(`/actions/${encodeURIComponent(item.dataset.id)}`, {
+            method: 'PUT',
+            body: JSON.stringify(settings)
+        });
+    } catch (error) {
+        console.error('Failed to save repeat settings:', error);
+        alert('Failed to save repeat settings. Intervals must be at least 50 ms.');
+        loadActions();
+    }
+}
+
+/**
+ * Load settings from API
+ */
+async function loadSettings() {
+    try {
+        const settings = await fetchJSON('/settings');
+        renderSettings(settings);
+    } catch (error) {
+        console.error('Failed to load settings:', error);
+    }
+}
+
+/**
+ * Fill the settings form
+ * @param {object} settings - Settings returned by the API
+ */
+function renderSettings(settings) {
+    cameraIdInput.value = settings.camera_id;
+    motionThresholdInput.value = settings.motion_threshold;
+    idleFpsInput.value = settings.idle_fps;
+    activeFpsInput.value = settings.active_fps;
+    idleTimeoutInput.value = settings.idle_timeout_ms;
+    pluginTimeoutInput.value = settings.plugin_timeout_ms;
+    minMatchScoreInput.value = settings.min_match_score;
+    ambiguityMarginInput.value = settings.ambiguity_margin;
+    serverPortInput.value = settings.port;
 }
 
 /**
@@ -209,17 +301,37 @@ function renderCameraOptions(cameras) {
  */
 async function saveSettings() {
     const settings = {
-        cameraId: cameraSelect.value,
-        sensitivity: parseInt(sensitivitySlider.value, 10),
-        startAtLogin: startAtLogin.checked
+        camera_id: parseInt(cameraIdInput.value, 10),
+        motion_threshold: parseFloat(motionThresholdInput.value),
+        idle_fps: parseInt(idleFpsInput.value, 10),
+        active_fps: parseInt(activeFpsInput.value, 10),
+        idle_timeout_ms: parseInt(idleTimeoutInput.value, 10),
+        plugin_timeout_ms: parseInt(pluginTimeoutInput.value, 10),
+        min_match_score: parseFloat(minMatchScoreInput.value),
+        ambiguity_margin: parseFloat(ambiguityMarginInput.value),
+        port: parseInt(serverPortInput.value, 10)
     };
 
     try {
-        await fetchJSON('/settings', {
-            method: 'POST',
+        const response = await fetch(`${API_BASE}/settings`, {
+            method: 'PUT',
+            headers: { 'Content-Type': 'application/json' },
             body: JSON.stringify(settings)
         });
-        alert('Settings saved successfully!');
+        const data = await response.json();
+
+        if (!response.ok) {
+            const fields = (data.fields || []).map(f => `${f.field}: ${f.message}`);
+            alert(['Failed to save settings.', ...fields].join('\n'));
+            return;
+        }
+
+        renderSettings(data);
+        if (data.restart_required && data.restart_required.length > 0) {
+            alert(`Settings saved. Restart Kuchipudi to apply: ${data.restart_required.join(', ')}`);
+        } else {
+            alert('Settings saved successfully!');
+        }
     } catch (error) {
         console.error('Failed to save settings:', error);
         alert('Failed to save settings. Please try again.');
@@ -262,6 +374,45 @@ function updateActivityList(activities) {
     `).join('');
 }
 
+// Pipeline events shown in the activity list, newest first
+const activities = [];
+
+/**
+ * Describe a pipeline match event for the activity list
+ * @param {object} event - Match event from /api/events
+ * @returns {string} - Activity message
+ */
+function describeEvent(event) {
+    const score = Number(event.score).toFixed(2);
+    if (event.outcome === 'ambiguous') {
+        const runnerUpScore = Number(event.runner_up_score).toFixed(2);
+        return `${event.gesture} (${score}) or ${event.runner_up} (${runnerUpScore})? Nothing fired`;
+    }
+    return `${event.gesture} fired (${score})`;
+}
+
+/**
+ * Connect to the pipeline events WebSocket, reconnecting when it closes
+ */
+function connectEvents() {
+    const protocol = location.protocol === 'https:' ? 'wss:' : 'ws:';
+    const ws = new WebSocket(`${protocol}//${location.host}${API_BASE}/events`);
+
+    ws.onmessage = (message) => {
+        const event = JSON.parse(message.data);
+        activities.unshift({
+            message: describeEvent(event),
+            timestamp: new Date(event.time).toLocaleTimeString(),
+        });
+        activities.length = Math.min(activities.length, MAX_ACTIVITIES);
+        updateActivityList(activities);
+    };
+
+    ws.onclose = () => {
+        setTimeout(connectEvents, 5000);
+    };
+}
+
 /**
  * Initialize event listeners
  */
@@ -275,11 +426,6 @@ function initEventListeners() {
         });
     });
 
-    // Sensitivity slider
-    sensitivitySlider.addEventListener('input', () => {
-        sensitivityValue.textContent = sensitivitySlider.value;
-    });
-
     // Save settings button
     saveSettingsBtn.addEventListener('click', saveSettings);
 
@@ -296,6 +442,7 @@ function init() {
     // Initial data load
     checkHealth();
     loadGestures();
+    connectEvents();
 
     // Periodic health check (every 30 seconds)
     setInterval(checkHealth, 30000);
@@ -13,6 +13,12 @@ const canvas = document.getElementById('overlay');
 const ctx = canvas.getContext('2d');
 const gestureNameInput = document.getElementById('gesture-name');
 const gestureTypeSelect = document.getElementById('gesture-type');
+const handednessSelect = document.getElementById('gesture-handedness');
+const rotationCheckbox = document.getElementById('rotation-invariant');
+const matchModeSelect = document.getElementById('gesture-match-mode');
+const neighborsSelect = document.getElementById('gesture-neighbors');
+const pathNormalizationSelect = document.getElementById('gesture-path-normalization');
+const pathFeaturesSelect = document.getElementById('gesture-path-features');
 const sampleList = document.getElementById('sample-list');
 const sampleCount = document.getElementById('sample-count');
 const recordBtn = document.getElementById('record-btn');
@@ -63,14 +69,16 @@ function connectWebSocket() {
         drawLandmarks(data.hands);
 
         if (recording && gestureTypeSelect.value === 'dynamic') {
-            // Buffer path points for dynamic gestures (use wrist position)
+            // Buffer path points for dynamic gestures (use wrist position),
+            // with the whole hand for gestures that follow more than the path
             if (data.hands && data.hands.length > 0) {
                 const points = data.hands[0].points;
                 if (points && points.length > 0) {
                     pathBuffer.push({
                         x: points[0].x,  // Wrist X
                         y: points[0].y,  // Wrist Y
-                        timestamp: data.timestamp
+                        timestamp: data.timestamp,
+                        hand: points
                     });
                 }
             }
@@ -135,10 +143,28 @@ function recordSample() {
 
         samples.push({
             type: 'static',
+            handedness: currentLandmarks[0].handedness,
             landmarks: currentLandmarks[0].points,
             timestamp: Date.now()
         });
 
+        updateSampleList();
+    } else if (type === 'two_hand') {
+        // Capture both hands with their handedness so they can be paired
+        if (!currentLandmarks || currentLandmarks.length < 2) {
+            alert('Both hands must be in view.');
+            return;
+        }
+
+        samples.push({
+            type: 'two_hand',
+            hands: currentLandmarks.slice(0, 2).map(h => ({
+                handedness: h.handedness,
+                landmarks: h.points
+            })),
+            timestamp: Date.now()
+        });
+
         updateSampleList();
     } else {
         // Record dynamic gesture (2 seconds)
@@ -204,6 +230,15 @@ async function saveGesture() {
     }
 
     const type = gestureTypeSelect.value;
+    const handedness = handednessSelect.value;
+    const rotation_invariant = type === 'static' && rotationCheckbox.checked;
+    const match_mode = type === 'static' ? matchModeSelect.value : 'landmarks';
+    const neighbors = type === 'static' ? parseInt(neighborsSelect.value, 10) : 0;
+    const path_normalization = type === 'dynamic' ? pathNormalizationSelect.value : 'axes';
+    const path_features = type === 'dynamic' ? pathFeaturesSelect.value : 'positions';
+    const path_track = type === 'dynamic'
+        ? [...document.querySelectorAll('input[name="path-track"]:checked')].map(input => input.value)
+        : [];
 
     try {
         saveBtn.disabled = true;
@@ -213,7 +248,10 @@ async function saveGesture() {
         const response = await fetch(`${API_BASE}/gestures`, {
             method: 'POST',
             headers: { 'Content-Type': 'application/json' },
-            body: JSON.stringify({ name, type })
+            body: JSON.stringify({
+                name, type, handedness, rotation_invariant, match_mode, neighbors,
+                path_normalization, path_features, path_track, tolerance_mode: 'auto'
+            })
         });
 
         if (!response.ok) {
@@ -234,7 +272,16 @@ async function saveGesture() {
             throw new Error('Failed to save samples');
         }
 
-        alert('Gesture saved successfully!');
+        // Warn about existing gestures the new one may be mistaken for
+        const { training } = await samplesResponse.json();
+        const confusions = (training?.calibration?.confusions || []).filter(c => c.likely);
+        if (confusions.length > 0) {
+            alert('Gesture saved, but it may be confused with: ' +
+                confusions.map(c => c.name).join(', ') +
+                '. Consider recording it more distinctly.');
+        } else {
+            alert('Gesture saved successfully!');
+        }
         window.location.href = '/';
     } catch (err) {
         alert('Failed to save gesture: ' + err.message);
@@ -245,9 +292,17 @@ async function saveGesture() {
 
 // Type change handler
 gestureTypeSelect.onchange = () => {
-    const isStatic = gestureTypeSelect.value === 'static';
-    document.getElementById('static-instructions').style.display = isStatic ? 'block' : 'none';
-    document.getElementById('dynamic-instructions').style.display = isStatic ? 'none' : 'block';
+    const type = gestureTypeSelect.value;
+    for (const t of ['static', 'dynamic', 'two_hand']) {
+        document.getElementById(`${t}-instructions`).style.display = t === type ? 'block' : 'none';
+    }
+    // Only single-hand poses have these matching options
+    for (const id of ['handedness-field', 'match-mode-field', 'neighbors-field', 'rotation-field']) {
+        document.getElementById(id).style.display = type === 'static' ? '' : 'none';
+    }
+    for (const id of ['path-normalization-field', 'path-features-field', 'path-track-field']) {
+        document.getElementById(id).style.display = type === 'dynamic' ? '' : 'none';
+    }
     // Clear samples when changing type
     samples = [];
     updateSampleList();